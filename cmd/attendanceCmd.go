@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	config "github.com/komkem01/easy-attend-service/configs"
+	"github.com/komkem01/easy-attend-service/controller/auth"
+	"github.com/spf13/cobra"
+)
+
+// Attendance command groups attendance maintenance tasks
+func Attendance() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "attendance",
+		Short: "Attendance maintenance tasks",
+		Args:  NotReqArgs,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return config.Open(cmd.Context())
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			return config.Close(cmd.Context())
+		},
+	}
+	cmd.AddCommand(attendanceLockExpired())
+	return cmd
+}
+
+func attendanceLockExpired() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lock-expired",
+		Short: "Lock sessions older than ATTENDANCE_AUTO_LOCK_DAYS",
+		Args:  NotReqArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			db := config.Database()
+			locked, err := auth.NewAttendanceService(db).LockExpiredSessionsService(cmd.Context())
+			if err != nil {
+				fmt.Printf("%s\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Locked %d attendance session(s)\n", locked)
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/uuid"
+	config "github.com/komkem01/easy-attend-service/configs"
+	"github.com/komkem01/easy-attend-service/controller/auth"
+	"github.com/komkem01/easy-attend-service/utils/holidays"
+	"github.com/spf13/cobra"
+)
+
+// Calendar command groups academic calendar tasks
+func Calendar() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "calendar",
+		Short: "Academic calendar tasks",
+		Args:  NotReqArgs,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return config.Open(cmd.Context())
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			return config.Close(cmd.Context())
+		},
+	}
+	cmd.AddCommand(calendarImport())
+	return cmd
+}
+
+func calendarImport() *cobra.Command {
+	var (
+		file        string
+		source      string
+		schoolID    string
+		createdBy   string
+		defaultType string
+		dryRun      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import events from an .ics file (--file) or a bundled calendar (--source)",
+		Args:  NotReqArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			opts := auth.CalendarImportOptions{DefaultEventType: defaultType, DryRun: dryRun}
+
+			var reader io.Reader
+			switch {
+			case source != "" && file != "":
+				fmt.Println("use either --file or --source, not both")
+				os.Exit(1)
+			case source != "":
+				data, ok := holidays.Lookup(source)
+				if !ok {
+					fmt.Printf("unknown source %q; available:", source)
+					for _, s := range holidays.Sources() {
+						fmt.Printf(" %s", s.Name)
+					}
+					fmt.Println()
+					os.Exit(1)
+				}
+				opts.Source = source
+				reader = bytes.NewReader(data)
+			case file != "":
+				f, err := os.Open(file)
+				if err != nil {
+					fmt.Printf("%s\n", err)
+					os.Exit(1)
+				}
+				defer f.Close()
+				opts.Source = file
+				reader = f
+			default:
+				fmt.Println("--file or --source is required")
+				os.Exit(1)
+			}
+
+			if schoolID != "" {
+				id, err := uuid.Parse(schoolID)
+				if err != nil {
+					fmt.Println("invalid --school-id")
+					os.Exit(1)
+				}
+				opts.SchoolID = &id
+			}
+
+			var creator *uuid.UUID
+			if createdBy != "" {
+				id, err := uuid.Parse(createdBy)
+				if err != nil {
+					fmt.Println("invalid --created-by")
+					os.Exit(1)
+				}
+				creator = &id
+			}
+
+			db := config.Database()
+			report, err := auth.NewCalendarService(db).ImportCalendarAsSystemService(cmd.Context(), reader, opts, creator)
+			if err != nil {
+				fmt.Printf("%s\n", err)
+				os.Exit(1)
+			}
+
+			for _, event := range report.Events {
+				fmt.Printf("%-9s %s %s", event.Action, event.StartDate, event.Title)
+				if event.Note != "" {
+					fmt.Printf(" (%s)", event.Note)
+				}
+				for _, e := range event.Errors {
+					fmt.Printf("\n          error: %s", e)
+				}
+				fmt.Println()
+			}
+			fmt.Printf("Created %d, updated %d, unchanged %d, removed %d, skipped %d, invalid %d\n",
+				report.Created, report.Updated, report.Unchanged, report.Removed, report.Skipped, report.Invalid)
+
+			switch {
+			case report.DryRun:
+				fmt.Println("Dry run; nothing was imported")
+			case !report.Committed:
+				fmt.Println("Calendar file has invalid events; nothing was imported")
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "path to an .ics file")
+	cmd.Flags().StringVar(&source, "source", "", "bundled calendar, e.g. th-public-holidays")
+	cmd.Flags().StringVar(&schoolID, "school-id", "", "school to import into; empty applies the events to every school")
+	cmd.Flags().StringVar(&createdBy, "created-by", "", "user ID recorded as the creator (default: first super admin)")
+	cmd.Flags().StringVar(&defaultType, "default-type", "other", "event type for events without a known category")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report what would change without writing")
+	return cmd
+}
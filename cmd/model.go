@@ -92,6 +92,10 @@ func modelDown(db *bun.DB) error {
 		"DROP TYPE IF EXISTS classroom_status CASCADE",
 		"DROP TYPE IF EXISTS classroom_role CASCADE",
 		"DROP TYPE IF EXISTS member_status CASCADE",
+		"DROP TYPE IF EXISTS make_up_status CASCADE",
+		"DROP TYPE IF EXISTS waitlist_status CASCADE",
+		"DROP TYPE IF EXISTS transfer_status CASCADE",
+		"DROP TYPE IF EXISTS promotion_action CASCADE",
 	}
 
 	for _, query := range enumTypes {
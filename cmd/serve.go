@@ -8,6 +8,7 @@ import (
 
 	config "github.com/komkem01/easy-attend-service/configs"
 	"github.com/komkem01/easy-attend-service/routes"
+	"github.com/komkem01/easy-attend-service/utils/storage"
 	"github.com/spf13/cobra"
 )
 
@@ -35,8 +36,14 @@ func startServer() {
 	// Get database connection
 	db := config.Database()
 
+	// Uploads cannot be stored or served without a working storage backend, so refuse to start
+	store, err := storage.Default()
+	if err != nil {
+		log.Fatalf("Invalid storage configuration: %v", err)
+	}
+
 	// Initialize routes with database
-	router := routes.SetupRoutes(db)
+	router := routes.SetupRoutes(db, store)
 
 	// Get port from environment or use default
 	port := os.Getenv("PORT")
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	config "github.com/komkem01/easy-attend-service/configs"
+	"github.com/komkem01/easy-attend-service/controller/auth"
+	"github.com/komkem01/easy-attend-service/utils/storage"
+	"github.com/spf13/cobra"
+)
+
+// Storage command groups maintenance tasks for uploaded file storage
+func Storage() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "storage",
+		Short: "Uploaded file storage maintenance tasks",
+		Args:  NotReqArgs,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return config.Open(cmd.Context())
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			return config.Close(cmd.Context())
+		},
+	}
+	cmd.AddCommand(storageMigrate())
+	return cmd
+}
+
+func storageMigrate() *cobra.Command {
+	var from, to string
+	var deleteSource, dryRun bool
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Copy uploaded files from one storage backend to another and verify their checksums",
+		Args:  NotReqArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if from == to {
+				fmt.Println("--from and --to must name different backends")
+				os.Exit(1)
+			}
+			src, err := storage.New(from)
+			if err != nil {
+				fmt.Printf("%s\n", err)
+				os.Exit(1)
+			}
+			dst, err := storage.New(to)
+			if err != nil {
+				fmt.Printf("%s\n", err)
+				os.Exit(1)
+			}
+
+			db := config.Database()
+			report, err := auth.NewUploadService(db, dst).MigrateStorageService(cmd.Context(), src, dst, deleteSource, dryRun)
+			if err != nil {
+				fmt.Printf("%s\n", err)
+				os.Exit(1)
+			}
+
+			verb := "Copied"
+			if report.DryRun {
+				verb = "Would copy"
+			}
+			fmt.Printf("%s %d of %d file(s) from %s to %s, %d already present\n", verb, report.Copied, report.Total, from, to, report.Skipped)
+			if report.Deleted > 0 {
+				fmt.Printf("Deleted %d file(s) from %s\n", report.Deleted, from)
+			}
+			for _, key := range report.Missing {
+				fmt.Printf("Missing from %s: %s\n", from, key)
+			}
+			for _, message := range report.Failed {
+				fmt.Printf("Failed: %s\n", message)
+			}
+			if len(report.Failed) > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "source backend (local or s3)")
+	cmd.Flags().StringVar(&to, "to", "", "target backend (local or s3)")
+	cmd.Flags().BoolVar(&deleteSource, "delete-source", false, "delete each file from the source once its copy is verified")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report what would be copied without copying anything")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+	return cmd
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	config "github.com/komkem01/easy-attend-service/configs"
+	"github.com/komkem01/easy-attend-service/controller/auth"
+	"github.com/spf13/cobra"
+)
+
+// Terms command groups academic term maintenance tasks
+func Terms() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "terms",
+		Short: "Academic term maintenance tasks",
+		Args:  NotReqArgs,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return config.Open(cmd.Context())
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			return config.Close(cmd.Context())
+		},
+	}
+	cmd.AddCommand(termsArchiveEnded())
+	return cmd
+}
+
+func termsArchiveEnded() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "archive-ended",
+		Short: "Archive terms that have ended: deactivate their classrooms and lock their sessions",
+		Args:  NotReqArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			db := config.Database()
+			results, err := auth.NewTermService(db).ArchiveEndedTermsService(cmd.Context())
+			for _, result := range results {
+				fmt.Printf("Archived %s: %d classroom(s) deactivated, %d session(s) locked\n",
+					result.Term.Name, result.ClassroomsDeactivated, result.SessionsLocked)
+			}
+			if err != nil {
+				fmt.Printf("%s\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("Archived %d term(s)\n", len(results))
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	config "github.com/komkem01/easy-attend-service/configs"
+	"github.com/komkem01/easy-attend-service/controller/auth"
+	"github.com/komkem01/easy-attend-service/utils/storage"
+	"github.com/spf13/cobra"
+)
+
+// Trash command groups maintenance tasks for soft-deleted records
+func Trash() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trash",
+		Short: "Soft-deleted record maintenance tasks",
+		Args:  NotReqArgs,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return config.Open(cmd.Context())
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+			return config.Close(cmd.Context())
+		},
+	}
+	cmd.AddCommand(trashPurge())
+	return cmd
+}
+
+func trashPurge() *cobra.Command {
+	var days int
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Permanently delete records that have been in the trash for longer than --days",
+		Args:  NotReqArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if days < 1 {
+				fmt.Println("--days must be at least 1")
+				os.Exit(1)
+			}
+			store, err := storage.Default()
+			if err != nil {
+				fmt.Printf("%s\n", err)
+				os.Exit(1)
+			}
+			db := config.Database()
+			report, err := auth.NewTrashService(db, store).PurgeTrashService(cmd.Context(), days, dryRun)
+			if err != nil {
+				fmt.Printf("%s\n", err)
+				os.Exit(1)
+			}
+			verb := "Purged"
+			if report.DryRun {
+				verb = "Would purge"
+			}
+			for _, resource := range []string{"assignments", "classrooms", "users", "schools", "files"} {
+				fmt.Printf("%s %d %s deleted before %s", verb, report.Purged[resource], resource, report.Cutoff.Format("2006-01-02"))
+				if kept := report.Kept[resource]; kept > 0 {
+					fmt.Printf(" (%d kept, still referenced)", kept)
+				}
+				fmt.Println()
+			}
+			for _, message := range report.Errors {
+				fmt.Printf("Could not remove stored file %s\n", message)
+			}
+		},
+	}
+	cmd.Flags().IntVar(&days, "days", 0, "retention period in days")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "report what would be purged without deleting anything")
+	_ = cmd.MarkFlagRequired("days")
+	return cmd
+}
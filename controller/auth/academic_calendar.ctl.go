@@ -0,0 +1,274 @@
+package auth
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/komkem01/easy-attend-service/response"
+	"github.com/komkem01/easy-attend-service/utils/holidays"
+	"github.com/uptrace/bun"
+)
+
+// maxCalendarImportBytes limits the size of an uploaded iCalendar file
+const maxCalendarImportBytes = 2 << 20
+
+// CalendarController handles academic calendar endpoints
+type CalendarController struct {
+	calendarService *CalendarService
+}
+
+// NewCalendarController creates a new calendar controller
+func NewCalendarController(db *bun.DB) *CalendarController {
+	return &CalendarController{
+		calendarService: NewCalendarService(db),
+	}
+}
+
+// GetCalendarOccurrences lists event occurrences between ?from and ?to (YYYY-MM-DD), optionally filtered
+// by ?school_id and ?event_type
+func (ctrl *CalendarController) GetCalendarOccurrences(c *gin.Context) {
+	from, err := parseDateQuery(c, "from")
+	if err != nil || from == nil {
+		response.BadRequest(c, "from is required, expected YYYY-MM-DD")
+		return
+	}
+	to, err := parseDateQuery(c, "to")
+	if err != nil || to == nil {
+		response.BadRequest(c, "to is required, expected YYYY-MM-DD")
+		return
+	}
+
+	var schoolID *uuid.UUID
+	if value := c.Query("school_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			response.BadRequest(c, "Invalid school ID format")
+			return
+		}
+		schoolID = &id
+	}
+
+	var eventType *string
+	if value := c.Query("event_type"); value != "" {
+		switch value {
+		case "session", "assignment_due", "exam", "holiday", "meeting", "other":
+			eventType = &value
+		default:
+			response.BadRequest(c, "Invalid event type")
+			return
+		}
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	occurrences, err := ctrl.calendarService.GetOccurrencesService(c.Request.Context(), userUUID, schoolID, eventType, *from, *to)
+	if err != nil {
+		respondCalendarError(c, err, "Failed to retrieve calendar")
+		return
+	}
+
+	response.Success(c, occurrences)
+}
+
+// GetCalendarEvent returns a single calendar event with its recurrence rule
+func (ctrl *CalendarController) GetCalendarEvent(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid calendar event ID format")
+		return
+	}
+
+	event, err := ctrl.calendarService.GetCalendarEventService(c.Request.Context(), id)
+	if err != nil {
+		respondCalendarError(c, err, "Failed to retrieve calendar event")
+		return
+	}
+
+	response.Success(c, event)
+}
+
+// CreateCalendarEvent adds an event to the academic calendar
+func (ctrl *CalendarController) CreateCalendarEvent(c *gin.Context) {
+	var req requests.CreateCalendarEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	event, err := ctrl.calendarService.CreateCalendarEventService(c.Request.Context(), &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondCalendarError(c, err, "Failed to create calendar event")
+		return
+	}
+
+	response.Created(c, event)
+}
+
+// UpdateCalendarEvent edits a calendar event
+func (ctrl *CalendarController) UpdateCalendarEvent(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid calendar event ID format")
+		return
+	}
+
+	var req requests.UpdateCalendarEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	event, err := ctrl.calendarService.UpdateCalendarEventService(c.Request.Context(), id, &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondCalendarError(c, err, "Failed to update calendar event")
+		return
+	}
+
+	response.Success(c, event)
+}
+
+// DeleteCalendarEvent removes a calendar event
+func (ctrl *CalendarController) DeleteCalendarEvent(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid calendar event ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	if err := ctrl.calendarService.DeleteCalendarEventService(c.Request.Context(), id, userUUID, GetUserRoleFromContext(c)); err != nil {
+		respondCalendarError(c, err, "Failed to delete calendar event")
+		return
+	}
+
+	c.JSON(200, gin.H{"success": true, "message": "Calendar event deleted successfully"})
+}
+
+// ImportCalendar imports events from an uploaded .ics file in the 'file' field, or from a bundled dataset
+// named in 'source'. school_id picks the school (super admins may leave it empty for every school),
+// default_event_type applies to events without a known category and dry_run=true only reports the plan.
+func (ctrl *CalendarController) ImportCalendar(c *gin.Context) {
+	opts := CalendarImportOptions{DefaultEventType: c.PostForm("default_event_type")}
+
+	if value := c.DefaultQuery("dry_run", c.PostForm("dry_run")); value != "" {
+		dryRun, err := strconv.ParseBool(value)
+		if err != nil {
+			response.BadRequest(c, "Invalid dry_run value")
+			return
+		}
+		opts.DryRun = dryRun
+	}
+
+	if value := c.PostForm("school_id"); value != "" {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			response.BadRequest(c, "Invalid school ID format")
+			return
+		}
+		opts.SchoolID = &id
+	}
+
+	var file io.Reader
+	if source := c.PostForm("source"); source != "" {
+		data, ok := holidays.Lookup(source)
+		if !ok {
+			response.BadRequest(c, "Unknown calendar source")
+			return
+		}
+		opts.Source = source
+		file = bytes.NewReader(data)
+	} else {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			response.BadRequest(c, "iCalendar file is required in the 'file' field, or a bundled calendar in 'source'")
+			return
+		}
+		if fileHeader.Size > maxCalendarImportBytes {
+			response.BadRequest(c, "iCalendar file is too large")
+			return
+		}
+
+		upload, err := fileHeader.Open()
+		if err != nil {
+			response.BadRequest(c, "Failed to read iCalendar file")
+			return
+		}
+		defer upload.Close()
+		opts.Source = fileHeader.Filename
+		file = upload
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	report, err := ctrl.calendarService.ImportCalendarService(c.Request.Context(), file, opts, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondCalendarError(c, err, "Failed to import calendar")
+		return
+	}
+
+	switch {
+	case report.DryRun:
+		response.Success(c, report)
+	case !report.Committed:
+		response.UnprocessableEntity(c, "Calendar file has invalid events; nothing was imported", report)
+	default:
+		response.Created(c, report)
+	}
+}
+
+// GetCalendarSources lists the bundled calendars ImportCalendar accepts as 'source'
+func (ctrl *CalendarController) GetCalendarSources(c *gin.Context) {
+	response.Success(c, holidays.Sources())
+}
+
+// respondCalendarError maps calendar service errors to HTTP responses
+func respondCalendarError(c *gin.Context, err error, fallback string) {
+	if strings.HasPrefix(err.Error(), "invalid recurrence") || strings.HasPrefix(err.Error(), "invalid iCalendar") ||
+		strings.HasPrefix(err.Error(), "iCalendar file") {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	switch err.Error() {
+	case "calendar event not found", "school not found":
+		response.NotFound(c, err.Error())
+	case "unauthorized to manage the academic calendar":
+		response.Forbidden(c, err.Error())
+	case "invalid date, expected YYYY-MM-DD", "end date must not be before start date",
+		"date range must not exceed one year", "exception dates require a recurrence rule",
+		"invalid default event type":
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback+": "+err.Error())
+	}
+}
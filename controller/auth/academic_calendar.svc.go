@@ -0,0 +1,428 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/komkem01/easy-attend-service/utils/recurrence"
+	"github.com/uptrace/bun"
+)
+
+// CalendarService manages academic calendar events and expands them into occurrences
+type CalendarService struct {
+	db *bun.DB
+}
+
+// NewCalendarService creates a new calendar service
+func NewCalendarService(db *bun.DB) *CalendarService {
+	return &CalendarService{db: db}
+}
+
+// CalendarOccurrence is one concrete instance of a calendar event. Non-recurring events have exactly one.
+type CalendarOccurrence struct {
+	Event   *model.AcademicCalendar `json:"event"`
+	Date    string                  `json:"date"`     // first day of this occurrence, YYYY-MM-DD
+	EndDate string                  `json:"end_date"` // last day of this occurrence, inclusive
+}
+
+// GetOccurrencesService lists event occurrences between from and to for a school (the user's own by default)
+// together with events that apply to every school
+func (s *CalendarService) GetOccurrencesService(ctx context.Context, userID uuid.UUID, schoolID *uuid.UUID, eventType *string, from, to time.Time) ([]*CalendarOccurrence, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("end date must not be before start date")
+	}
+	if to.Sub(from) > 366*24*time.Hour {
+		return nil, fmt.Errorf("date range must not exceed one year")
+	}
+
+	var schoolIDs []uuid.UUID
+	if schoolID != nil {
+		schoolIDs = append(schoolIDs, *schoolID)
+	} else {
+		var user model.Users
+		err := s.db.NewSelect().
+			Model(&user).
+			Column("school_id").
+			Where("id = ?", userID).
+			Scan(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve user: %w", err)
+		}
+		if user.SchoolID != nil {
+			schoolIDs = append(schoolIDs, *user.SchoolID)
+		}
+	}
+
+	occurrences, err := calendarOccurrences(ctx, s.db, schoolIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if eventType == nil {
+		return occurrences, nil
+	}
+	filtered := make([]*CalendarOccurrence, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		if occurrence.Event.EventType == *eventType {
+			filtered = append(filtered, occurrence)
+		}
+	}
+	return filtered, nil
+}
+
+// GetCalendarEventService loads a single calendar event
+func (s *CalendarService) GetCalendarEventService(ctx context.Context, eventID uuid.UUID) (*model.AcademicCalendar, error) {
+	var event model.AcademicCalendar
+	err := s.db.NewSelect().
+		Model(&event).
+		Relation("Creator").
+		Where("ac.id = ?", eventID).
+		Scan(ctx)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("calendar event not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve calendar event: %w", err)
+	}
+
+	return &event, nil
+}
+
+// CreateCalendarEventService adds an event; admins add to their own school, super admins to any school or to all
+func (s *CalendarService) CreateCalendarEventService(ctx context.Context, req *requests.CreateCalendarEventRequest, userID uuid.UUID, role string) (*model.AcademicCalendar, error) {
+	schoolID, err := s.calendarSchoolForEditor(ctx, req.SchoolID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, err := parseCalendarDate(req.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	event := &model.AcademicCalendar{
+		ID:                uuid.New(),
+		SchoolID:          schoolID,
+		Title:             strings.TrimSpace(req.Title),
+		Description:       req.Description,
+		EventType:         req.EventType,
+		StartDate:         startDate,
+		AffectsAttendance: true,
+		CreatedBy:         userID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	if req.EndDate != nil && *req.EndDate != "" {
+		endDate, err := parseCalendarDate(*req.EndDate)
+		if err != nil {
+			return nil, err
+		}
+		event.EndDate = &endDate
+	}
+	if req.AffectsAttendance != nil {
+		event.AffectsAttendance = *req.AffectsAttendance
+	}
+	if err := applyEventRecurrence(event, req.RRule, req.ExDates); err != nil {
+		return nil, err
+	}
+	if event.EndDate != nil && event.EndDate.Before(event.StartDate) {
+		return nil, fmt.Errorf("end date must not be before start date")
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(event).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to create calendar event: %w", err)
+		}
+		return writeAuditLog(ctx, tx, &userID, "academic_calendar.create", "academic_calendar", &event.ID, nil, event, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// UpdateCalendarEventService edits an event the user is allowed to manage
+func (s *CalendarService) UpdateCalendarEventService(ctx context.Context, eventID uuid.UUID, req *requests.UpdateCalendarEventRequest, userID uuid.UUID, role string) (*model.AcademicCalendar, error) {
+	event, err := s.getEditableEvent(ctx, eventID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	old := *event
+
+	if req.Title != nil {
+		event.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Description != nil {
+		event.Description = req.Description
+	}
+	if req.EventType != nil {
+		event.EventType = *req.EventType
+	}
+	if req.StartDate != nil {
+		if event.StartDate, err = parseCalendarDate(*req.StartDate); err != nil {
+			return nil, err
+		}
+	}
+	if req.EndDate != nil {
+		event.EndDate = nil
+		if *req.EndDate != "" {
+			endDate, err := parseCalendarDate(*req.EndDate)
+			if err != nil {
+				return nil, err
+			}
+			event.EndDate = &endDate
+		}
+	}
+	if req.AffectsAttendance != nil {
+		event.AffectsAttendance = *req.AffectsAttendance
+	}
+	if req.RRule != nil || req.ExDates != nil {
+		rrule := req.RRule
+		exDates := req.ExDates
+		// Keep the parts of the existing rule that the request leaves out
+		if existing := eventRule(event); existing != nil {
+			if rrule == nil {
+				current := existing.String()
+				rrule = &current
+			}
+			if exDates == nil {
+				exDates = existing.ExDates
+			}
+		}
+		if err := applyEventRecurrence(event, rrule, exDates); err != nil {
+			return nil, err
+		}
+	}
+	if event.EndDate != nil && event.EndDate.Before(event.StartDate) {
+		return nil, fmt.Errorf("end date must not be before start date")
+	}
+	event.Revision++
+	event.UpdatedAt = time.Now()
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().
+			Model(event).
+			Column("title", "description", "event_type", "start_date", "end_date", "is_recurring", "recurrence_pattern", "affects_attendance", "revision", "updated_at").
+			WherePK().
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to update calendar event: %w", err)
+		}
+		return writeAuditLog(ctx, tx, &userID, "academic_calendar.update", "academic_calendar", &event.ID, old, event, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// DeleteCalendarEventService removes an event (soft delete)
+func (s *CalendarService) DeleteCalendarEventService(ctx context.Context, eventID uuid.UUID, userID uuid.UUID, role string) error {
+	event, err := s.getEditableEvent(ctx, eventID, userID, role)
+	if err != nil {
+		return err
+	}
+
+	return s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewDelete().Model(event).WherePK().Exec(ctx); err != nil {
+			return fmt.Errorf("failed to delete calendar event: %w", err)
+		}
+		return writeAuditLog(ctx, tx, &userID, "academic_calendar.delete", "academic_calendar", &event.ID, event, nil, nil)
+	})
+}
+
+// getEditableEvent loads an event and checks the user may change it
+func (s *CalendarService) getEditableEvent(ctx context.Context, eventID uuid.UUID, userID uuid.UUID, role string) (*model.AcademicCalendar, error) {
+	event, err := s.GetCalendarEventService(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	event.Creator = nil
+
+	if _, err := s.calendarSchoolForEditor(ctx, event.SchoolID, userID, role); err != nil {
+		return nil, err
+	}
+	if event.SchoolID == nil && role != "super_admin" {
+		return nil, fmt.Errorf("unauthorized to manage the academic calendar")
+	}
+	return event, nil
+}
+
+// calendarSchoolForEditor resolves which school an admin is editing. Admins are limited to their own
+// school; super admins may pick any school or none (an event for every school).
+func (s *CalendarService) calendarSchoolForEditor(ctx context.Context, schoolID *uuid.UUID, userID uuid.UUID, role string) (*uuid.UUID, error) {
+	if !isAdminRole(role) {
+		return nil, fmt.Errorf("unauthorized to manage the academic calendar")
+	}
+	if role == "super_admin" {
+		return schoolID, nil
+	}
+
+	var user model.Users
+	err := s.db.NewSelect().
+		Model(&user).
+		Column("school_id").
+		Where("id = ?", userID).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+	if user.SchoolID == nil || (schoolID != nil && *schoolID != *user.SchoolID) {
+		return nil, fmt.Errorf("unauthorized to manage the academic calendar")
+	}
+	return user.SchoolID, nil
+}
+
+// calendarOccurrences is the single place calendar events are read for a date range. It returns every
+// occurrence overlapping [from, to] for the given schools and for events without a school, expanding
+// recurring events and dropping their exception dates.
+func calendarOccurrences(ctx context.Context, db bun.IDB, schoolIDs []uuid.UUID, from, to time.Time) ([]*CalendarOccurrence, error) {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	var events []*model.AcademicCalendar
+	query := db.NewSelect().
+		Model(&events).
+		Where("ac.start_date <= ?", to.Format("2006-01-02")).
+		Where("ac.is_recurring = true OR COALESCE(ac.end_date, ac.start_date) >= ?", from.Format("2006-01-02")).
+		Order("ac.start_date ASC")
+	if len(schoolIDs) > 0 {
+		query = query.Where("ac.school_id IS NULL OR ac.school_id IN (?)", bun.In(schoolIDs))
+	} else {
+		query = query.Where("ac.school_id IS NULL")
+	}
+
+	if err := query.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("failed to retrieve calendar events: %w", err)
+	}
+
+	var occurrences []*CalendarOccurrence
+	for _, event := range events {
+		days := 0
+		if event.EndDate != nil {
+			days = int(event.EndDate.Sub(event.StartDate).Hours() / 24)
+		}
+
+		rule := eventRule(event)
+		if rule == nil {
+			end := event.StartDate.AddDate(0, 0, days)
+			if !end.Before(from) {
+				occurrences = append(occurrences, &CalendarOccurrence{
+					Event:   event,
+					Date:    event.StartDate.Format("2006-01-02"),
+					EndDate: end.Format("2006-01-02"),
+				})
+			}
+			continue
+		}
+
+		// Start the expansion early enough to catch multi-day occurrences that began before from
+		for _, date := range rule.Occurrences(event.StartDate, from.AddDate(0, 0, -days), to) {
+			occurrences = append(occurrences, &CalendarOccurrence{
+				Event:   event,
+				Date:    date.Format("2006-01-02"),
+				EndDate: date.AddDate(0, 0, days).Format("2006-01-02"),
+			})
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].Date < occurrences[j].Date
+	})
+	return occurrences, nil
+}
+
+// cancelsClasses reports whether an event is a holiday on which no classes take place
+func cancelsClasses(event *model.AcademicCalendar) bool {
+	return event.AffectsAttendance && event.EventType == "holiday"
+}
+
+// noClassDates returns the YYYY-MM-DD dates in [from, to] on which a holiday cancels classes at the school;
+// a nil school only sees events without a school
+func noClassDates(ctx context.Context, db bun.IDB, schoolID *uuid.UUID, from, to time.Time) (map[string]bool, error) {
+	var schoolIDs []uuid.UUID
+	if schoolID != nil {
+		schoolIDs = append(schoolIDs, *schoolID)
+	}
+	occurrences, err := calendarOccurrences(ctx, db, schoolIDs, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make(map[string]bool)
+	for _, occurrence := range occurrences {
+		if !cancelsClasses(occurrence.Event) {
+			continue
+		}
+		start, _ := time.Parse("2006-01-02", occurrence.Date)
+		end, _ := time.Parse("2006-01-02", occurrence.EndDate)
+		for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+			dates[date.Format("2006-01-02")] = true
+		}
+	}
+	return dates, nil
+}
+
+// eventRule returns the recurrence rule of an event, or nil when it does not recur
+func eventRule(event *model.AcademicCalendar) *recurrence.Rule {
+	if !event.IsRecurring || event.RecurrencePattern == nil {
+		return nil
+	}
+
+	var rule recurrence.Rule
+	if err := json.Unmarshal([]byte(*event.RecurrencePattern), &rule); err != nil || rule.Validate() != nil {
+		return nil
+	}
+	return &rule
+}
+
+// applyEventRecurrence sets or clears an event's recurrence from an RRULE string and exception dates
+func applyEventRecurrence(event *model.AcademicCalendar, rrule *string, exDates []string) error {
+	if rrule == nil || strings.TrimSpace(*rrule) == "" {
+		if len(exDates) > 0 && rrule == nil {
+			return fmt.Errorf("exception dates require a recurrence rule")
+		}
+		event.IsRecurring = false
+		event.RecurrencePattern = nil
+		return nil
+	}
+
+	rule, err := recurrence.ParseRRule(*rrule)
+	if err != nil {
+		return err
+	}
+	rule.ExDates = exDates
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	pattern, err := json.Marshal(rule)
+	if err != nil {
+		return fmt.Errorf("failed to encode recurrence rule: %w", err)
+	}
+	value := string(pattern)
+	event.IsRecurring = true
+	event.RecurrencePattern = &value
+	return nil
+}
+
+// parseCalendarDate parses a YYYY-MM-DD date in UTC so bun writes the same calendar date to a date column
+func parseCalendarDate(value string) (time.Time, error) {
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date, expected YYYY-MM-DD")
+	}
+	return date, nil
+}
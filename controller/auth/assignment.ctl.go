@@ -69,6 +69,10 @@ func (ctrl *AssignmentController) CreateAssignment(c *gin.Context) {
 			response.Forbidden(c, "You don't have access to this classroom")
 			return
 		}
+		if err.Error() == "late penalty percent must be between 0 and 100" {
+			response.BadRequest(c, err.Error())
+			return
+		}
 		response.InternalServerError(c, "Failed to create assignment: "+err.Error())
 		return
 	}
@@ -242,6 +246,10 @@ func (ctrl *AssignmentController) UpdateAssignment(c *gin.Context) {
 			response.BadRequest(c, "No data provided for update")
 			return
 		}
+		if err.Error() == "late penalty percent must be between 0 and 100" {
+			response.BadRequest(c, err.Error())
+			return
+		}
 		response.InternalServerError(c, "Failed to update assignment: "+err.Error())
 		return
 	}
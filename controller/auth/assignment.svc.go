@@ -54,6 +54,9 @@ func (s *AssignmentService) CreateAssignmentService(ctx context.Context, req *re
 
 	latePenaltyPercent := 0.0
 	if req.LatePenaltyPercent != nil {
+		if *req.LatePenaltyPercent < 0 || *req.LatePenaltyPercent > 100 {
+			return nil, fmt.Errorf("late penalty percent must be between 0 and 100")
+		}
 		latePenaltyPercent = *req.LatePenaltyPercent
 	}
 
@@ -227,8 +230,14 @@ func (s *AssignmentService) UpdateAssignmentService(ctx context.Context, id uuid
 		return nil, fmt.Errorf("failed to retrieve assignment: %w", err)
 	}
 
-	// Check if user is the creator of this assignment or teacher of the classroom
-	if assignment.CreatedBy != teacherID && assignment.Classroom.TeacherID != teacherID {
+	// Check if user is the teacher of the classroom, or the creator of this assignment still teaching there
+	allowed := assignment.Classroom.TeacherID == teacherID
+	if !allowed && assignment.CreatedBy == teacherID {
+		if allowed, err = isClassroomStaff(ctx, s.db, assignment.ClassroomID, teacherID); err != nil {
+			return nil, err
+		}
+	}
+	if !allowed {
 		return nil, fmt.Errorf("unauthorized to update this assignment")
 	}
 
@@ -260,6 +269,9 @@ func (s *AssignmentService) UpdateAssignmentService(ctx context.Context, id uuid
 		updateData["allow_late_submission"] = *req.AllowLateSubmission
 	}
 	if req.LatePenaltyPercent != nil {
+		if *req.LatePenaltyPercent < 0 || *req.LatePenaltyPercent > 100 {
+			return nil, fmt.Errorf("late penalty percent must be between 0 and 100")
+		}
 		updateData["late_penalty_percent"] = *req.LatePenaltyPercent
 	}
 	if req.SubmissionFormat != nil {
@@ -289,6 +301,7 @@ func (s *AssignmentService) UpdateAssignmentService(ctx context.Context, id uuid
 	for key, value := range updateData {
 		query = query.Set("? = ?", bun.Ident(key), value)
 	}
+	query = query.Set("revision = revision + 1")
 
 	_, err = query.Exec(ctx)
 	if err != nil {
@@ -326,8 +339,14 @@ func (s *AssignmentService) DeleteAssignmentService(ctx context.Context, id uuid
 		return fmt.Errorf("failed to retrieve assignment: %w", err)
 	}
 
-	// Check if user is the creator of this assignment or teacher of the classroom
-	if assignment.CreatedBy != teacherID && assignment.Classroom.TeacherID != teacherID {
+	// Check if user is the teacher of the classroom, or the creator of this assignment still teaching there
+	allowed := assignment.Classroom.TeacherID == teacherID
+	if !allowed && assignment.CreatedBy == teacherID {
+		if allowed, err = isClassroomStaff(ctx, s.db, assignment.ClassroomID, teacherID); err != nil {
+			return err
+		}
+	}
+	if !allowed {
 		return fmt.Errorf("unauthorized to delete this assignment")
 	}
 
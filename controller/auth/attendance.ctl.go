@@ -0,0 +1,452 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/komkem01/easy-attend-service/response"
+	"github.com/uptrace/bun"
+)
+
+// AttendanceController handles attendance HTTP requests
+type AttendanceController struct {
+	attendanceService *AttendanceService
+}
+
+// NewAttendanceController creates a new attendance controller
+func NewAttendanceController(db *bun.DB) *AttendanceController {
+	return &AttendanceController{
+		attendanceService: NewAttendanceService(db),
+	}
+}
+
+// GetUserRoleFromContext returns the role stored in the JWT context
+func GetUserRoleFromContext(c *gin.Context) string {
+	return c.GetString("user_role")
+}
+
+// CheckIn records the authenticated student's attendance
+func (ctrl *AttendanceController) CheckIn(c *gin.Context) {
+	var req requests.CheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	studentUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	record, err := ctrl.attendanceService.CheckInService(c.Request.Context(), &req, studentUUID)
+	if err != nil {
+		respondAttendanceError(c, err, "Failed to check in")
+		return
+	}
+
+	response.Created(c, record)
+}
+
+// GetSessionRecords lists attendance records for a session
+func (ctrl *AttendanceController) GetSessionRecords(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid session ID format")
+		return
+	}
+
+	teacherUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	records, err := ctrl.attendanceService.GetSessionRecordsService(c.Request.Context(), sessionID, teacherUUID)
+	if err != nil {
+		respondAttendanceError(c, err, "Failed to retrieve attendance records")
+		return
+	}
+
+	response.Success(c, records)
+}
+
+// MarkAttendance records attendance for several students in a session
+func (ctrl *AttendanceController) MarkAttendance(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid session ID format")
+		return
+	}
+
+	var req requests.MarkAttendanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	teacherUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	records, err := ctrl.attendanceService.MarkAttendanceService(c.Request.Context(), sessionID, &req, teacherUUID)
+	if err != nil {
+		respondAttendanceError(c, err, "Failed to mark attendance")
+		return
+	}
+
+	response.Success(c, records)
+}
+
+// MarkAttendanceBySeat records attendance from taps on the seat map
+func (ctrl *AttendanceController) MarkAttendanceBySeat(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid session ID format")
+		return
+	}
+
+	var req requests.SeatAttendanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	teacherUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	records, err := ctrl.attendanceService.SeatAttendanceService(c.Request.Context(), sessionID, &req, teacherUUID)
+	if err != nil {
+		respondAttendanceError(c, err, "Failed to mark attendance")
+		return
+	}
+
+	response.Success(c, records)
+}
+
+// UpdateAttendanceRecord corrects a single attendance record
+func (ctrl *AttendanceController) UpdateAttendanceRecord(c *gin.Context) {
+	recordID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid attendance record ID format")
+		return
+	}
+
+	var req requests.UpdateAttendanceRecordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	teacherUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	record, err := ctrl.attendanceService.UpdateAttendanceRecordService(c.Request.Context(), recordID, &req, teacherUUID)
+	if err != nil {
+		respondAttendanceError(c, err, "Failed to update attendance record")
+		return
+	}
+
+	response.Success(c, record)
+}
+
+// SignOffSession locks a session's attendance after the teacher certifies it
+func (ctrl *AttendanceController) SignOffSession(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid session ID format")
+		return
+	}
+
+	teacherUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	session, err := ctrl.attendanceService.SignOffSessionService(c.Request.Context(), sessionID, teacherUUID)
+	if err != nil {
+		respondAttendanceError(c, err, "Failed to sign off session")
+		return
+	}
+
+	response.Success(c, session)
+}
+
+// UnlockSession reopens a locked session (school admins only)
+func (ctrl *AttendanceController) UnlockSession(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid session ID format")
+		return
+	}
+
+	var req requests.UnlockSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	adminUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	session, err := ctrl.attendanceService.UnlockSessionService(c.Request.Context(), sessionID, adminUUID, GetUserRoleFromContext(c), req.Reason)
+	if err != nil {
+		respondAttendanceError(c, err, "Failed to unlock session")
+		return
+	}
+
+	response.Success(c, session)
+}
+
+// GetRecordTimeline returns the full change history of an attendance record
+func (ctrl *AttendanceController) GetRecordTimeline(c *gin.Context) {
+	recordID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid attendance record ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	record, revisions, err := ctrl.attendanceService.GetRecordTimelineService(c.Request.Context(), recordID, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondAttendanceError(c, err, "Failed to retrieve attendance history")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"record":    record,
+		"revisions": revisions,
+	})
+}
+
+// GetSessionRevisions returns every attendance change made in a session
+func (ctrl *AttendanceController) GetSessionRevisions(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid session ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	revisions, err := ctrl.attendanceService.GetSessionRevisionsService(c.Request.Context(), sessionID, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondAttendanceError(c, err, "Failed to retrieve attendance history")
+		return
+	}
+
+	response.Success(c, revisions)
+}
+
+// CancelSession cancels a session and optionally schedules a make-up session
+func (ctrl *AttendanceController) CancelSession(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid session ID format")
+		return
+	}
+
+	var req requests.CancelSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	teacherUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	session, makeUp, err := ctrl.attendanceService.CancelSessionService(c.Request.Context(), sessionID, &req, teacherUUID)
+	if err != nil {
+		respondAttendanceError(c, err, "Failed to cancel session")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"session":         session,
+		"make_up_session": makeUp,
+	})
+}
+
+// GetClassroomAttendanceSummary returns per-student attendance totals for a classroom
+func (ctrl *AttendanceController) GetClassroomAttendanceSummary(c *gin.Context) {
+	classroomID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	from, err := parseDateQuery(c, "from")
+	if err != nil {
+		response.BadRequest(c, "Invalid from date, expected YYYY-MM-DD")
+		return
+	}
+	to, err := parseDateQuery(c, "to")
+	if err != nil {
+		response.BadRequest(c, "Invalid to date, expected YYYY-MM-DD")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	summaries, err := ctrl.attendanceService.GetClassroomAttendanceSummaryService(c.Request.Context(), classroomID, userUUID, GetUserRoleFromContext(c), from, to)
+	if err != nil {
+		respondAttendanceError(c, err, "Failed to retrieve attendance summary")
+		return
+	}
+
+	response.Success(c, summaries)
+}
+
+// RequestMakeUp links an absence to a session of another section for make-up credit
+func (ctrl *AttendanceController) RequestMakeUp(c *gin.Context) {
+	var req requests.RequestMakeUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	makeUp, err := ctrl.attendanceService.RequestMakeUpService(c.Request.Context(), &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondAttendanceError(c, err, "Failed to request make-up")
+		return
+	}
+
+	response.Created(c, makeUp)
+}
+
+// ReviewMakeUp approves or rejects a pending make-up request
+func (ctrl *AttendanceController) ReviewMakeUp(c *gin.Context) {
+	makeUpID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid make-up ID format")
+		return
+	}
+
+	var req requests.ReviewMakeUpRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	makeUp, err := ctrl.attendanceService.ReviewMakeUpService(c.Request.Context(), makeUpID, &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondAttendanceError(c, err, "Failed to review make-up")
+		return
+	}
+
+	response.Success(c, makeUp)
+}
+
+// GetMakeUps lists make-up requests visible to the current user
+func (ctrl *AttendanceController) GetMakeUps(c *gin.Context) {
+	var classroomID *uuid.UUID
+	if value := c.Query("classroom_id"); value != "" {
+		parsed, err := uuid.Parse(value)
+		if err != nil {
+			response.BadRequest(c, "Invalid classroom ID format")
+			return
+		}
+		classroomID = &parsed
+	}
+
+	status := c.Query("status")
+	if status != "" && status != "pending" && status != "approved" && status != "rejected" {
+		response.BadRequest(c, "Invalid status, expected pending, approved or rejected")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	makeUps, err := ctrl.attendanceService.GetMakeUpsService(c.Request.Context(), userUUID, GetUserRoleFromContext(c), classroomID, status)
+	if err != nil {
+		respondAttendanceError(c, err, "Failed to retrieve make-up requests")
+		return
+	}
+
+	response.Success(c, makeUps)
+}
+
+// parseDateQuery parses an optional YYYY-MM-DD query parameter in the school's time zone
+func parseDateQuery(c *gin.Context, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", value, schoolLocation)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+// respondAttendanceError maps attendance service errors to HTTP responses
+func respondAttendanceError(c *gin.Context, err error, fallback string) {
+	switch err.Error() {
+	case "session not found", "attendance record not found", "classroom not found", "make-up request not found",
+		"seating plan not found":
+		response.NotFound(c, err.Error())
+	case "unauthorized to manage this session", "only school admins can unlock attendance", "student is not enrolled in this classroom",
+		"unauthorized to view this classroom":
+		response.Forbidden(c, err.Error())
+	case "attendance session is locked", "attendance session is already locked", "attendance session is not locked",
+		"already checked in", "session is not open for check-in", "check-in window has closed",
+		"session is cancelled", "session is already cancelled", "completed sessions cannot be cancelled",
+		"absence already has a make-up request", "make-up request has already been reviewed":
+		response.Conflict(c, err.Error())
+	case "invalid check-in code", "check-in code has expired", "no data to update",
+		"invalid session date", "invalid session time", "session end time must be after start time", "make-up session falls on a holiday",
+		"student_id is required", "cancelled sessions cannot be made up", "original session has not taken place yet",
+		"make-up session must belong to another classroom", "make-up session must be for the same subject",
+		"make-up session must belong to the same school", "make-up session is cancelled", "only absences can be made up", "student has not attended the make-up session",
+		"invalid seat", "seat is empty", "seat is listed more than once":
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback+": "+err.Error())
+	}
+}
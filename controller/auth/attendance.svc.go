@@ -0,0 +1,634 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/komkem01/easy-attend-service/utils/checkin"
+	"github.com/uptrace/bun"
+)
+
+// schoolLocation is the time zone session dates and times are interpreted in
+var schoolLocation = loadSchoolLocation()
+
+func loadSchoolLocation() *time.Location {
+	loc, err := time.LoadLocation("Asia/Bangkok")
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// sessionDateTime combines a session's date column with one of its time-of-day columns
+func sessionDateTime(date, clock time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, schoolLocation)
+}
+
+// attendanceAutoLockDays returns ATTENDANCE_AUTO_LOCK_DAYS (0 disables automatic locking)
+func attendanceAutoLockDays() int {
+	days, err := strconv.Atoi(os.Getenv("ATTENDANCE_AUTO_LOCK_DAYS"))
+	if err != nil || days < 0 {
+		return 0
+	}
+	return days
+}
+
+// AttendanceService handles check-in, attendance marking and sign-off
+type AttendanceService struct {
+	db *bun.DB
+}
+
+// NewAttendanceService creates a new attendance service
+func NewAttendanceService(db *bun.DB) *AttendanceService {
+	return &AttendanceService{db: db}
+}
+
+// CheckInService records a student's own check-in using a session code or a scanned QR payload
+func (s *AttendanceService) CheckInService(ctx context.Context, req *requests.CheckInRequest, studentID uuid.UUID) (*model.AttendanceRecords, error) {
+	var sessionID uuid.UUID
+	method := "code"
+
+	if req.QRPayload != nil && *req.QRPayload != "" {
+		payload, err := checkin.DecodePayload(*req.QRPayload)
+		if err != nil || payload.Purpose != checkin.PurposeSession {
+			return nil, fmt.Errorf("invalid check-in code")
+		}
+		sessionID, err = uuid.Parse(payload.Subject)
+		if err != nil {
+			return nil, fmt.Errorf("invalid check-in code")
+		}
+		if !checkin.Verify(checkin.PurposeSession, payload.Subject, payload.Token, time.Now()) {
+			return nil, fmt.Errorf("check-in code has expired")
+		}
+		method = "qr"
+	} else {
+		if req.SessionID == nil || req.SessionCode == nil {
+			return nil, fmt.Errorf("invalid check-in code")
+		}
+		sessionID = *req.SessionID
+	}
+
+	session, err := s.getSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if method == "code" && (session.SessionCode == nil || !strings.EqualFold(*session.SessionCode, strings.TrimSpace(*req.SessionCode))) {
+		return nil, fmt.Errorf("invalid check-in code")
+	}
+
+	if session.Status == "completed" || session.Status == "cancelled" {
+		return nil, fmt.Errorf("session is not open for check-in")
+	}
+
+	if err := s.ensureSessionUnlocked(ctx, session); err != nil {
+		return nil, err
+	}
+
+	enrolled, err := s.db.NewSelect().
+		Model((*model.ClassroomStudents)(nil)).
+		Where("classroom_id = ? AND student_id = ? AND is_active = true", session.ClassroomID, studentID).
+		Exists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check enrollment: %w", err)
+	}
+	if !enrolled {
+		// Students from another section may check in to make up an absence
+		guestIDs, err := makeUpGuestIDs(ctx, s.db, session.ID, []uuid.UUID{studentID})
+		if err != nil {
+			return nil, err
+		}
+		if len(guestIDs) == 0 {
+			return nil, fmt.Errorf("student is not enrolled in this classroom")
+		}
+	}
+
+	exists, err := s.db.NewSelect().
+		Model((*model.AttendanceRecords)(nil)).
+		Where("session_id = ? AND student_id = ?", session.ID, studentID).
+		Exists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing check-in: %w", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("already checked in")
+	}
+
+	now := time.Now()
+	startAt := sessionDateTime(session.SessionDate, session.StartTime)
+	endAt := sessionDateTime(session.SessionDate, session.EndTime)
+	if now.After(endAt) {
+		return nil, fmt.Errorf("check-in window has closed")
+	}
+
+	status := "present"
+	lateMinutes := 0
+	if now.After(startAt.Add(time.Duration(session.LateThresholdMinutes) * time.Minute)) {
+		if !session.AllowLateCheck {
+			return nil, fmt.Errorf("check-in window has closed")
+		}
+		status = "late"
+		lateMinutes = int(now.Sub(startAt).Minutes())
+	}
+
+	record := &model.AttendanceRecords{
+		ID:              uuid.New(),
+		SessionID:       session.ID,
+		StudentID:       studentID,
+		Status:          status,
+		CheckInTime:     &now,
+		CheckInMethod:   &method,
+		CheckInLocation: req.Location,
+		LateMinutes:     lateMinutes,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(record).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to record check-in: %w", err)
+		}
+		return appendRecordRevision(ctx, tx, record, nil, &studentID, method, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// GetSessionRecordsService lists the attendance records of a session for its teacher
+func (s *AttendanceService) GetSessionRecordsService(ctx context.Context, sessionID uuid.UUID, teacherID uuid.UUID) ([]*model.AttendanceRecords, error) {
+	session, err := s.getSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := canManageSession(ctx, s.db, session, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("unauthorized to manage this session")
+	}
+
+	var records []*model.AttendanceRecords
+	err = s.db.NewSelect().
+		Model(&records).
+		Relation("Student").
+		Where("ar.session_id = ?", sessionID).
+		Order("ar.created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve attendance records: %w", err)
+	}
+
+	return records, nil
+}
+
+// MarkAttendanceService lets a teacher create or correct records for several students in one request
+func (s *AttendanceService) MarkAttendanceService(ctx context.Context, sessionID uuid.UUID, req *requests.MarkAttendanceRequest, teacherID uuid.UUID) ([]*model.AttendanceRecords, error) {
+	session, err := s.getSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := canManageSession(ctx, s.db, session, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("unauthorized to manage this session")
+	}
+
+	if session.Status == "cancelled" {
+		return nil, fmt.Errorf("session is cancelled")
+	}
+
+	if err := s.ensureSessionUnlocked(ctx, session); err != nil {
+		return nil, err
+	}
+
+	studentIDs := make([]uuid.UUID, 0, len(req.Records))
+	for _, item := range req.Records {
+		studentIDs = append(studentIDs, item.StudentID)
+	}
+
+	var enrolledIDs []uuid.UUID
+	err = s.db.NewSelect().
+		Model((*model.ClassroomStudents)(nil)).
+		Column("student_id").
+		Where("classroom_id = ? AND is_active = true", session.ClassroomID).
+		Where("student_id IN (?)", bun.In(studentIDs)).
+		Scan(ctx, &enrolledIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check enrollment: %w", err)
+	}
+
+	guestIDs, err := makeUpGuestIDs(ctx, s.db, sessionID, studentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	enrolled := make(map[uuid.UUID]bool, len(enrolledIDs)+len(guestIDs))
+	for _, id := range append(enrolledIDs, guestIDs...) {
+		enrolled[id] = true
+	}
+	for _, id := range studentIDs {
+		if !enrolled[id] {
+			return nil, fmt.Errorf("student is not enrolled in this classroom")
+		}
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		now := time.Now()
+		manual := "manual"
+
+		for _, item := range req.Records {
+			lateMinutes := 0
+			if item.LateMinutes != nil {
+				lateMinutes = *item.LateMinutes
+			}
+
+			var existing model.AttendanceRecords
+			err := tx.NewSelect().
+				Model(&existing).
+				Where("ar.session_id = ? AND ar.student_id = ?", sessionID, item.StudentID).
+				Scan(ctx)
+
+			if err == sql.ErrNoRows {
+				record := &model.AttendanceRecords{
+					ID:            uuid.New(),
+					SessionID:     sessionID,
+					StudentID:     item.StudentID,
+					Status:        item.Status,
+					CheckInMethod: &manual,
+					LateMinutes:   lateMinutes,
+					Notes:         item.Notes,
+					MarkedBy:      &teacherID,
+					CreatedAt:     now,
+					UpdatedAt:     now,
+				}
+				if item.Status == "present" || item.Status == "late" {
+					record.CheckInTime = &now
+				}
+				if _, err := tx.NewInsert().Model(record).Exec(ctx); err != nil {
+					return fmt.Errorf("failed to create attendance record: %w", err)
+				}
+				if err := appendRecordRevision(ctx, tx, record, nil, &teacherID, manual, req.Reason); err != nil {
+					return err
+				}
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to retrieve attendance record: %w", err)
+			}
+
+			query := tx.NewUpdate().
+				Model((*model.AttendanceRecords)(nil)).
+				Set("status = ?", item.Status).
+				Set("late_minutes = ?", lateMinutes).
+				Set("is_modified = true").
+				Set("modified_by = ?", teacherID).
+				Set("modified_at = ?", now).
+				Set("updated_at = ?", now).
+				Where("id = ?", existing.ID)
+			if item.Notes != nil {
+				query = query.Set("notes = ?", *item.Notes)
+			}
+			if _, err := query.Exec(ctx); err != nil {
+				return fmt.Errorf("failed to update attendance record: %w", err)
+			}
+
+			oldStatus := existing.Status
+			existing.Status = item.Status
+			if err := appendRecordRevision(ctx, tx, &existing, &oldStatus, &teacherID, manual, req.Reason); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetSessionRecordsService(ctx, sessionID, teacherID)
+}
+
+// UpdateAttendanceRecordService lets a teacher correct a single attendance record
+func (s *AttendanceService) UpdateAttendanceRecordService(ctx context.Context, recordID uuid.UUID, req *requests.UpdateAttendanceRecordRequest, teacherID uuid.UUID) (*model.AttendanceRecords, error) {
+	var record model.AttendanceRecords
+	err := s.db.NewSelect().
+		Model(&record).
+		Where("ar.id = ? AND ar.deleted_at IS NULL", recordID).
+		Scan(ctx)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("attendance record not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve attendance record: %w", err)
+	}
+
+	session, err := s.getSession(ctx, record.SessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := canManageSession(ctx, s.db, session, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("unauthorized to manage this session")
+	}
+
+	if err := s.ensureSessionUnlocked(ctx, session); err != nil {
+		return nil, err
+	}
+
+	// Build update data
+	updateData := make(map[string]interface{})
+
+	if req.Status != nil {
+		updateData["status"] = *req.Status
+	}
+	if req.LateMinutes != nil {
+		updateData["late_minutes"] = *req.LateMinutes
+	}
+	if req.Notes != nil {
+		updateData["notes"] = *req.Notes
+	}
+
+	if len(updateData) == 0 {
+		return nil, fmt.Errorf("no data to update")
+	}
+
+	now := time.Now()
+	updateData["is_modified"] = true
+	updateData["modified_by"] = teacherID
+	updateData["modified_at"] = now
+	updateData["updated_at"] = now
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		// Apply updates dynamically
+		query := tx.NewUpdate().Model(&record).Where("id = ?", recordID)
+		for key, value := range updateData {
+			query = query.Set("? = ?", bun.Ident(key), value)
+		}
+
+		if _, err := query.Exec(ctx); err != nil {
+			return fmt.Errorf("failed to update attendance record: %w", err)
+		}
+
+		oldStatus := record.Status
+		if req.Status != nil {
+			record.Status = *req.Status
+		}
+		return appendRecordRevision(ctx, tx, &record, &oldStatus, &teacherID, "manual", req.Reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.NewSelect().
+		Model(&record).
+		Relation("Student").
+		Relation("Modifier").
+		Where("ar.id = ?", recordID).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load updated attendance record: %w", err)
+	}
+
+	return &record, nil
+}
+
+// SignOffSessionService certifies a session's attendance, after which records can no longer change
+func (s *AttendanceService) SignOffSessionService(ctx context.Context, sessionID uuid.UUID, teacherID uuid.UUID) (*model.AttendanceSessions, error) {
+	session, err := s.getSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := canManageSession(ctx, s.db, session, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("unauthorized to manage this session")
+	}
+
+	if session.IsLocked {
+		return nil, fmt.Errorf("attendance session is already locked")
+	}
+
+	if err := s.lockSession(ctx, session, &teacherID, "attendance_session.sign_off"); err != nil {
+		return nil, err
+	}
+
+	return s.getSession(ctx, sessionID)
+}
+
+// UnlockSessionService reopens a locked session; only an admin of the classroom's school may do this
+func (s *AttendanceService) UnlockSessionService(ctx context.Context, sessionID uuid.UUID, adminID uuid.UUID, role string, reason string) (*model.AttendanceSessions, error) {
+	if !isAdminRole(role) {
+		return nil, fmt.Errorf("only school admins can unlock attendance")
+	}
+
+	session, err := s.getSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if role != "super_admin" {
+		var admin model.Users
+		err := s.db.NewSelect().
+			Model(&admin).
+			Where("u.id = ?", adminID).
+			Scan(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve admin: %w", err)
+		}
+		if admin.SchoolID == nil || session.Classroom == nil || session.Classroom.SchoolID == nil || *admin.SchoolID != *session.Classroom.SchoolID {
+			return nil, fmt.Errorf("only school admins can unlock attendance")
+		}
+	}
+
+	if !session.IsLocked {
+		return nil, fmt.Errorf("attendance session is not locked")
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		now := time.Now()
+		_, err := tx.NewUpdate().
+			Model((*model.AttendanceSessions)(nil)).
+			Set("is_locked = false").
+			Set("locked_by = NULL").
+			Set("locked_at = NULL").
+			Set("unlocked_at = ?", now).
+			Set("updated_at = ?", now).
+			Where("id = ?", session.ID).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to unlock session: %w", err)
+		}
+
+		return writeAuditLog(ctx, tx, &adminID, "attendance_session.unlock", "attendance_sessions", &session.ID,
+			map[string]interface{}{"is_locked": true, "locked_by": session.LockedBy, "locked_at": session.LockedAt},
+			map[string]interface{}{"is_locked": false, "unlocked_at": now},
+			map[string]interface{}{"reason": reason})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getSession(ctx, sessionID)
+}
+
+// LockExpiredSessionsService locks every session whose auto-lock period has passed and returns how many were locked
+func (s *AttendanceService) LockExpiredSessionsService(ctx context.Context) (int, error) {
+	days := attendanceAutoLockDays()
+	if days == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	var sessions []*model.AttendanceSessions
+	err := s.db.NewSelect().
+		Model(&sessions).
+		Where("?TableAlias.is_locked = false").
+		Where("?TableAlias.session_date <= ?", now.AddDate(0, 0, -days)).
+		Scan(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve sessions: %w", err)
+	}
+
+	locked := 0
+	for _, session := range sessions {
+		if !autoLockDue(session, now) {
+			continue
+		}
+		if err := s.lockSession(ctx, session, nil, "attendance_session.auto_lock"); err != nil {
+			return locked, err
+		}
+		locked++
+	}
+
+	return locked, nil
+}
+
+// getSession loads a session together with its classroom
+func (s *AttendanceService) getSession(ctx context.Context, sessionID uuid.UUID) (*model.AttendanceSessions, error) {
+	var session model.AttendanceSessions
+	err := s.db.NewSelect().
+		Model(&session).
+		Relation("Classroom").
+		Where("?TableAlias.id = ? AND ?TableAlias.deleted_at IS NULL", sessionID).
+		Scan(ctx)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("session not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// ensureSessionUnlocked rejects changes to a locked session, locking it first if the auto-lock period has passed
+func (s *AttendanceService) ensureSessionUnlocked(ctx context.Context, session *model.AttendanceSessions) error {
+	if session.IsLocked {
+		return fmt.Errorf("attendance session is locked")
+	}
+
+	if autoLockDue(session, time.Now()) {
+		if err := s.lockSession(ctx, session, nil, "attendance_session.auto_lock"); err != nil {
+			return err
+		}
+		return fmt.Errorf("attendance session is locked")
+	}
+
+	return nil
+}
+
+// lockSession marks a session as locked and records the action; lockedBy is nil for automatic locks
+func (s *AttendanceService) lockSession(ctx context.Context, session *model.AttendanceSessions, lockedBy *uuid.UUID, action string) error {
+	now := time.Now()
+
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().
+			Model((*model.AttendanceSessions)(nil)).
+			Set("is_locked = true").
+			Set("locked_by = ?", lockedBy).
+			Set("locked_at = ?", now).
+			Set("updated_at = ?", now).
+			Where("id = ? AND is_locked = false", session.ID).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to lock session: %w", err)
+		}
+
+		return writeAuditLog(ctx, tx, lockedBy, action, "attendance_sessions", &session.ID,
+			map[string]interface{}{"is_locked": false},
+			map[string]interface{}{"is_locked": true, "locked_by": lockedBy, "locked_at": now},
+			nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	session.IsLocked = true
+	session.LockedBy = lockedBy
+	session.LockedAt = &now
+	return nil
+}
+
+// canManageSession reports whether the user is the classroom teacher, or the session creator while still
+// teaching in the classroom
+func canManageSession(ctx context.Context, db bun.IDB, session *model.AttendanceSessions, userID uuid.UUID) (bool, error) {
+	if session.Classroom != nil && session.Classroom.TeacherID == userID {
+		return true, nil
+	}
+	if session.CreatedBy != userID {
+		return false, nil
+	}
+	return isClassroomStaff(ctx, db, session.ClassroomID, userID)
+}
+
+// canOverseeSession reports whether the user manages the session or administers its classroom's school
+func canOverseeSession(ctx context.Context, db bun.IDB, session *model.AttendanceSessions, userID uuid.UUID, role string) (bool, error) {
+	allowed, err := canManageSession(ctx, db, session, userID)
+	if err != nil || allowed {
+		return allowed, err
+	}
+	if session.Classroom == nil {
+		return false, nil
+	}
+	return isAdminOfSchool(ctx, db, session.Classroom.SchoolID, userID, role)
+}
+
+// autoLockDue reports whether ATTENDANCE_AUTO_LOCK_DAYS have passed since the session ended
+// (or since an admin last unlocked it)
+func autoLockDue(session *model.AttendanceSessions, now time.Time) bool {
+	days := attendanceAutoLockDays()
+	if days == 0 {
+		return false
+	}
+
+	anchor := sessionDateTime(session.SessionDate, session.EndTime)
+	if session.UnlockedAt != nil && session.UnlockedAt.After(anchor) {
+		anchor = *session.UnlockedAt
+	}
+
+	return now.After(anchor.AddDate(0, 0, days))
+}
@@ -0,0 +1,225 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/uptrace/bun"
+)
+
+// CancelSessionService cancels a session, voids the records already captured, notifies enrolled
+// students and optionally schedules a make-up session linked to the cancelled one
+func (s *AttendanceService) CancelSessionService(ctx context.Context, sessionID uuid.UUID, req *requests.CancelSessionRequest, teacherID uuid.UUID) (*model.AttendanceSessions, *model.AttendanceSessions, error) {
+	session, err := s.getSession(ctx, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allowed, err := canManageSession(ctx, s.db, session, teacherID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !allowed {
+		return nil, nil, fmt.Errorf("unauthorized to manage this session")
+	}
+
+	switch session.Status {
+	case "cancelled":
+		return nil, nil, fmt.Errorf("session is already cancelled")
+	case "completed":
+		return nil, nil, fmt.Errorf("completed sessions cannot be cancelled")
+	}
+
+	if err := s.ensureSessionUnlocked(ctx, session); err != nil {
+		return nil, nil, err
+	}
+
+	var makeUp *model.AttendanceSessions
+	if req.MakeUp != nil {
+		makeUp, err = buildMakeUpSession(session, req.MakeUp, teacherID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var schoolID *uuid.UUID
+		if session.Classroom != nil {
+			schoolID = session.Classroom.SchoolID
+		}
+		closed, err := noClassDates(ctx, s.db, schoolID, makeUp.SessionDate, makeUp.SessionDate)
+		if err != nil {
+			return nil, nil, err
+		}
+		if closed[makeUp.SessionDate.Format("2006-01-02")] {
+			return nil, nil, fmt.Errorf("make-up session falls on a holiday")
+		}
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		now := time.Now()
+
+		// Void captured records, keeping a revision so the history shows why they disappeared
+		var records []*model.AttendanceRecords
+		if err := tx.NewSelect().Model(&records).Where("ar.session_id = ?", session.ID).Scan(ctx); err != nil {
+			return fmt.Errorf("failed to retrieve attendance records: %w", err)
+		}
+		for _, record := range records {
+			oldStatus := record.Status
+			if err := appendRecordRevision(ctx, tx, record, &oldStatus, &teacherID, "void", req.Reason); err != nil {
+				return err
+			}
+		}
+		if len(records) > 0 {
+			if _, err := tx.NewDelete().Model((*model.AttendanceRecords)(nil)).Where("session_id = ?", session.ID).Exec(ctx); err != nil {
+				return fmt.Errorf("failed to void attendance records: %w", err)
+			}
+		}
+
+		_, err := tx.NewUpdate().
+			Model((*model.AttendanceSessions)(nil)).
+			Set("status = 'cancelled'").
+			Set("cancelled_at = ?", now).
+			Set("cancelled_by = ?", teacherID).
+			Set("cancel_reason = ?", req.Reason).
+			Set("revision = revision + 1").
+			Set("updated_at = ?", now).
+			Where("id = ?", session.ID).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to cancel session: %w", err)
+		}
+
+		if makeUp != nil {
+			if _, err := tx.NewInsert().Model(makeUp).Exec(ctx); err != nil {
+				return fmt.Errorf("failed to create make-up session: %w", err)
+			}
+		}
+
+		studentIDs, err := activeStudentIDs(ctx, tx, session.ClassroomID)
+		if err != nil {
+			return err
+		}
+
+		message := fmt.Sprintf("%s on %s has been cancelled.", session.Title, session.SessionDate.Format("2006-01-02"))
+		data := map[string]interface{}{
+			"session_id":   session.ID,
+			"classroom_id": session.ClassroomID,
+			"reason":       req.Reason,
+		}
+		if makeUp != nil {
+			message += fmt.Sprintf(" A make-up session is scheduled on %s at %s.", makeUp.SessionDate.Format("2006-01-02"), makeUp.StartTime.Format("15:04"))
+			data["make_up_session_id"] = makeUp.ID
+		}
+		if err := notifyUsers(ctx, tx, studentIDs, "warning", "Class cancelled", message, "attendance_session", &session.ID, data); err != nil {
+			return err
+		}
+
+		if err := writeAuditLog(ctx, tx, &teacherID, "attendance_session.cancel", "attendance_sessions", &session.ID,
+			map[string]interface{}{"status": session.Status},
+			map[string]interface{}{"status": "cancelled", "voided_records": len(records)},
+			data); err != nil {
+			return err
+		}
+
+		// Cancelled sessions drop out of the denominators, so refresh the month's analytics
+		return recomputeClassroomAnalytics(ctx, tx, session.ClassroomID, session.SessionDate)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cancelled, err := s.getSession(ctx, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cancelled, makeUp, nil
+}
+
+// buildMakeUpSession prepares (without saving) a session replacing the cancelled one
+func buildMakeUpSession(original *model.AttendanceSessions, req *requests.MakeUpSessionRequest, teacherID uuid.UUID) (*model.AttendanceSessions, error) {
+	sessionDate, startTime, endTime, err := parseSessionSchedule(req.SessionDate, req.StartTime, req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	title := "Make-up: " + original.Title
+	if req.Title != nil {
+		title = *req.Title
+	}
+
+	location := original.Location
+	if req.Location != nil {
+		location = req.Location
+	}
+
+	// bun writes time values as UTC timestamps, so store the calendar date and clock times in UTC
+	// rather than as school-local instants, which would shift them back by the zone offset
+	code := generateSessionCode()
+	return &model.AttendanceSessions{
+		ID:                   uuid.New(),
+		ClassroomID:          original.ClassroomID,
+		TermID:               original.TermID,
+		Title:                title,
+		Description:          original.Description,
+		SessionDate:          time.Date(sessionDate.Year(), sessionDate.Month(), sessionDate.Day(), 0, 0, 0, 0, time.UTC),
+		StartTime:            scheduleClock(startTime.Hour(), startTime.Minute()),
+		EndTime:              scheduleClock(endTime.Hour(), endTime.Minute()),
+		Status:               "scheduled",
+		Method:               original.Method,
+		SessionCode:          &code,
+		AllowLateCheck:       original.AllowLateCheck,
+		LateThresholdMinutes: original.LateThresholdMinutes,
+		Location:             location,
+		MakeUpForID:          &original.ID,
+		CreatedBy:            teacherID,
+		CreatedAt:            time.Now(),
+		UpdatedAt:            time.Now(),
+	}, nil
+}
+
+// parseSessionSchedule parses a YYYY-MM-DD date and HH:MM start/end times in the school's time zone
+func parseSessionSchedule(date, start, end string) (time.Time, time.Time, time.Time, error) {
+	sessionDate, err := time.ParseInLocation("2006-01-02", date, schoolLocation)
+	if err != nil {
+		return time.Time{}, time.Time{}, time.Time{}, fmt.Errorf("invalid session date")
+	}
+
+	startClock, err := time.Parse("15:04", start)
+	if err != nil {
+		return time.Time{}, time.Time{}, time.Time{}, fmt.Errorf("invalid session time")
+	}
+	endClock, err := time.Parse("15:04", end)
+	if err != nil {
+		return time.Time{}, time.Time{}, time.Time{}, fmt.Errorf("invalid session time")
+	}
+
+	startTime := sessionDateTime(sessionDate, startClock)
+	endTime := sessionDateTime(sessionDate, endClock)
+	if !endTime.After(startTime) {
+		return time.Time{}, time.Time{}, time.Time{}, fmt.Errorf("session end time must be after start time")
+	}
+
+	return sessionDate, startTime, endTime, nil
+}
+
+// scheduleClock builds a clock time on a fixed UTC date, which is what bun writes to a time column
+func scheduleClock(hour, minute int) time.Time {
+	return time.Date(2000, time.January, 1, hour, minute, 0, 0, time.UTC)
+}
+
+// generateSessionCode generates a short code students type to check in
+func generateSessionCode() string {
+	const charset = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	const codeLength = 6
+
+	code := make([]byte, codeLength)
+	for i := range code {
+		code[i] = charset[rand.Intn(len(charset))]
+	}
+	return string(code)
+}
@@ -0,0 +1,344 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/uptrace/bun"
+)
+
+// RequestMakeUpService links a student's absence to a session of another section of the same subject.
+// The absence only counts as made up once the original teacher approves it after the student attended.
+func (s *AttendanceService) RequestMakeUpService(ctx context.Context, req *requests.RequestMakeUpRequest, userID uuid.UUID, role string) (*model.AttendanceMakeUps, error) {
+	studentID := userID
+	if role != "student" {
+		if req.StudentID == nil {
+			return nil, fmt.Errorf("student_id is required")
+		}
+		studentID = *req.StudentID
+	}
+
+	original, err := s.getSession(ctx, req.OriginalSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if role != "student" {
+		allowed, err := canOverseeSession(ctx, s.db, original, userID, role)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, fmt.Errorf("unauthorized to manage this session")
+		}
+	}
+
+	if original.Status == "cancelled" {
+		return nil, fmt.Errorf("cancelled sessions cannot be made up")
+	}
+	if sessionDateTime(original.SessionDate, original.StartTime).After(time.Now()) {
+		return nil, fmt.Errorf("original session has not taken place yet")
+	}
+
+	makeUpSession, err := s.getSession(ctx, req.MakeUpSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if makeUpSession.ClassroomID == original.ClassroomID {
+		return nil, fmt.Errorf("make-up session must belong to another classroom")
+	}
+	if original.Classroom == nil || makeUpSession.Classroom == nil ||
+		!strings.EqualFold(strings.TrimSpace(original.Classroom.Subject), strings.TrimSpace(makeUpSession.Classroom.Subject)) {
+		return nil, fmt.Errorf("make-up session must be for the same subject")
+	}
+	if !sameSchool(original.Classroom.SchoolID, makeUpSession.Classroom.SchoolID) {
+		return nil, fmt.Errorf("make-up session must belong to the same school")
+	}
+	if makeUpSession.Status == "cancelled" {
+		return nil, fmt.Errorf("make-up session is cancelled")
+	}
+
+	enrolled, err := s.db.NewSelect().
+		Model((*model.ClassroomStudents)(nil)).
+		Where("classroom_id = ? AND student_id = ? AND is_active = true", original.ClassroomID, studentID).
+		Exists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check enrollment: %w", err)
+	}
+	if !enrolled {
+		return nil, fmt.Errorf("student is not enrolled in this classroom")
+	}
+
+	var record model.AttendanceRecords
+	err = s.db.NewSelect().
+		Model(&record).
+		Where("ar.session_id = ? AND ar.student_id = ?", original.ID, studentID).
+		Scan(ctx)
+	hasRecord := err == nil
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to retrieve attendance record: %w", err)
+	}
+	if hasRecord && record.Status != "absent" {
+		return nil, fmt.Errorf("only absences can be made up")
+	}
+
+	if hasRecord {
+		exists, err := s.db.NewSelect().
+			Model((*model.AttendanceMakeUps)(nil)).
+			Where("original_record_id = ? AND status <> 'rejected'", record.ID).
+			Exists(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check existing make-ups: %w", err)
+		}
+		if exists {
+			return nil, fmt.Errorf("absence already has a make-up request")
+		}
+	} else if err := s.ensureSessionUnlocked(ctx, original); err != nil {
+		// The absence record is written into the original session below, which a lock forbids
+		return nil, err
+	}
+
+	now := time.Now()
+	makeUp := &model.AttendanceMakeUps{
+		ID:                uuid.New(),
+		StudentID:         studentID,
+		OriginalSessionID: original.ID,
+		MakeUpSessionID:   makeUpSession.ID,
+		Status:            "pending",
+		Reason:            req.Reason,
+		RequestedBy:       userID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		// A session without a record already counts as an absence; store it so the make-up has something to link to
+		if !hasRecord {
+			record = model.AttendanceRecords{
+				ID:        uuid.New(),
+				SessionID: original.ID,
+				StudentID: studentID,
+				Status:    "absent",
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+			if _, err := tx.NewInsert().Model(&record).Exec(ctx); err != nil {
+				return fmt.Errorf("failed to create attendance record: %w", err)
+			}
+			if err := appendRecordRevision(ctx, tx, &record, nil, &userID, "make_up", req.Reason); err != nil {
+				return err
+			}
+		}
+
+		makeUp.OriginalRecordID = record.ID
+		if _, err := tx.NewInsert().Model(makeUp).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to create make-up request: %w", err)
+		}
+
+		if userID == studentID && original.Classroom != nil {
+			message := fmt.Sprintf("A student asked to make up %s on %s by attending another section.", original.Title, original.SessionDate.Format("2006-01-02"))
+			if err := notifyUsers(ctx, tx, []uuid.UUID{original.Classroom.TeacherID}, "info", "Make-up request", message,
+				"attendance_session", &original.ID, map[string]interface{}{"make_up_id": makeUp.ID}); err != nil {
+				return err
+			}
+		}
+
+		return writeAuditLog(ctx, tx, &userID, "attendance_make_up.request", "attendance_make_ups", &makeUp.ID, nil, makeUp, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return makeUp, nil
+}
+
+// ReviewMakeUpService approves or rejects a pending make-up. Approval requires the student to have
+// attended the make-up session; the original record stays absent and is reported as made up.
+func (s *AttendanceService) ReviewMakeUpService(ctx context.Context, makeUpID uuid.UUID, req *requests.ReviewMakeUpRequest, reviewerID uuid.UUID, role string) (*model.AttendanceMakeUps, error) {
+	makeUp, err := s.getMakeUp(ctx, makeUpID)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := s.getSession(ctx, makeUp.OriginalSessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := canOverseeSession(ctx, s.db, original, reviewerID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("unauthorized to manage this session")
+	}
+
+	if makeUp.Status != "pending" {
+		return nil, fmt.Errorf("make-up request has already been reviewed")
+	}
+
+	var attended model.AttendanceRecords
+	if req.Status == "approved" {
+		err := s.db.NewSelect().
+			Model(&attended).
+			Where("ar.session_id = ? AND ar.student_id = ?", makeUp.MakeUpSessionID, makeUp.StudentID).
+			Where("ar.status IN ('present', 'late')").
+			Scan(ctx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("student has not attended the make-up session")
+			}
+			return nil, fmt.Errorf("failed to retrieve attendance record: %w", err)
+		}
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		now := time.Now()
+		query := tx.NewUpdate().
+			Model((*model.AttendanceMakeUps)(nil)).
+			Set("status = ?", req.Status).
+			Set("reviewed_by = ?", reviewerID).
+			Set("reviewed_at = ?", now).
+			Set("review_note = ?", req.Note).
+			Set("updated_at = ?", now).
+			Where("id = ?", makeUp.ID)
+		if req.Status == "approved" {
+			query = query.Set("make_up_record_id = ?", attended.ID)
+		}
+		if _, err := query.Exec(ctx); err != nil {
+			return fmt.Errorf("failed to review make-up request: %w", err)
+		}
+
+		title := "Make-up rejected"
+		message := fmt.Sprintf("Your make-up for %s on %s was rejected.", original.Title, original.SessionDate.Format("2006-01-02"))
+		if req.Status == "approved" {
+			title = "Make-up approved"
+			message = fmt.Sprintf("Your absence from %s on %s has been made up.", original.Title, original.SessionDate.Format("2006-01-02"))
+
+			// Keep the credit visible on the original record's timeline without changing its status
+			var record model.AttendanceRecords
+			if err := tx.NewSelect().Model(&record).Where("ar.id = ?", makeUp.OriginalRecordID).Scan(ctx); err != nil {
+				return fmt.Errorf("failed to retrieve attendance record: %w", err)
+			}
+			oldStatus := record.Status
+			if err := appendRecordRevision(ctx, tx, &record, &oldStatus, &reviewerID, "make_up", req.Note); err != nil {
+				return err
+			}
+		}
+
+		if err := notifyUsers(ctx, tx, []uuid.UUID{makeUp.StudentID}, "info", title, message,
+			"attendance_session", &original.ID, map[string]interface{}{"make_up_id": makeUp.ID}); err != nil {
+			return err
+		}
+
+		if err := writeAuditLog(ctx, tx, &reviewerID, "attendance_make_up.review", "attendance_make_ups", &makeUp.ID,
+			map[string]interface{}{"status": makeUp.Status},
+			map[string]interface{}{"status": req.Status},
+			map[string]interface{}{"note": req.Note}); err != nil {
+			return err
+		}
+
+		if req.Status != "approved" {
+			return nil
+		}
+		return recomputeClassroomAnalytics(ctx, tx, original.ClassroomID, original.SessionDate)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getMakeUp(ctx, makeUpID)
+}
+
+// GetMakeUpsService lists make-ups: students see their own, teachers those of their classrooms, admins those
+// of their school and super admins all.
+// classroomID and status narrow the result when set.
+func (s *AttendanceService) GetMakeUpsService(ctx context.Context, userID uuid.UUID, role string, classroomID *uuid.UUID, status string) ([]*model.AttendanceMakeUps, error) {
+	var makeUps []*model.AttendanceMakeUps
+	query := s.db.NewSelect().
+		Model(&makeUps).
+		Relation("Student").
+		Relation("OriginalSession").
+		Relation("MakeUpSession").
+		Order("amu.created_at DESC")
+
+	switch role {
+	case "super_admin":
+	case "student":
+		query = query.Where("amu.student_id = ?", userID)
+	case "admin":
+		schoolID, err := userSchoolID(ctx, s.db, userID)
+		if err != nil {
+			return nil, err
+		}
+		if schoolID == nil {
+			return []*model.AttendanceMakeUps{}, nil
+		}
+		query = query.Where("original_session.classroom_id IN (?)",
+			s.db.NewSelect().Model((*model.Classrooms)(nil)).Column("id").Where("school_id = ?", *schoolID))
+	default:
+		query = query.Where("original_session.classroom_id IN (?)",
+			s.db.NewSelect().Model((*model.Classrooms)(nil)).Column("id").Where("teacher_id = ?", userID))
+	}
+
+	if classroomID != nil {
+		query = query.Where("original_session.classroom_id = ?", *classroomID)
+	}
+	if status != "" {
+		query = query.Where("amu.status = ?", status)
+	}
+
+	if err := query.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("failed to retrieve make-up requests: %w", err)
+	}
+
+	return makeUps, nil
+}
+
+// getMakeUp loads a make-up with both sessions and records
+func (s *AttendanceService) getMakeUp(ctx context.Context, makeUpID uuid.UUID) (*model.AttendanceMakeUps, error) {
+	var makeUp model.AttendanceMakeUps
+	err := s.db.NewSelect().
+		Model(&makeUp).
+		Relation("OriginalSession").
+		Relation("OriginalRecord").
+		Relation("MakeUpSession").
+		Relation("MakeUpRecord").
+		Where("amu.id = ?", makeUpID).
+		Scan(ctx)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("make-up request not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve make-up request: %w", err)
+	}
+
+	return &makeUp, nil
+}
+
+// makeUpGuestIDs returns which of studentIDs are visiting sessionID to make up an absence elsewhere
+func makeUpGuestIDs(ctx context.Context, db bun.IDB, sessionID uuid.UUID, studentIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var guestIDs []uuid.UUID
+	if len(studentIDs) == 0 {
+		return guestIDs, nil
+	}
+
+	err := db.NewSelect().
+		Model((*model.AttendanceMakeUps)(nil)).
+		Column("student_id").
+		Where("make_up_session_id = ? AND status <> 'rejected'", sessionID).
+		Where("student_id IN (?)", bun.In(studentIDs)).
+		Scan(ctx, &guestIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check make-up requests: %w", err)
+	}
+	return guestIDs, nil
+}
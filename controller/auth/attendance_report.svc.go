@@ -0,0 +1,299 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/uptrace/bun"
+)
+
+// StudentAttendanceSummary is one student's attendance totals over a period
+type StudentAttendanceSummary struct {
+	StudentID          uuid.UUID `json:"student_id"`
+	StudentNumber      *string   `json:"student_number"`
+	FirstName          string    `json:"first_name"`
+	LastName           string    `json:"last_name"`
+	TotalSessions      int       `json:"total_sessions"`
+	PresentCount       int       `json:"present_count"`
+	LateCount          int       `json:"late_count"`
+	ExcusedCount       int       `json:"excused_count"`
+	MadeUpCount        int       `json:"made_up_count"`
+	AbsentCount        int       `json:"absent_count"`
+	AttendanceRate     float64   `json:"attendance_rate"`
+	AverageLateMinutes float64   `json:"average_late_minutes"`
+	IsEligible         bool      `json:"is_eligible"`
+}
+
+// attendanceEligibilityPercent returns ATTENDANCE_ELIGIBILITY_PERCENT (default 80)
+func attendanceEligibilityPercent() float64 {
+	percent, err := strconv.ParseFloat(os.Getenv("ATTENDANCE_ELIGIBILITY_PERCENT"), 64)
+	if err != nil || percent <= 0 {
+		return 80
+	}
+	return percent
+}
+
+// countableSessionIDs returns the classroom's sessions between from and to (inclusive) that count towards
+// attendance totals, leaving out cancelled sessions and sessions on holidays that cancel classes
+func countableSessionIDs(ctx context.Context, db bun.IDB, classroomID uuid.UUID, from, to time.Time) ([]uuid.UUID, error) {
+	var classroom model.Classrooms
+	err := db.NewSelect().
+		Model(&classroom).
+		Column("school_id").
+		Where("id = ?", classroomID).
+		WhereAllWithDeleted().
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve classroom: %w", err)
+	}
+
+	var sessions []*model.AttendanceSessions
+	err = db.NewSelect().
+		Model(&sessions).
+		Column("id", "session_date").
+		Where("?TableAlias.classroom_id = ?", classroomID).
+		Where("?TableAlias.session_date BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Where("?TableAlias.status != 'cancelled'").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve sessions: %w", err)
+	}
+
+	closed, err := noClassDates(ctx, db, classroom.SchoolID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(sessions))
+	for _, session := range sessions {
+		if !closed[session.SessionDate.Format("2006-01-02")] {
+			ids = append(ids, session.ID)
+		}
+	}
+	return ids, nil
+}
+
+// GetClassroomAttendanceSummaryService returns per-student attendance totals and eligibility for a classroom,
+// over its term by default
+func (s *AttendanceService) GetClassroomAttendanceSummaryService(ctx context.Context, classroomID uuid.UUID, userID uuid.UUID, role string, from, to *time.Time) ([]*StudentAttendanceSummary, error) {
+	var classroom model.Classrooms
+	err := s.db.NewSelect().
+		Model(&classroom).
+		Where("c.id = ? AND c.deleted_at IS NULL", classroomID).
+		Scan(ctx)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("classroom not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve classroom: %w", err)
+	}
+
+	allowed, err := canManageClassroom(ctx, s.db, &classroom, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("unauthorized to view this classroom")
+	}
+
+	// Reports and eligibility cover the classroom's term unless a range is given
+	periodFrom, periodTo, err := termPeriod(ctx, s.db, &classroom)
+	if err != nil {
+		return nil, err
+	}
+	if from != nil {
+		periodFrom = *from
+	}
+	if to != nil {
+		periodTo = *to
+	}
+
+	return summarizeAttendance(ctx, s.db, classroomID, periodFrom, periodTo)
+}
+
+// summarizeAttendance totals attendance for every active student in a classroom between from and to (inclusive).
+// Cancelled sessions, sessions on holidays and sessions that have not happened yet are left out of the denominator, a session
+// without a record for the student counts as an absence, and approved make-ups count as attended.
+func summarizeAttendance(ctx context.Context, db bun.IDB, classroomID uuid.UUID, from, to time.Time) ([]*StudentAttendanceSummary, error) {
+	today := time.Now().In(schoolLocation)
+	if to.After(today) {
+		to = today
+	}
+
+	sessionIDs, err := countableSessionIDs(ctx, db, classroomID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var students []*model.ClassroomStudents
+	err = db.NewSelect().
+		Model(&students).
+		Relation("Student").
+		Where("cs.classroom_id = ? AND cs.is_active = true", classroomID).
+		Order("cs.student_number ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve classroom students: %w", err)
+	}
+
+	var counts []struct {
+		StudentID   uuid.UUID `bun:"student_id"`
+		Status      string    `bun:"status"`
+		Count       int       `bun:"count"`
+		LateMinutes int       `bun:"late_minutes"`
+	}
+	if len(sessionIDs) > 0 {
+		err = db.NewSelect().
+			Model((*model.AttendanceRecords)(nil)).
+			Column("student_id", "status").
+			ColumnExpr("COUNT(*) AS count").
+			ColumnExpr("COALESCE(SUM(late_minutes), 0) AS late_minutes").
+			Where("session_id IN (?)", bun.In(sessionIDs)).
+			Group("student_id", "status").
+			Scan(ctx, &counts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count attendance records: %w", err)
+		}
+	}
+
+	var madeUp []struct {
+		StudentID uuid.UUID `bun:"student_id"`
+		Count     int       `bun:"count"`
+	}
+	if len(sessionIDs) > 0 {
+		err = db.NewSelect().
+			Model((*model.AttendanceMakeUps)(nil)).
+			Column("student_id").
+			ColumnExpr("COUNT(*) AS count").
+			Where("original_session_id IN (?)", bun.In(sessionIDs)).
+			Where("status = 'approved'").
+			// Ignore make-ups whose absence was later corrected to another status
+			Where("original_record_id IN (?)", db.NewSelect().Model((*model.AttendanceRecords)(nil)).Column("id").Where("status = 'absent'")).
+			Group("student_id").
+			Scan(ctx, &madeUp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count make-ups: %w", err)
+		}
+	}
+
+	summaries := make([]*StudentAttendanceSummary, 0, len(students))
+	byStudent := make(map[uuid.UUID]*StudentAttendanceSummary, len(students))
+	lateMinutes := make(map[uuid.UUID]int, len(students))
+	for _, enrollment := range students {
+		summary := &StudentAttendanceSummary{
+			StudentID:     enrollment.StudentID,
+			StudentNumber: enrollment.StudentNumber,
+			TotalSessions: len(sessionIDs),
+		}
+		if enrollment.Student != nil {
+			summary.FirstName = enrollment.Student.FirstName
+			summary.LastName = enrollment.Student.LastName
+		}
+		summaries = append(summaries, summary)
+		byStudent[enrollment.StudentID] = summary
+	}
+
+	for _, count := range counts {
+		summary, ok := byStudent[count.StudentID]
+		if !ok {
+			continue
+		}
+		switch count.Status {
+		case "present":
+			summary.PresentCount += count.Count
+		case "late":
+			summary.LateCount += count.Count
+			lateMinutes[count.StudentID] += count.LateMinutes
+		case "excused":
+			summary.ExcusedCount += count.Count
+		}
+	}
+
+	for _, count := range madeUp {
+		if summary, ok := byStudent[count.StudentID]; ok {
+			summary.MadeUpCount = count.Count
+		}
+	}
+
+	threshold := attendanceEligibilityPercent()
+	for _, summary := range summaries {
+		// Made-up absences stay visible as their own count instead of disappearing from the totals
+		summary.AbsentCount = summary.TotalSessions - summary.PresentCount - summary.LateCount - summary.ExcusedCount - summary.MadeUpCount
+		if summary.AbsentCount < 0 {
+			summary.AbsentCount = 0
+		}
+
+		// Excused absences neither help nor hurt the rate
+		denominator := summary.TotalSessions - summary.ExcusedCount
+		summary.AttendanceRate = 100
+		if denominator > 0 {
+			summary.AttendanceRate = float64(summary.PresentCount+summary.LateCount+summary.MadeUpCount) * 100 / float64(denominator)
+		}
+		if summary.LateCount > 0 {
+			summary.AverageLateMinutes = float64(lateMinutes[summary.StudentID]) / float64(summary.LateCount)
+		}
+		summary.IsEligible = summary.AttendanceRate >= threshold
+	}
+
+	return summaries, nil
+}
+
+// recomputeClassroomAnalytics refreshes attendance_analytics for the month containing day
+func recomputeClassroomAnalytics(ctx context.Context, db bun.IDB, classroomID uuid.UUID, day time.Time) error {
+	monthStart := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, schoolLocation)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+	monthYear := monthStart.Format("2006-01")
+
+	summaries, err := summarizeAttendance(ctx, db, classroomID, monthStart, monthEnd)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, summary := range summaries {
+		var analytics model.AttendanceAnalytics
+		err := db.NewSelect().
+			Model(&analytics).
+			Where("aa.classroom_id = ? AND aa.student_id = ? AND aa.month_year = ?", classroomID, summary.StudentID, monthYear).
+			Scan(ctx)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to retrieve attendance analytics: %w", err)
+		}
+
+		if err == sql.ErrNoRows {
+			analytics = model.AttendanceAnalytics{
+				ID:          uuid.New(),
+				ClassroomID: classroomID,
+				StudentID:   summary.StudentID,
+				MonthYear:   monthYear,
+				CreatedAt:   now,
+			}
+		}
+
+		analytics.TotalSessions = summary.TotalSessions
+		analytics.PresentCount = summary.PresentCount
+		analytics.AbsentCount = summary.AbsentCount
+		analytics.LateCount = summary.LateCount
+		analytics.ExcusedCount = summary.ExcusedCount
+		analytics.MadeUpCount = summary.MadeUpCount
+		analytics.AttendanceRate = summary.AttendanceRate
+		analytics.AverageLateMinutes = summary.AverageLateMinutes
+		analytics.UpdatedAt = now
+
+		if _, err := db.NewInsert().
+			Model(&analytics).
+			On("CONFLICT (id) DO UPDATE").
+			Exec(ctx); err != nil {
+			return fmt.Errorf("failed to save attendance analytics: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/uptrace/bun"
+)
+
+// appendRecordRevision stores one step of a record's history; oldStatus is nil when the record was just created
+func appendRecordRevision(ctx context.Context, db bun.IDB, record *model.AttendanceRecords, oldStatus *string, changedBy *uuid.UUID, method string, reason *string) error {
+	revision := &model.AttendanceRecordRevisions{
+		ID:        uuid.New(),
+		RecordID:  record.ID,
+		SessionID: record.SessionID,
+		StudentID: record.StudentID,
+		OldStatus: oldStatus,
+		NewStatus: record.Status,
+		ChangedBy: changedBy,
+		Method:    method,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+
+	if _, err := db.NewInsert().Model(revision).Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record attendance revision: %w", err)
+	}
+	return nil
+}
+
+// GetRecordTimelineService returns a record and every revision made to it, oldest first
+func (s *AttendanceService) GetRecordTimelineService(ctx context.Context, recordID uuid.UUID, userID uuid.UUID, role string) (*model.AttendanceRecords, []*model.AttendanceRecordRevisions, error) {
+	var record model.AttendanceRecords
+	err := s.db.NewSelect().
+		Model(&record).
+		Relation("Student").
+		Where("ar.id = ?", recordID).
+		Scan(ctx)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, fmt.Errorf("attendance record not found")
+		}
+		return nil, nil, fmt.Errorf("failed to retrieve attendance record: %w", err)
+	}
+
+	session, err := s.getSession(ctx, record.SessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Students may see their own history; teachers and admins may see anyone's
+	if record.StudentID != userID {
+		allowed, err := canOverseeSession(ctx, s.db, session, userID, role)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !allowed {
+			return nil, nil, fmt.Errorf("unauthorized to manage this session")
+		}
+	}
+
+	var revisions []*model.AttendanceRecordRevisions
+	err = s.db.NewSelect().
+		Model(&revisions).
+		Relation("Actor").
+		Where("arr.record_id = ?", recordID).
+		Order("arr.created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve attendance revisions: %w", err)
+	}
+
+	return &record, revisions, nil
+}
+
+// GetSessionRevisionsService returns every revision made to records in a session, oldest first
+func (s *AttendanceService) GetSessionRevisionsService(ctx context.Context, sessionID uuid.UUID, userID uuid.UUID, role string) ([]*model.AttendanceRecordRevisions, error) {
+	session, err := s.getSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := canOverseeSession(ctx, s.db, session, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("unauthorized to manage this session")
+	}
+
+	var revisions []*model.AttendanceRecordRevisions
+	err = s.db.NewSelect().
+		Model(&revisions).
+		Relation("Student").
+		Relation("Actor").
+		Where("arr.session_id = ?", sessionID).
+		Order("arr.created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve attendance revisions: %w", err)
+	}
+
+	return revisions, nil
+}
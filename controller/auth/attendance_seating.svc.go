@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/requests"
+)
+
+// SeatAttendanceService records attendance from taps on the classroom's seat map. Each tapped seat is
+// translated to the student sitting there and the result is saved like MarkAttendanceService.
+func (s *AttendanceService) SeatAttendanceService(ctx context.Context, sessionID uuid.UUID, req *requests.SeatAttendanceRequest, teacherID uuid.UUID) ([]*model.AttendanceRecords, error) {
+	session, err := s.getSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := canManageSession(ctx, s.db, session, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("unauthorized to manage this session")
+	}
+
+	layout, err := loadSeatingLayout(ctx, s.db, session.ClassroomID)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := buildSeatingPlan(ctx, s.db, layout)
+	if err != nil {
+		return nil, err
+	}
+
+	occupants := make(map[string]uuid.UUID)
+	for _, row := range plan.Seats {
+		for _, seat := range row {
+			if seat.StudentID != nil {
+				occupants[seat.Label] = *seat.StudentID
+			}
+		}
+	}
+
+	mark := &requests.MarkAttendanceRequest{Reason: req.Reason}
+	tapped := make(map[string]bool, len(req.Seats))
+	for _, item := range req.Seats {
+		row, column, err := parseSeatLabel(item.Seat, layout.Rows, layout.Columns)
+		if err != nil {
+			return nil, err
+		}
+		label := seatLabel(row, column)
+		if tapped[label] {
+			return nil, fmt.Errorf("seat is listed more than once")
+		}
+		tapped[label] = true
+
+		studentID, ok := occupants[label]
+		if !ok {
+			return nil, fmt.Errorf("seat is empty")
+		}
+		mark.Records = append(mark.Records, requests.MarkAttendanceItem{
+			StudentID:   studentID,
+			Status:      item.Status,
+			LateMinutes: item.LateMinutes,
+			Notes:       item.Notes,
+		})
+	}
+
+	if req.UnmarkedStatus != nil {
+		for _, row := range plan.Seats {
+			for _, seat := range row {
+				if seat.StudentID != nil && !tapped[seat.Label] {
+					mark.Records = append(mark.Records, requests.MarkAttendanceItem{
+						StudentID: *seat.StudentID,
+						Status:    *req.UnmarkedStatus,
+					})
+				}
+			}
+		}
+	}
+
+	if len(mark.Records) == 0 {
+		return nil, fmt.Errorf("no data to update")
+	}
+
+	return s.MarkAttendanceService(ctx, sessionID, mark, teacherID)
+}
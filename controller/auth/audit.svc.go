@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/uptrace/bun"
+)
+
+// isAdminRole reports whether role may perform school administration actions
+func isAdminRole(role string) bool {
+	return role == "admin" || role == "super_admin"
+}
+
+// writeAuditLog records an action in audit_logs; values are stored as JSON
+func writeAuditLog(ctx context.Context, db bun.IDB, userID *uuid.UUID, action, table string, recordID *uuid.UUID, oldValues, newValues, additionalData any) error {
+	auditLog := &model.AuditLogs{
+		ID:             uuid.New(),
+		UserID:         userID,
+		Action:         action,
+		Table:          table,
+		RecordID:       recordID,
+		OldValues:      toJSONString(oldValues),
+		NewValues:      toJSONString(newValues),
+		AdditionalData: toJSONString(additionalData),
+		CreatedAt:      time.Now(),
+	}
+
+	if _, err := db.NewInsert().Model(auditLog).Exec(ctx); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// toJSONString marshals v for a jsonb column, returning nil for nil values
+func toJSONString(v any) *string {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	str := string(data)
+	return &str
+}
@@ -0,0 +1,334 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/utils/ical"
+	"github.com/uptrace/bun"
+)
+
+const (
+	// calendarFeedPastDays and calendarFeedFutureDays bound what a feed publishes
+	calendarFeedPastDays   = 30
+	calendarFeedFutureDays = 365
+	calendarUIDDomain      = "easy-attend"
+)
+
+// CreateCalendarFeedService issues a subscription URL for the user's personal feed, or for one classroom
+// when classroomID is set. Any earlier feed for the same scope is revoked so a leaked URL can be replaced.
+func (s *ScheduleService) CreateCalendarFeedService(ctx context.Context, userID uuid.UUID, classroomID *uuid.UUID) (*model.CalendarFeeds, error) {
+	if classroomID != nil {
+		_, roles, err := s.userClassrooms(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := roles[*classroomID]; !ok {
+			return nil, fmt.Errorf("not a member of this classroom")
+		}
+	}
+
+	token, err := generateFeedToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate feed token: %w", err)
+	}
+
+	now := time.Now()
+	feed := &model.CalendarFeeds{
+		ID:          uuid.New(),
+		UserID:      userID,
+		ClassroomID: classroomID,
+		Token:       token,
+		CreatedAt:   now,
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		query := tx.NewUpdate().
+			Model((*model.CalendarFeeds)(nil)).
+			Set("revoked_at = ?", now).
+			Where("user_id = ? AND revoked_at IS NULL", userID)
+		if classroomID != nil {
+			query = query.Where("classroom_id = ?", *classroomID)
+		} else {
+			query = query.Where("classroom_id IS NULL")
+		}
+		if _, err := query.Exec(ctx); err != nil {
+			return fmt.Errorf("failed to revoke previous calendar feed: %w", err)
+		}
+
+		if _, err := tx.NewInsert().Model(feed).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to create calendar feed: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	feed.URL = calendarFeedURL(feed.Token)
+	return feed, nil
+}
+
+// GetCalendarFeedsService lists the user's active feeds
+func (s *ScheduleService) GetCalendarFeedsService(ctx context.Context, userID uuid.UUID) ([]*model.CalendarFeeds, error) {
+	var feeds []*model.CalendarFeeds
+	err := s.db.NewSelect().
+		Model(&feeds).
+		Relation("Classroom").
+		Where("cf.user_id = ? AND cf.revoked_at IS NULL", userID).
+		Order("cf.created_at DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve calendar feeds: %w", err)
+	}
+
+	for _, feed := range feeds {
+		feed.URL = calendarFeedURL(feed.Token)
+	}
+	return feeds, nil
+}
+
+// RevokeCalendarFeedService stops a feed URL from working
+func (s *ScheduleService) RevokeCalendarFeedService(ctx context.Context, userID uuid.UUID, feedID uuid.UUID) error {
+	res, err := s.db.NewUpdate().
+		Model((*model.CalendarFeeds)(nil)).
+		Set("revoked_at = ?", time.Now()).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", feedID, userID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to revoke calendar feed: %w", err)
+	}
+	if affected, _ := res.RowsAffected(); affected == 0 {
+		return fmt.Errorf("calendar feed not found")
+	}
+	return nil
+}
+
+// RenderCalendarFeedService builds the iCalendar document for a feed token. It publishes sessions, assignment
+// due dates and calendar events from calendarFeedPastDays ago up to calendarFeedFutureDays ahead.
+func (s *ScheduleService) RenderCalendarFeedService(ctx context.Context, token string) ([]byte, error) {
+	var feed model.CalendarFeeds
+	err := s.db.NewSelect().
+		Model(&feed).
+		Relation("User").
+		Where("cf.token = ? AND cf.revoked_at IS NULL", token).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("calendar feed not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve calendar feed: %w", err)
+	}
+	if feed.User == nil || !feed.User.IsActive || feed.User.DeletedAt != nil {
+		return nil, fmt.Errorf("calendar feed not found")
+	}
+
+	classrooms, roles, err := s.userClassrooms(ctx, feed.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	calendar := &ical.Calendar{Name: "Easy Attend", Refresh: time.Hour, Generated: time.Now()}
+	if feed.ClassroomID != nil {
+		classroom, ok := classrooms[*feed.ClassroomID]
+		if !ok {
+			// The user has left the classroom since subscribing
+			return nil, fmt.Errorf("calendar feed not found")
+		}
+		classrooms = map[uuid.UUID]*model.Classrooms{classroom.ID: classroom}
+		calendar.Name = "Easy Attend - " + classroom.Name
+	}
+
+	today := time.Now().In(schoolLocation)
+	from := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -calendarFeedPastDays)
+	to := from.AddDate(0, 0, calendarFeedPastDays+calendarFeedFutureDays)
+
+	if len(classrooms) > 0 {
+		sessionEvents, err := s.sessionFeedEvents(ctx, classrooms, from, to)
+		if err != nil {
+			return nil, err
+		}
+		calendar.Events = append(calendar.Events, sessionEvents...)
+
+		assignmentEvents, err := s.assignmentFeedEvents(ctx, classrooms, roles, from, to)
+		if err != nil {
+			return nil, err
+		}
+		calendar.Events = append(calendar.Events, assignmentEvents...)
+	}
+
+	events, err := s.calendarEvents(ctx, feed.UserID, classrooms, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, occurrence := range events {
+		calendar.Events = append(calendar.Events, academicFeedEvent(occurrence))
+	}
+
+	_, err = s.db.NewUpdate().
+		Model((*model.CalendarFeeds)(nil)).
+		Set("last_accessed_at = ?", time.Now()).
+		Where("id = ?", feed.ID).
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update calendar feed: %w", err)
+	}
+
+	return calendar.Encode(), nil
+}
+
+// sessionFeedEvents turns attendance sessions into events; cancelled sessions stay in the feed as CANCELLED
+// so subscribed calendars update the entry instead of silently dropping it
+func (s *ScheduleService) sessionFeedEvents(ctx context.Context, classrooms map[uuid.UUID]*model.Classrooms, from, to time.Time) ([]ical.Event, error) {
+	var sessions []*model.AttendanceSessions
+	err := s.db.NewSelect().
+		Model(&sessions).
+		Where("?TableAlias.classroom_id IN (?)", bun.In(classroomKeys(classrooms))).
+		Where("?TableAlias.session_date BETWEEN ? AND ?", from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Order("session_date ASC", "start_time ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve sessions: %w", err)
+	}
+
+	events := make([]ical.Event, 0, len(sessions))
+	for _, session := range sessions {
+		classroom := classrooms[session.ClassroomID]
+		event := ical.Event{
+			UID:      "session-" + session.ID.String() + "@" + calendarUIDDomain,
+			Summary:  classroom.Name + ": " + session.Title,
+			Start:    sessionDateTime(session.SessionDate, session.StartTime),
+			End:      sessionDateTime(session.SessionDate, session.EndTime),
+			Status:   "CONFIRMED",
+			Updated:  session.UpdatedAt,
+			Sequence: int64(session.Revision),
+		}
+		if session.Description != nil {
+			event.Description = *session.Description
+		}
+		if session.Location != nil {
+			event.Location = *session.Location
+		} else if classroom.RoomNumber != nil {
+			event.Location = *classroom.RoomNumber
+		}
+		if session.Status == "cancelled" {
+			event.Status = "CANCELLED"
+			event.Summary = "Cancelled: " + event.Summary
+			if session.CancelReason != nil {
+				event.Description = strings.TrimSpace("Cancelled: " + *session.CancelReason + "\n" + event.Description)
+			}
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// assignmentFeedEvents publishes due dates; students only see published assignments
+func (s *ScheduleService) assignmentFeedEvents(ctx context.Context, classrooms map[uuid.UUID]*model.Classrooms, roles map[uuid.UUID]string, from, to time.Time) ([]ical.Event, error) {
+	var staffClassrooms []uuid.UUID
+	for id := range classrooms {
+		if roles[id] != "student" && roles[id] != "observer" {
+			staffClassrooms = append(staffClassrooms, id)
+		}
+	}
+
+	var assignments []*model.Assignments
+	query := s.db.NewSelect().
+		Model(&assignments).
+		Where("a.classroom_id IN (?) AND a.due_date IS NOT NULL", bun.In(classroomKeys(classrooms))).
+		Where("a.due_date BETWEEN ? AND ?", from, to.AddDate(0, 0, 1)).
+		Order("a.due_date ASC")
+	if len(staffClassrooms) > 0 {
+		query = query.Where("a.is_published = true OR a.classroom_id IN (?)", bun.In(staffClassrooms))
+	} else {
+		query = query.Where("a.is_published = true")
+	}
+
+	if err := query.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("failed to retrieve assignments: %w", err)
+	}
+
+	events := make([]ical.Event, 0, len(assignments))
+	for _, assignment := range assignments {
+		event := ical.Event{
+			UID:      "assignment-" + assignment.ID.String() + "@" + calendarUIDDomain,
+			Summary:  "Due: " + assignment.Title + " (" + classrooms[assignment.ClassroomID].Name + ")",
+			Start:    *assignment.DueDate,
+			End:      *assignment.DueDate,
+			Updated:  assignment.UpdatedAt,
+			Sequence: int64(assignment.Revision),
+		}
+		if assignment.Description != nil {
+			event.Description = *assignment.Description
+		}
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// academicFeedEvent turns a calendar event occurrence into an all-day event; iCalendar end dates are
+// exclusive. Each occurrence of a recurring event gets its own UID so calendars keep them apart.
+func academicFeedEvent(occurrence *CalendarOccurrence) ical.Event {
+	event := occurrence.Event
+	start, _ := time.Parse("2006-01-02", occurrence.Date)
+	end, _ := time.Parse("2006-01-02", occurrence.EndDate)
+
+	uid := "event-" + event.ID.String()
+	if event.IsRecurring {
+		uid += "-" + start.Format("20060102")
+	}
+
+	feedEvent := ical.Event{
+		UID:      uid + "@" + calendarUIDDomain,
+		Summary:  event.Title,
+		Start:    start,
+		End:      end.AddDate(0, 0, 1),
+		AllDay:   true,
+		Updated:  event.UpdatedAt,
+		Sequence: int64(event.Revision),
+	}
+	if event.Description != nil {
+		feedEvent.Description = *event.Description
+	}
+	return feedEvent
+}
+
+// classroomKeys returns the IDs of a classroom map
+func classroomKeys(classrooms map[uuid.UUID]*model.Classrooms) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(classrooms))
+	for id := range classrooms {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// generateFeedToken returns a random, URL-safe feed token
+func generateFeedToken() (string, error) {
+	raw := make([]byte, 24)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// calendarFeedURL builds the subscription URL for a token (CALENDAR_FEED_BASE_URL, default http://localhost:8080/api/v1/ical)
+func calendarFeedURL(token string) string {
+	godotenv.Load()
+
+	base := os.Getenv("CALENDAR_FEED_BASE_URL")
+	if base == "" {
+		base = "http://localhost:8080/api/v1/ical"
+	}
+	return strings.TrimRight(base, "/") + "/" + token + ".ics"
+}
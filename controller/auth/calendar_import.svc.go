@@ -0,0 +1,420 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/utils/ical"
+	"github.com/uptrace/bun"
+)
+
+// MaxCalendarImportEvents caps the number of events accepted in one import
+const MaxCalendarImportEvents = 2000
+
+// CalendarImportOptions describes where imported events go
+type CalendarImportOptions struct {
+	Source           string     // file name or bundled source name, echoed in the report
+	SchoolID         *uuid.UUID // nil imports events that apply to every school
+	DefaultEventType string     // used when an event's categories do not map to an event type
+	DryRun           bool
+}
+
+// CalendarImportEvent is the outcome for one VEVENT
+type CalendarImportEvent struct {
+	UID       string     `json:"uid"`
+	Title     string     `json:"title"`
+	EventType string     `json:"event_type"`
+	StartDate string     `json:"start_date"`
+	EndDate   *string    `json:"end_date,omitempty"`
+	RRule     *string    `json:"rrule,omitempty"`
+	Action    string     `json:"action"` // create, update, unchanged, remove, skip or invalid
+	EventID   *uuid.UUID `json:"event_id,omitempty"`
+	Note      string     `json:"note,omitempty"`
+	Errors    []string   `json:"errors,omitempty"`
+
+	event *model.AcademicCalendar
+}
+
+// CalendarImportReport summarizes an iCalendar import or dry run
+type CalendarImportReport struct {
+	DryRun      bool                   `json:"dry_run"`
+	Committed   bool                   `json:"committed"`
+	Source      string                 `json:"source"`
+	SchoolID    *uuid.UUID             `json:"school_id"`
+	TotalEvents int                    `json:"total_events"`
+	Created     int                    `json:"created"`
+	Updated     int                    `json:"updated"`
+	Unchanged   int                    `json:"unchanged"`
+	Removed     int                    `json:"removed"`
+	Skipped     int                    `json:"skipped"`
+	Invalid     int                    `json:"invalid"`
+	Events      []*CalendarImportEvent `json:"events"`
+}
+
+// calendarImportCategories maps iCalendar CATEGORIES values (English and Thai) to event types
+var calendarImportCategories = map[string]string{
+	"holiday":         "holiday",
+	"holidays":        "holiday",
+	"public holiday":  "holiday",
+	"public holidays": "holiday",
+	"วันหยุด":         "holiday",
+	"วันหยุดราชการ":     "holiday",
+	"วันหยุดนักขัตฤกษ์": "holiday",
+	"exam":        "exam",
+	"exams":       "exam",
+	"examination": "exam",
+	"สอบ":         "exam",
+	"วันสอบ":      "exam",
+	"meeting":     "meeting",
+	"ประชุม":      "meeting",
+	"deadline":    "assignment_due",
+	"due":         "assignment_due",
+	"assignment":  "assignment_due",
+}
+
+// ImportCalendarService imports an iCalendar file into the academic calendar of a school the user manages.
+// Events are matched to earlier imports by UID, so re-importing an updated file changes events in place.
+// Nothing is written when opts.DryRun is set or any event is invalid.
+func (s *CalendarService) ImportCalendarService(ctx context.Context, file io.Reader, opts CalendarImportOptions, userID uuid.UUID, role string) (*CalendarImportReport, error) {
+	schoolID, err := s.calendarSchoolForEditor(ctx, opts.SchoolID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	opts.SchoolID = schoolID
+
+	return s.importCalendar(ctx, file, opts, userID)
+}
+
+// ImportCalendarAsSystemService imports an iCalendar file without a permission check, for the CLI. Events
+// are recorded as created by createdBy, or by the first super admin when it is nil.
+func (s *CalendarService) ImportCalendarAsSystemService(ctx context.Context, file io.Reader, opts CalendarImportOptions, createdBy *uuid.UUID) (*CalendarImportReport, error) {
+	if createdBy == nil {
+		var admin model.Users
+		err := s.db.NewSelect().
+			Model(&admin).
+			Column("id").
+			Where("role = ? AND is_active = true", "super_admin").
+			Order("created_at ASC").
+			Limit(1).
+			Scan(ctx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("no super admin account to own imported events")
+			}
+			return nil, fmt.Errorf("failed to retrieve super admin: %w", err)
+		}
+		createdBy = &admin.ID
+	}
+
+	if opts.SchoolID != nil {
+		exists, err := s.db.NewSelect().Model((*model.Schools)(nil)).Where("id = ?", *opts.SchoolID).Exists(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve school: %w", err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("school not found")
+		}
+	}
+
+	return s.importCalendar(ctx, file, opts, *createdBy)
+}
+
+// importCalendar parses, plans and (unless it is a dry run) applies an import
+func (s *CalendarService) importCalendar(ctx context.Context, file io.Reader, opts CalendarImportOptions, userID uuid.UUID) (*CalendarImportReport, error) {
+	switch opts.DefaultEventType {
+	case "":
+		opts.DefaultEventType = "other"
+	case "session", "assignment_due", "exam", "holiday", "meeting", "other":
+	default:
+		return nil, fmt.Errorf("invalid default event type")
+	}
+
+	parsed, err := ical.Parse(file, schoolLocation)
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("iCalendar file has no events")
+	}
+	if len(parsed) > MaxCalendarImportEvents {
+		return nil, fmt.Errorf("iCalendar file exceeds %d events", MaxCalendarImportEvents)
+	}
+
+	report := &CalendarImportReport{
+		DryRun:      opts.DryRun,
+		Source:      opts.Source,
+		SchoolID:    opts.SchoolID,
+		TotalEvents: len(parsed),
+	}
+	seen := make(map[string]bool)
+	for _, item := range parsed {
+		row := buildCalendarImportEvent(item, opts, userID)
+		if row.UID != "" {
+			if seen[row.UID] {
+				row.Action = "invalid"
+				row.Errors = append(row.Errors, "duplicate UID in file")
+			}
+			seen[row.UID] = true
+		}
+		report.Events = append(report.Events, row)
+	}
+
+	if err := s.planCalendarImport(ctx, report); err != nil {
+		return nil, err
+	}
+
+	if report.Invalid > 0 || opts.DryRun {
+		return report, nil
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		for _, row := range report.Events {
+			switch row.Action {
+			case "create":
+				if _, err := tx.NewInsert().Model(row.event).Exec(ctx); err != nil {
+					return fmt.Errorf("event %s: failed to create calendar event: %w", row.UID, err)
+				}
+			case "update":
+				_, err := tx.NewUpdate().
+					Model(row.event).
+					Column("title", "description", "event_type", "start_date", "end_date", "is_recurring", "recurrence_pattern", "affects_attendance", "revision", "updated_at").
+					WherePK().
+					Exec(ctx)
+				if err != nil {
+					return fmt.Errorf("event %s: failed to update calendar event: %w", row.UID, err)
+				}
+			case "remove":
+				if _, err := tx.NewDelete().Model(row.event).WherePK().Exec(ctx); err != nil {
+					return fmt.Errorf("event %s: failed to delete calendar event: %w", row.UID, err)
+				}
+			}
+		}
+
+		summary := map[string]any{
+			"source":    report.Source,
+			"school_id": report.SchoolID,
+			"created":   report.Created,
+			"updated":   report.Updated,
+			"removed":   report.Removed,
+		}
+		return writeAuditLog(ctx, tx, &userID, "academic_calendar.import", "academic_calendar", nil, nil, nil, summary)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.Committed = true
+	return report, nil
+}
+
+// buildCalendarImportEvent converts a parsed VEVENT into a calendar event and validates it
+func buildCalendarImportEvent(item ical.Event, opts CalendarImportOptions, userID uuid.UUID) *CalendarImportEvent {
+	row := &CalendarImportEvent{
+		UID:       item.UID,
+		Title:     strings.TrimSpace(item.Summary),
+		EventType: calendarImportEventType(item.Categories, opts.DefaultEventType),
+		Action:    "create",
+	}
+	if row.UID == "" {
+		row.Errors = append(row.Errors, "missing UID")
+	}
+	if row.Title == "" {
+		row.Errors = append(row.Errors, "missing SUMMARY")
+	} else if len([]rune(row.Title)) > 200 {
+		row.Errors = append(row.Errors, "SUMMARY is longer than 200 characters")
+	}
+
+	// Calendar events are whole days; timed events take the school-local dates they cover, and iCalendar
+	// end dates are exclusive
+	startDate := importDate(item.Start, item.AllDay)
+	endDate := importDate(item.End, item.AllDay)
+	if item.AllDay || (endDate.After(startDate) && item.End.In(schoolLocation).Hour() == 0 && item.End.In(schoolLocation).Minute() == 0) {
+		endDate = endDate.AddDate(0, 0, -1)
+	}
+	if endDate.Before(startDate) {
+		endDate = startDate
+	}
+	row.StartDate = startDate.Format("2006-01-02")
+
+	now := time.Now()
+	event := &model.AcademicCalendar{
+		ID:                uuid.New(),
+		SchoolID:          opts.SchoolID,
+		Title:             row.Title,
+		EventType:         row.EventType,
+		StartDate:         startDate,
+		AffectsAttendance: row.EventType == "holiday",
+		ExternalUID:       &row.UID,
+		CreatedBy:         userID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	if description := strings.TrimSpace(item.Description); description != "" {
+		event.Description = &description
+	}
+	if !endDate.Equal(startDate) {
+		event.EndDate = &endDate
+		value := endDate.Format("2006-01-02")
+		row.EndDate = &value
+	}
+
+	if item.RRule != "" {
+		rrule := item.RRule
+		row.RRule = &rrule
+		exDates := make([]string, 0, len(item.ExDates))
+		for _, exDate := range item.ExDates {
+			exDates = append(exDates, importDate(exDate, item.AllDay).Format("2006-01-02"))
+		}
+		if err := applyEventRecurrence(event, &rrule, exDates); err != nil {
+			row.Errors = append(row.Errors, err.Error())
+		}
+	}
+
+	if len(row.Errors) > 0 {
+		row.Action = "invalid"
+	} else if item.Status == "CANCELLED" {
+		row.Action = "remove"
+	}
+	row.event = event
+	return row
+}
+
+// planCalendarImport matches rows to earlier imports by UID and decides what each row does
+func (s *CalendarService) planCalendarImport(ctx context.Context, report *CalendarImportReport) error {
+	uids := make([]string, 0, len(report.Events))
+	for _, row := range report.Events {
+		if row.UID != "" {
+			uids = append(uids, row.UID)
+		}
+	}
+
+	existing := make(map[string]*model.AcademicCalendar)
+	if len(uids) > 0 {
+		var events []*model.AcademicCalendar
+		query := s.db.NewSelect().
+			Model(&events).
+			WhereAllWithDeleted().
+			Where("ac.external_uid IN (?)", bun.In(uids))
+		if report.SchoolID != nil {
+			query = query.Where("ac.school_id = ?", *report.SchoolID)
+		} else {
+			query = query.Where("ac.school_id IS NULL")
+		}
+		if err := query.Scan(ctx); err != nil {
+			return fmt.Errorf("failed to retrieve imported events: %w", err)
+		}
+		for _, event := range events {
+			existing[*event.ExternalUID] = event
+		}
+	}
+
+	for _, row := range report.Events {
+		if row.Action == "invalid" {
+			report.Invalid++
+			continue
+		}
+
+		current, found := existing[row.UID]
+		switch {
+		case found && current.DeletedAt != nil:
+			// Someone deleted the imported event on purpose; re-importing must not bring it back
+			row.Action = "skip"
+			row.Note = "event was deleted after an earlier import"
+			row.EventID = &current.ID
+		case row.Action == "remove" && !found:
+			row.Action = "skip"
+			row.Note = "cancelled event was never imported"
+		case row.Action == "remove":
+			row.EventID = &current.ID
+			row.event = current
+		case found && calendarEventUnchanged(current, row.event):
+			row.Action = "unchanged"
+			row.EventID = &current.ID
+		case found:
+			row.Action = "update"
+			row.EventID = &current.ID
+			row.event.ID = current.ID
+			row.event.CreatedBy = current.CreatedBy
+			row.event.CreatedAt = current.CreatedAt
+			row.event.Revision = current.Revision + 1
+		default:
+			row.EventID = &row.event.ID
+		}
+
+		switch row.Action {
+		case "create":
+			report.Created++
+		case "update":
+			report.Updated++
+		case "unchanged":
+			report.Unchanged++
+		case "remove":
+			report.Removed++
+		case "skip":
+			report.Skipped++
+		}
+	}
+	return nil
+}
+
+// calendarEventUnchanged reports whether an import would leave an existing event as it is
+func calendarEventUnchanged(current, imported *model.AcademicCalendar) bool {
+	sameDate := func(a, b *time.Time) bool {
+		if a == nil || b == nil {
+			return a == nil && b == nil
+		}
+		return a.Format("2006-01-02") == b.Format("2006-01-02")
+	}
+	sameString := func(a, b *string) bool {
+		if a == nil || b == nil {
+			return a == nil && b == nil
+		}
+		return *a == *b
+	}
+
+	return current.Title == imported.Title &&
+		sameString(current.Description, imported.Description) &&
+		current.EventType == imported.EventType &&
+		sameDate(&current.StartDate, &imported.StartDate) &&
+		sameDate(current.EndDate, imported.EndDate) &&
+		current.IsRecurring == imported.IsRecurring &&
+		sameRecurrence(current.RecurrencePattern, imported.RecurrencePattern) &&
+		current.AffectsAttendance == imported.AffectsAttendance
+}
+
+// sameRecurrence compares stored recurrence patterns by their rule, since jsonb does not keep key order
+func sameRecurrence(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	ruleA := eventRule(&model.AcademicCalendar{IsRecurring: true, RecurrencePattern: a})
+	ruleB := eventRule(&model.AcademicCalendar{IsRecurring: true, RecurrencePattern: b})
+	if ruleA == nil || ruleB == nil {
+		return *a == *b
+	}
+	return ruleA.String() == ruleB.String() && strings.Join(ruleA.ExDates, ",") == strings.Join(ruleB.ExDates, ",")
+}
+
+// calendarImportEventType picks the event type of the first category that maps to one
+func calendarImportEventType(categories []string, fallback string) string {
+	for _, category := range categories {
+		if eventType, ok := calendarImportCategories[strings.ToLower(strings.TrimSpace(category))]; ok {
+			return eventType
+		}
+	}
+	return fallback
+}
+
+// importDate returns the school-local calendar date of an iCalendar value as UTC midnight
+func importDate(t time.Time, allDay bool) time.Time {
+	if !allDay {
+		t = t.In(schoolLocation)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
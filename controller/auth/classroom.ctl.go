@@ -3,6 +3,7 @@ package auth
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -10,6 +11,9 @@ import (
 	"github.com/komkem01/easy-attend-service/response"
 )
 
+// maxRosterImportBytes limits the size of an uploaded roster CSV
+const maxRosterImportBytes = 2 << 20
+
 type ClassroomController struct {
 	classroomService *ClassroomService
 }
@@ -61,7 +65,11 @@ func (ctrl *ClassroomController) CreateClassroom(c *gin.Context) {
 
 	classroom, err := ctrl.classroomService.CreateClassroomService(c.Request.Context(), &req, teacherUUID)
 	if err != nil {
-		response.InternalServerError(c, "Failed to create classroom: "+err.Error())
+		if isClassroomTermError(err) {
+			response.BadRequest(c, err.Error())
+		} else {
+			response.InternalServerError(c, "Failed to create classroom: "+err.Error())
+		}
 		return
 	}
 
@@ -69,6 +77,7 @@ func (ctrl *ClassroomController) CreateClassroom(c *gin.Context) {
 		"id":         classroom.ID,
 		"name":       classroom.Name,
 		"subject":    classroom.Subject,
+		"term_id":    classroom.TermID,
 		"created_at": classroom.CreatedAt,
 	})
 }
@@ -118,6 +127,18 @@ func (ctrl *ClassroomController) GetClassrooms(c *gin.Context) {
 		}
 	}
 
+	// term_id=all lists every term; by default each school's current term, or its latest between terms, is shown
+	if termIDStr := c.Query("term_id"); termIDStr == "all" {
+		req.AllTerms = true
+	} else if termIDStr != "" {
+		termID, err := uuid.Parse(termIDStr)
+		if err != nil {
+			response.BadRequest(c, "Invalid term ID format")
+			return
+		}
+		req.TermID = &termID
+	}
+
 	classrooms, total, err := ctrl.classroomService.GetClassroomsService(c.Request.Context(), &req)
 	if err != nil {
 		response.InternalServerError(c, "Failed to fetch classrooms: "+err.Error())
@@ -196,6 +217,8 @@ func (ctrl *ClassroomController) UpdateClassroom(c *gin.Context) {
 			response.NotFound(c, "Classroom not found")
 		} else if err.Error() == "access denied: you can only update your own classrooms" {
 			response.Forbidden(c, "You can only update your own classrooms")
+		} else if isClassroomTermError(err) {
+			response.BadRequest(c, err.Error())
 		} else {
 			response.InternalServerError(c, "Failed to update classroom: "+err.Error())
 		}
@@ -244,3 +267,863 @@ func (ctrl *ClassroomController) DeleteClassroom(c *gin.Context) {
 
 	c.JSON(200, gin.H{"success": true, "message": "Classroom deleted successfully"})
 }
+
+// JoinClassroom enrolls the current student using a classroom code
+func (ctrl *ClassroomController) JoinClassroom(c *gin.Context) {
+	var req requests.JoinClassroomRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	studentUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	result, err := ctrl.classroomService.JoinClassroomService(c.Request.Context(), req.ClassroomCode, studentUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to join classroom")
+		return
+	}
+
+	if result.Status != "enrolled" {
+		response.Accepted(c, result)
+		return
+	}
+
+	response.Created(c, result)
+}
+
+// GetJoinRequests lists pending join requests for a classroom
+func (ctrl *ClassroomController) GetJoinRequests(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	members, err := ctrl.classroomService.GetJoinRequestsService(c.Request.Context(), id, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to retrieve join requests")
+		return
+	}
+
+	response.Success(c, members)
+}
+
+// ReviewJoinRequest accepts or rejects a pending join request
+func (ctrl *ClassroomController) ReviewJoinRequest(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	memberID, err := uuid.Parse(c.Param("member_id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid join request ID format")
+		return
+	}
+
+	var req requests.ReviewJoinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	member, err := ctrl.classroomService.ReviewJoinRequestService(c.Request.Context(), id, memberID, req.Status, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to review join request")
+		return
+	}
+
+	response.Success(c, member)
+}
+
+// GetRoster lists the students in a classroom
+func (ctrl *ClassroomController) GetRoster(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	req := requests.RosterQueryRequest{
+		Page:   1,
+		Limit:  20,
+		Sort:   c.Query("sort"),
+		Order:  c.Query("order"),
+		Status: c.Query("status"),
+	}
+	if page, err := strconv.Atoi(c.Query("page")); err == nil && page > 0 {
+		req.Page = page
+	}
+	if limit, err := strconv.Atoi(c.Query("limit")); err == nil && limit > 0 && limit <= 100 {
+		req.Limit = limit
+	}
+	if search := c.Query("search"); search != "" {
+		req.Search = &search
+	}
+
+	switch {
+	case req.Sort != "" && req.Sort != "student_number" && req.Sort != "name" && req.Sort != "enrolled_at":
+		response.BadRequest(c, "Invalid sort, expected student_number, name or enrolled_at")
+		return
+	case req.Order != "" && req.Order != "asc" && req.Order != "desc":
+		response.BadRequest(c, "Invalid order, expected asc or desc")
+		return
+	case req.Status != "" && req.Status != "active" && req.Status != "inactive" && req.Status != "all":
+		response.BadRequest(c, "Invalid status, expected active, inactive or all")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	students, total, err := ctrl.classroomService.GetRosterService(c.Request.Context(), id, &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to fetch classroom students")
+		return
+	}
+
+	totalPages := int((total + int64(req.Limit) - 1) / int64(req.Limit))
+
+	response.Success(c, map[string]interface{}{
+		"students": students,
+		"pagination": map[string]interface{}{
+			"current_page": req.Page,
+			"per_page":     req.Limit,
+			"total":        total,
+			"total_pages":  totalPages,
+			"has_next":     req.Page < totalPages,
+			"has_prev":     req.Page > 1,
+		},
+	})
+}
+
+// AddRosterStudent adds a student to a classroom
+func (ctrl *ClassroomController) AddRosterStudent(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	var req requests.AddRosterStudentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	enrollment, err := ctrl.classroomService.AddRosterStudentService(c.Request.Context(), id, &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to add student")
+		return
+	}
+
+	response.Created(c, enrollment)
+}
+
+// UpdateRosterStudent edits a student's roster entry
+func (ctrl *ClassroomController) UpdateRosterStudent(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	studentID, err := uuid.Parse(c.Param("student_id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid student ID format")
+		return
+	}
+
+	var req requests.UpdateRosterStudentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	enrollment, err := ctrl.classroomService.UpdateRosterStudentService(c.Request.Context(), id, studentID, &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to update student")
+		return
+	}
+
+	response.Success(c, enrollment)
+}
+
+// RemoveRosterStudent deactivates a student's enrollment
+func (ctrl *ClassroomController) RemoveRosterStudent(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	studentID, err := uuid.Parse(c.Param("student_id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid student ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	err = ctrl.classroomService.RemoveRosterStudentService(c.Request.Context(), id, studentID, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to remove student")
+		return
+	}
+
+	c.JSON(200, gin.H{"success": true, "message": "Student removed from classroom"})
+}
+
+// ImportRoster enrolls students from an uploaded CSV; dry_run=true only reports what would happen
+func (ctrl *ClassroomController) ImportRoster(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	dryRun := false
+	if value := c.DefaultQuery("dry_run", c.PostForm("dry_run")); value != "" {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			response.BadRequest(c, "Invalid dry_run value")
+			return
+		}
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.BadRequest(c, "CSV file is required in the 'file' field")
+		return
+	}
+	if fileHeader.Size > maxRosterImportBytes {
+		response.BadRequest(c, "CSV file is too large")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.BadRequest(c, "Failed to read CSV file")
+		return
+	}
+	defer file.Close()
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	report, err := ctrl.classroomService.ImportRosterService(c.Request.Context(), id, file, dryRun, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "CSV ") || strings.HasPrefix(err.Error(), "invalid CSV") {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		respondEnrollmentError(c, err, "Failed to import roster")
+		return
+	}
+
+	switch {
+	case report.DryRun:
+		response.Success(c, report)
+	case !report.Committed:
+		response.UnprocessableEntity(c, "Roster import has invalid rows; nothing was imported", report)
+	default:
+		response.Created(c, report)
+	}
+}
+
+// GetWaitlist lists the students waiting for a seat in a classroom
+func (ctrl *ClassroomController) GetWaitlist(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	entries, err := ctrl.classroomService.GetWaitlistService(c.Request.Context(), id, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to retrieve waitlist")
+		return
+	}
+
+	response.Success(c, entries)
+}
+
+// ReorderWaitlist sets the order in which waiting students get seats
+func (ctrl *ClassroomController) ReorderWaitlist(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	var req requests.ReorderWaitlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	entries, err := ctrl.classroomService.ReorderWaitlistService(c.Request.Context(), id, req.StudentIDs, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to reorder waitlist")
+		return
+	}
+
+	response.Success(c, entries)
+}
+
+// RemoveFromWaitlist takes a student off the waitlist
+func (ctrl *ClassroomController) RemoveFromWaitlist(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	studentID, err := uuid.Parse(c.Param("student_id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid student ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	err = ctrl.classroomService.RemoveFromWaitlistService(c.Request.Context(), id, studentID, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to remove student from waitlist")
+		return
+	}
+
+	c.JSON(200, gin.H{"success": true, "message": "Student removed from waitlist"})
+}
+
+// ClearWaitlist removes every student from a classroom's waitlist
+func (ctrl *ClassroomController) ClearWaitlist(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	removed, err := ctrl.classroomService.ClearWaitlistService(c.Request.Context(), id, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to clear waitlist")
+		return
+	}
+
+	response.Success(c, gin.H{"removed": removed})
+}
+
+// RotateClassroomCode issues a new join code for a classroom
+func (ctrl *ClassroomController) RotateClassroomCode(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	classroom, err := ctrl.classroomService.RotateClassroomCodeService(c.Request.Context(), id, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to rotate classroom code")
+		return
+	}
+
+	response.Success(c, classroom)
+}
+
+// CreateInvite creates an invite link for a classroom
+func (ctrl *ClassroomController) CreateInvite(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	var req requests.CreateInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	inv, err := ctrl.classroomService.CreateInviteService(c.Request.Context(), id, &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to create invite link")
+		return
+	}
+
+	response.Created(c, inv)
+}
+
+// GetInvites lists a classroom's invite links
+func (ctrl *ClassroomController) GetInvites(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	invites, err := ctrl.classroomService.GetInvitesService(c.Request.Context(), id, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to retrieve invite links")
+		return
+	}
+
+	response.Success(c, invites)
+}
+
+// GetInviteUses lists who has used an invite link
+func (ctrl *ClassroomController) GetInviteUses(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	inviteID, err := uuid.Parse(c.Param("invite_id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid invite ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	uses, err := ctrl.classroomService.GetInviteUsesService(c.Request.Context(), id, inviteID, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to retrieve invite link usage")
+		return
+	}
+
+	response.Success(c, uses)
+}
+
+// RevokeInvite disables an invite link
+func (ctrl *ClassroomController) RevokeInvite(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	inviteID, err := uuid.Parse(c.Param("invite_id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid invite ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	inv, err := ctrl.classroomService.RevokeInviteService(c.Request.Context(), id, inviteID, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to revoke invite link")
+		return
+	}
+
+	response.Success(c, inv)
+}
+
+// AcceptInvite redeems an invite link for the current user
+func (ctrl *ClassroomController) AcceptInvite(c *gin.Context) {
+	var req requests.AcceptInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	result, err := ctrl.classroomService.AcceptInviteService(c.Request.Context(), req.Token, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to accept invite link")
+		return
+	}
+
+	if result.Status == "waitlisted" {
+		response.Accepted(c, result)
+		return
+	}
+
+	response.Created(c, result)
+}
+
+// RolloverClassrooms clones classrooms into a new term; dry_run previews the copies
+func (ctrl *ClassroomController) RolloverClassrooms(c *gin.Context) {
+	var req requests.RolloverClassroomsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	report, err := ctrl.classroomService.RolloverClassroomsService(c.Request.Context(), &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		switch err.Error() {
+		case "target term not found":
+			response.NotFound(c, err.Error())
+		case "term is archived":
+			response.BadRequest(c, err.Error())
+		case "classroom was already rolled over into the target term":
+			response.Conflict(c, err.Error())
+		default:
+			respondEnrollmentError(c, err, "Failed to roll over classrooms")
+		}
+		return
+	}
+
+	if !report.Committed {
+		response.Success(c, report)
+		return
+	}
+
+	response.Created(c, report)
+}
+
+// GetSeatingPlan returns a classroom's seat map
+func (ctrl *ClassroomController) GetSeatingPlan(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	plan, err := ctrl.classroomService.GetSeatingPlanService(c.Request.Context(), id, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to retrieve seating plan")
+		return
+	}
+
+	response.Success(c, plan)
+}
+
+// SetSeatingLayout creates or replaces a classroom's grid of seats
+func (ctrl *ClassroomController) SetSeatingLayout(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	var req requests.SeatingLayoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	plan, err := ctrl.classroomService.SetSeatingLayoutService(c.Request.Context(), id, &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to save seating plan")
+		return
+	}
+
+	response.Success(c, plan)
+}
+
+// AssignSeats places students in seats
+func (ctrl *ClassroomController) AssignSeats(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	var req requests.AssignSeatsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	plan, err := ctrl.classroomService.AssignSeatsService(c.Request.Context(), id, &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to assign seats")
+		return
+	}
+
+	response.Success(c, plan)
+}
+
+// ArrangeSeats seats every student by student number or at random
+func (ctrl *ClassroomController) ArrangeSeats(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	var req requests.ArrangeSeatsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	plan, err := ctrl.classroomService.ArrangeSeatsService(c.Request.Context(), id, &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondEnrollmentError(c, err, "Failed to arrange seats")
+		return
+	}
+
+	response.Success(c, plan)
+}
+
+// GetTransfers lists classroom transfers visible to the current user; ?status filters them
+func (ctrl *ClassroomController) GetTransfers(c *gin.Context) {
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	transfers, err := ctrl.classroomService.GetTransfersService(c.Request.Context(), userUUID, GetUserRoleFromContext(c), c.Query("status"))
+	if err != nil {
+		respondTransferError(c, err, "Failed to retrieve transfers")
+		return
+	}
+
+	response.Success(c, transfers)
+}
+
+// RequestTransfer files a classroom transfer; admins' transfers take effect immediately
+func (ctrl *ClassroomController) RequestTransfer(c *gin.Context) {
+	var req requests.TransferClassroomsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	transfer, err := ctrl.classroomService.RequestTransferService(c.Request.Context(), &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondTransferError(c, err, "Failed to transfer classrooms")
+		return
+	}
+
+	if transfer.Status == "pending" {
+		response.Accepted(c, transfer)
+		return
+	}
+
+	response.Created(c, transfer)
+}
+
+// ReviewTransfer approves or rejects a pending transfer
+func (ctrl *ClassroomController) ReviewTransfer(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid transfer ID format")
+		return
+	}
+
+	var req requests.ReviewTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	transfer, err := ctrl.classroomService.ReviewTransferService(c.Request.Context(), id, &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondTransferError(c, err, "Failed to review transfer")
+		return
+	}
+
+	response.Success(c, transfer)
+}
+
+// CancelTransfer withdraws a pending transfer
+func (ctrl *ClassroomController) CancelTransfer(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid transfer ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	transfer, err := ctrl.classroomService.CancelTransferService(c.Request.Context(), id, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondTransferError(c, err, "Failed to cancel transfer")
+		return
+	}
+
+	response.Success(c, transfer)
+}
+
+// respondEnrollmentError maps enrollment service errors to HTTP responses
+func respondEnrollmentError(c *gin.Context, err error, fallback string) {
+	switch err.Error() {
+	case "classroom not found", "join request not found", "student not found", "student is not in this classroom",
+		"student is not on the waitlist", "invite link not found", "seating plan not found":
+		response.NotFound(c, err.Error())
+	case "only students can join classrooms", "unauthorized to manage this classroom",
+		"this invite link is for students", "this invite link is for teachers":
+		response.Forbidden(c, err.Error())
+	case "already enrolled in this classroom", "join request is already pending", "classroom is full",
+		"classroom is not accepting students", "student number is already in use", "student is already inactive",
+		"already on the waitlist", "already a member of this classroom", "invite link has been revoked",
+		"invite link has expired", "invite link has reached its usage limit", "seat is already taken", "not enough seats":
+		response.Conflict(c, err.Error())
+	case "user is not a student", "no data to update", "student list must match the waitlist", "invalid invite link",
+		"invalid seat", "seat is unavailable", "student is listed more than once":
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback+": "+err.Error())
+	}
+}
+
+// isClassroomTermError reports whether err rejects the term given for a classroom
+func isClassroomTermError(err error) bool {
+	switch err.Error() {
+	case "term not found", "term belongs to another school", "term is archived":
+		return true
+	}
+	return false
+}
+
+// respondTransferError maps classroom transfer errors to HTTP responses
+func respondTransferError(c *gin.Context, err error, fallback string) {
+	switch err.Error() {
+	case "transfer not found", "teacher not found", "classroom not found":
+		response.NotFound(c, err.Error())
+	case "unauthorized to transfer classrooms", "only school admins can review transfers":
+		response.Forbidden(c, err.Error())
+	case "a transfer is already pending", "transfer has already been reviewed":
+		response.Conflict(c, err.Error())
+	case "cannot transfer to the same teacher", "target user is not an active teacher", "teachers belong to different schools",
+		"classroom does not belong to this teacher", "teacher has no classrooms to transfer", "invalid transfer status":
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback+": "+err.Error())
+	}
+}
@@ -38,22 +38,34 @@ func (s *ClassroomService) CreateClassroomService(ctx context.Context, req *requ
 		maxStudents = *req.MaxStudents
 	}
 
+	requireApproval := false
+	if req.RequireApproval != nil {
+		requireApproval = *req.RequireApproval
+	}
+
+	termID, err := classroomTerm(ctx, s.db, req.SchoolID, req.TermID)
+	if err != nil {
+		return nil, err
+	}
+
 	classroom := &model.Classrooms{
-		ID:            uuid.New(),
-		SchoolID:      req.SchoolID,
-		Name:          req.Name,
-		Subject:       req.Subject,
-		Description:   req.Description,
-		GradeLevel:    req.GradeLevel,
-		Section:       req.Section,
-		RoomNumber:    req.RoomNumber,
-		TeacherID:     teacherID,
-		ClassroomCode: classroomCode,
-		MaxStudents:   maxStudents,
-		Schedule:      req.Schedule,
-		IsActive:      true,
-		CreatedAt:     time.Now(),
-		UpdatedAt:     time.Now(),
+		ID:              uuid.New(),
+		SchoolID:        req.SchoolID,
+		TermID:          termID,
+		Name:            req.Name,
+		Subject:         req.Subject,
+		Description:     req.Description,
+		GradeLevel:      req.GradeLevel,
+		Section:         req.Section,
+		RoomNumber:      req.RoomNumber,
+		TeacherID:       teacherID,
+		ClassroomCode:   classroomCode,
+		MaxStudents:     maxStudents,
+		Schedule:        req.Schedule,
+		IsActive:        true,
+		RequireApproval: requireApproval,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 
 	_, err = s.db.NewInsert().Model(classroom).Exec(ctx)
@@ -110,6 +122,15 @@ func (s *ClassroomService) GetClassroomsService(ctx context.Context, req *reques
 		query = query.Where("c.is_active = ?", *req.IsActive)
 	}
 
+	// Without an explicit term, show classrooms of each school's current (or latest) term and those not
+	// assigned to any term
+	switch {
+	case req.TermID != nil:
+		query = query.Where("c.term_id = ?", *req.TermID)
+	case !req.AllTerms:
+		query = query.Where("c.term_id IS NULL OR c.term_id IN (?)", defaultTermIDs(s.db))
+	}
+
 	// Count total records
 	total, err := query.Count(ctx)
 	if err != nil {
@@ -211,6 +232,17 @@ func (s *ClassroomService) UpdateClassroomService(ctx context.Context, id uuid.U
 	if req.SchoolID != nil {
 		updateData["school_id"] = *req.SchoolID
 	}
+	if req.TermID != nil {
+		schoolID := classroom.SchoolID
+		if req.SchoolID != nil {
+			schoolID = req.SchoolID
+		}
+		termID, err := classroomTerm(ctx, s.db, schoolID, req.TermID)
+		if err != nil {
+			return nil, err
+		}
+		updateData["term_id"] = *termID
+	}
 	if req.Name != nil {
 		updateData["name"] = *req.Name
 	}
@@ -238,6 +270,9 @@ func (s *ClassroomService) UpdateClassroomService(ctx context.Context, id uuid.U
 	if req.IsActive != nil {
 		updateData["is_active"] = *req.IsActive
 	}
+	if req.RequireApproval != nil {
+		updateData["require_approval"] = *req.RequireApproval
+	}
 
 	if len(updateData) == 0 {
 		return nil, fmt.Errorf("no data to update")
@@ -256,6 +291,16 @@ func (s *ClassroomService) UpdateClassroomService(ctx context.Context, id uuid.U
 		return nil, fmt.Errorf("failed to update classroom: %w", err)
 	}
 
+	// More seats or a reactivated classroom can admit students from the waitlist
+	if req.MaxStudents != nil || req.IsActive != nil {
+		err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			return promoteFromWaitlist(ctx, tx, id)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to admit students from waitlist: %w", err)
+		}
+	}
+
 	// Retrieve updated classroom with relationships
 	err = s.db.NewSelect().
 		Model(&classroom).
@@ -0,0 +1,349 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/uptrace/bun"
+)
+
+// canManageClassroom reports whether the user teaches the classroom or administers its school
+func canManageClassroom(ctx context.Context, db bun.IDB, classroom *model.Classrooms, userID uuid.UUID, role string) (bool, error) {
+	if classroom.TeacherID == userID {
+		return true, nil
+	}
+	return isAdminOfSchool(ctx, db, classroom.SchoolID, userID, role)
+}
+
+// isClassroomStaff reports whether the user is an active teacher or assistant member of the classroom.
+// Creators of sessions and assignments keep their rights through it only while they still teach there,
+// so a teacher whose classrooms were transferred away loses them.
+func isClassroomStaff(ctx context.Context, db bun.IDB, classroomID uuid.UUID, userID uuid.UUID) (bool, error) {
+	member, err := db.NewSelect().
+		Model((*model.ClassroomMembers)(nil)).
+		Where("classroom_id = ? AND user_id = ? AND status = 'active'", classroomID, userID).
+		Where("role IN ('teacher', 'assistant')").
+		Exists(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to check classroom membership: %w", err)
+	}
+	return member, nil
+}
+
+// isAdminOfSchool reports whether the user is a super admin or an admin of the given school; admins of
+// other schools, and any admin when the school is unknown, are refused
+func isAdminOfSchool(ctx context.Context, db bun.IDB, schoolID *uuid.UUID, userID uuid.UUID, role string) (bool, error) {
+	switch role {
+	case "super_admin":
+		return true, nil
+	case "admin":
+		userSchool, err := userSchoolID(ctx, db, userID)
+		if err != nil {
+			return false, err
+		}
+		return userSchool != nil && schoolID != nil && *userSchool == *schoolID, nil
+	default:
+		return false, nil
+	}
+}
+
+// JoinClassroomResult describes what happened when a student used a classroom code
+type JoinClassroomResult struct {
+	Status     string                   `json:"status"` // enrolled, pending, waitlisted or joined (non-student members)
+	Enrollment *model.ClassroomStudents `json:"enrollment,omitempty"`
+	Membership *model.ClassroomMembers  `json:"membership,omitempty"`
+	Waitlist   *model.ClassroomWaitlist `json:"waitlist,omitempty"`
+}
+
+// JoinClassroomService enrolls a student using a classroom code. Classrooms in approval mode get a
+// pending membership instead, which the teacher accepts or rejects, and full classrooms queue the
+// student on the waitlist.
+func (s *ClassroomService) JoinClassroomService(ctx context.Context, code string, studentID uuid.UUID, role string) (*JoinClassroomResult, error) {
+	if role != "student" {
+		return nil, fmt.Errorf("only students can join classrooms")
+	}
+
+	classroom, err := s.GetClassroomByCodeService(ctx, strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, err
+	}
+
+	if !classroom.IsActive {
+		return nil, fmt.Errorf("classroom is not accepting students")
+	}
+
+	enrolled, err := s.db.NewSelect().
+		Model((*model.ClassroomStudents)(nil)).
+		Where("classroom_id = ? AND student_id = ? AND is_active = true", classroom.ID, studentID).
+		Exists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check enrollment: %w", err)
+	}
+	if enrolled {
+		return nil, fmt.Errorf("already enrolled in this classroom")
+	}
+
+	pending, err := s.db.NewSelect().
+		Model((*model.ClassroomMembers)(nil)).
+		Where("classroom_id = ? AND user_id = ? AND status = 'pending'", classroom.ID, studentID).
+		Exists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check join requests: %w", err)
+	}
+	if pending {
+		return nil, fmt.Errorf("join request is already pending")
+	}
+
+	if classroom.RequireApproval {
+		var member *model.ClassroomMembers
+		err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			var err error
+			member, err = createJoinRequest(ctx, tx, classroom, studentID)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &JoinClassroomResult{Status: "pending", Membership: member}, nil
+	}
+
+	queued, err := hasWaitlist(ctx, s.db, classroom.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var enrollment *model.ClassroomStudents
+	if !queued {
+		err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			var err error
+			enrollment, err = enrollStudent(ctx, tx, classroom.ID, studentID)
+			return err
+		})
+	}
+	if queued || (err != nil && err.Error() == "classroom is full") {
+		entry, err := s.joinWaitlist(ctx, classroom, studentID)
+		if err != nil {
+			return nil, err
+		}
+		return &JoinClassroomResult{Status: "waitlisted", Waitlist: entry}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &JoinClassroomResult{Status: "enrolled", Enrollment: enrollment}, nil
+}
+
+// GetJoinRequestsService lists pending join requests for a classroom
+func (s *ClassroomService) GetJoinRequestsService(ctx context.Context, classroomID uuid.UUID, userID uuid.UUID, role string) ([]*model.ClassroomMembers, error) {
+	classroom, err := s.getManagedClassroom(ctx, classroomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []*model.ClassroomMembers
+	err = s.db.NewSelect().
+		Model(&members).
+		Relation("User").
+		Where("cm.classroom_id = ? AND cm.status = 'pending'", classroom.ID).
+		Order("cm.created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve join requests: %w", err)
+	}
+
+	return members, nil
+}
+
+// ReviewJoinRequestService accepts or rejects a pending join request; accepting enrolls the student
+func (s *ClassroomService) ReviewJoinRequestService(ctx context.Context, classroomID uuid.UUID, memberID uuid.UUID, status string, userID uuid.UUID, role string) (*model.ClassroomMembers, error) {
+	classroom, err := s.getManagedClassroom(ctx, classroomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	var member model.ClassroomMembers
+	err = s.db.NewSelect().
+		Model(&member).
+		Where("cm.id = ? AND cm.classroom_id = ? AND cm.status = 'pending'", memberID, classroom.ID).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("join request not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve join request: %w", err)
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		now := time.Now()
+		query := tx.NewUpdate().
+			Model((*model.ClassroomMembers)(nil)).
+			Set("updated_at = ?", now).
+			Where("id = ?", member.ID)
+
+		title := "Join request rejected"
+		message := fmt.Sprintf("Your request to join %s was rejected.", classroom.Name)
+		if status == "approved" {
+			if _, err := enrollStudent(ctx, tx, classroom.ID, member.UserID); err != nil {
+				return err
+			}
+			query = query.Set("status = 'active'").Set("joined_at = ?", now)
+			title = "Join request approved"
+			message = fmt.Sprintf("You have been enrolled in %s.", classroom.Name)
+		} else {
+			query = query.Set("status = 'removed'").Set("left_at = ?", now)
+		}
+
+		if _, err := query.Exec(ctx); err != nil {
+			return fmt.Errorf("failed to update join request: %w", err)
+		}
+
+		return notifyUsers(ctx, tx, []uuid.UUID{member.UserID}, "info", title, message, "classroom", &classroom.ID, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.NewSelect().
+		Model(&member).
+		Relation("User").
+		Where("cm.id = ?", member.ID).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load join request: %w", err)
+	}
+
+	return &member, nil
+}
+
+// createJoinRequest records a pending membership for the teacher to accept or reject and notifies them
+func createJoinRequest(ctx context.Context, db bun.IDB, classroom *model.Classrooms, studentID uuid.UUID) (*model.ClassroomMembers, error) {
+	now := time.Now()
+	member := &model.ClassroomMembers{
+		ID:          uuid.New(),
+		ClassroomID: classroom.ID,
+		UserID:      studentID,
+		Role:        "student",
+		Status:      "pending",
+		JoinedAt:    now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if _, err := db.NewInsert().Model(member).Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create join request: %w", err)
+	}
+
+	message := fmt.Sprintf("A student asked to join %s.", classroom.Name)
+	if err := notifyUsers(ctx, db, []uuid.UUID{classroom.TeacherID}, "info", "Join request", message,
+		"classroom", &classroom.ID, map[string]interface{}{"member_id": member.ID}); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// getManagedClassroom loads a classroom the user is allowed to manage
+func (s *ClassroomService) getManagedClassroom(ctx context.Context, classroomID uuid.UUID, userID uuid.UUID, role string) (*model.Classrooms, error) {
+	return loadManagedClassroom(ctx, s.db, classroomID, userID, role)
+}
+
+// loadManagedClassroom is getManagedClassroom for services that do not hold a ClassroomService
+func loadManagedClassroom(ctx context.Context, db bun.IDB, classroomID uuid.UUID, userID uuid.UUID, role string) (*model.Classrooms, error) {
+	var classroom model.Classrooms
+	err := db.NewSelect().
+		Model(&classroom).
+		Where("c.id = ? AND c.deleted_at IS NULL", classroomID).
+		Scan(ctx)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("classroom not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve classroom: %w", err)
+	}
+
+	allowed, err := canManageClassroom(ctx, db, &classroom, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("unauthorized to manage this classroom")
+	}
+
+	return &classroom, nil
+}
+
+// enrollStudent adds a student to a classroom, reactivating an earlier enrollment if there is one.
+// The classroom row is locked so concurrent joins cannot exceed MaxStudents.
+func enrollStudent(ctx context.Context, db bun.IDB, classroomID uuid.UUID, studentID uuid.UUID) (*model.ClassroomStudents, error) {
+	var classroom model.Classrooms
+	err := db.NewSelect().
+		Model(&classroom).
+		Where("c.id = ?", classroomID).
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("classroom not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve classroom: %w", err)
+	}
+
+	var enrollment model.ClassroomStudents
+	err = db.NewSelect().
+		Model(&enrollment).
+		Where("cs.classroom_id = ? AND cs.student_id = ?", classroomID, studentID).
+		Scan(ctx)
+	hasEnrollment := err == nil
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to check enrollment: %w", err)
+	}
+	if hasEnrollment && enrollment.IsActive {
+		return nil, fmt.Errorf("already enrolled in this classroom")
+	}
+
+	activeCount, err := db.NewSelect().
+		Model((*model.ClassroomStudents)(nil)).
+		Where("classroom_id = ? AND is_active = true", classroomID).
+		Count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count classroom students: %w", err)
+	}
+	if activeCount >= classroom.MaxStudents {
+		return nil, fmt.Errorf("classroom is full")
+	}
+
+	now := time.Now()
+	if hasEnrollment {
+		_, err := db.NewUpdate().
+			Model((*model.ClassroomStudents)(nil)).
+			Set("is_active = true").
+			Set("enrolled_at = ?", now).
+			Where("id = ?", enrollment.ID).
+			Exec(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to enroll student: %w", err)
+		}
+		enrollment.IsActive = true
+		enrollment.EnrolledAt = now
+		return &enrollment, nil
+	}
+
+	enrollment = model.ClassroomStudents{
+		ID:          uuid.New(),
+		ClassroomID: classroomID,
+		StudentID:   studentID,
+		EnrolledAt:  now,
+		IsActive:    true,
+		CreatedAt:   now,
+	}
+	if _, err := db.NewInsert().Model(&enrollment).Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to enroll student: %w", err)
+	}
+
+	return &enrollment, nil
+}
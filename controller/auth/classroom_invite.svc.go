@@ -0,0 +1,368 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/komkem01/easy-attend-service/utils/invite"
+	"github.com/uptrace/bun"
+)
+
+// RotateClassroomCodeService replaces a classroom's join code so a leaked code stops working
+func (s *ClassroomService) RotateClassroomCodeService(ctx context.Context, classroomID uuid.UUID, userID uuid.UUID, role string) (*model.Classrooms, error) {
+	classroom, err := s.getManagedClassroom(ctx, classroomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := s.generateClassroomCode(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate classroom code: %w", err)
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().
+			Model((*model.Classrooms)(nil)).
+			Set("classroom_code = ?", code).
+			Set("updated_at = ?", time.Now()).
+			Where("id = ?", classroom.ID).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to rotate classroom code: %w", err)
+		}
+
+		return writeAuditLog(ctx, tx, &userID, "classroom.rotate_code", "classrooms", &classroom.ID,
+			map[string]interface{}{"classroom_code": classroom.ClassroomCode},
+			map[string]interface{}{"classroom_code": code}, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	classroom.ClassroomCode = code
+	return classroom, nil
+}
+
+// CreateInviteService creates a signed invite link with an optional expiry, usage limit and role
+func (s *ClassroomService) CreateInviteService(ctx context.Context, classroomID uuid.UUID, req *requests.CreateInviteRequest, userID uuid.UUID, role string) (*model.ClassroomInvites, error) {
+	classroom, err := s.getManagedClassroom(ctx, classroomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	inv := &model.ClassroomInvites{
+		ID:          uuid.New(),
+		ClassroomID: classroom.ID,
+		Role:        "student",
+		MaxUses:     req.MaxUses,
+		CreatedBy:   userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if req.Role != nil {
+		inv.Role = *req.Role
+	}
+	if req.ExpiresInHours != nil {
+		expiresAt := now.Add(time.Duration(*req.ExpiresInHours) * time.Hour)
+		inv.ExpiresAt = &expiresAt
+	}
+
+	if _, err := s.db.NewInsert().Model(inv).Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create invite link: %w", err)
+	}
+
+	inv.Link = invite.Link(invite.Sign(inv.ID))
+	return inv, nil
+}
+
+// GetInvitesService lists a classroom's invite links, newest first
+func (s *ClassroomService) GetInvitesService(ctx context.Context, classroomID uuid.UUID, userID uuid.UUID, role string) ([]*model.ClassroomInvites, error) {
+	classroom, err := s.getManagedClassroom(ctx, classroomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	var invites []*model.ClassroomInvites
+	err = s.db.NewSelect().
+		Model(&invites).
+		Relation("Creator").
+		Where("ci.classroom_id = ?", classroom.ID).
+		Order("ci.created_at DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve invite links: %w", err)
+	}
+
+	for _, inv := range invites {
+		if inv.RevokedAt == nil {
+			inv.Link = invite.Link(invite.Sign(inv.ID))
+		}
+	}
+
+	return invites, nil
+}
+
+// GetInviteUsesService lists who used an invite link and what happened
+func (s *ClassroomService) GetInviteUsesService(ctx context.Context, classroomID uuid.UUID, inviteID uuid.UUID, userID uuid.UUID, role string) ([]*model.ClassroomInviteUses, error) {
+	classroom, err := s.getManagedClassroom(ctx, classroomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.getInvite(ctx, s.db, classroom.ID, inviteID); err != nil {
+		return nil, err
+	}
+
+	var uses []*model.ClassroomInviteUses
+	err = s.db.NewSelect().
+		Model(&uses).
+		Relation("User").
+		Where("ciu.invite_id = ?", inviteID).
+		Order("ciu.used_at DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve invite link usage: %w", err)
+	}
+
+	return uses, nil
+}
+
+// RevokeInviteService disables an invite link immediately
+func (s *ClassroomService) RevokeInviteService(ctx context.Context, classroomID uuid.UUID, inviteID uuid.UUID, userID uuid.UUID, role string) (*model.ClassroomInvites, error) {
+	classroom, err := s.getManagedClassroom(ctx, classroomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	inv, err := s.getInvite(ctx, s.db, classroom.ID, inviteID)
+	if err != nil {
+		return nil, err
+	}
+	if inv.RevokedAt != nil {
+		return nil, fmt.Errorf("invite link has been revoked")
+	}
+
+	now := time.Now()
+	_, err = s.db.NewUpdate().
+		Model((*model.ClassroomInvites)(nil)).
+		Set("revoked_at = ?", now).
+		Set("revoked_by = ?", userID).
+		Set("updated_at = ?", now).
+		Where("id = ?", inv.ID).
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke invite link: %w", err)
+	}
+
+	inv.RevokedAt = &now
+	inv.RevokedBy = &userID
+	return inv, nil
+}
+
+// AcceptInviteService redeems an invite link. Student invites enroll directly, join the waitlist when the
+// classroom is full, or leave a pending request when the classroom requires approval; assistant and
+// observer invites, which only teachers and admins can use, add a classroom membership with that role.
+func (s *ClassroomService) AcceptInviteService(ctx context.Context, token string, userID uuid.UUID, userRole string) (*JoinClassroomResult, error) {
+	inviteID, err := invite.Parse(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid invite link")
+	}
+
+	var inv model.ClassroomInvites
+	err = s.db.NewSelect().
+		Model(&inv).
+		Relation("Classroom").
+		Where("ci.id = ?", inviteID).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invalid invite link")
+		}
+		return nil, fmt.Errorf("failed to retrieve invite link: %w", err)
+	}
+
+	switch {
+	case inv.RevokedAt != nil:
+		return nil, fmt.Errorf("invite link has been revoked")
+	case inv.ExpiresAt != nil && time.Now().After(*inv.ExpiresAt):
+		return nil, fmt.Errorf("invite link has expired")
+	case inv.MaxUses != nil && inv.UseCount >= *inv.MaxUses:
+		return nil, fmt.Errorf("invite link has reached its usage limit")
+	case inv.Classroom == nil:
+		return nil, fmt.Errorf("classroom not found")
+	case !inv.Classroom.IsActive:
+		return nil, fmt.Errorf("classroom is not accepting students")
+	}
+
+	switch inv.Role {
+	case "student":
+		if userRole != "student" {
+			return nil, fmt.Errorf("this invite link is for students")
+		}
+	case "assistant", "observer":
+		if userRole != "teacher" && !isAdminRole(userRole) {
+			return nil, fmt.Errorf("this invite link is for teachers")
+		}
+	}
+
+	var result *JoinClassroomResult
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		// Claim a use first so concurrent redemptions cannot exceed MaxUses
+		now := time.Now()
+		res, err := tx.NewUpdate().
+			Model((*model.ClassroomInvites)(nil)).
+			Set("use_count = use_count + 1").
+			Set("last_used_at = ?", now).
+			Set("updated_at = ?", now).
+			Where("id = ? AND revoked_at IS NULL", inv.ID).
+			Where("max_uses IS NULL OR use_count < max_uses").
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to record invite link use: %w", err)
+		}
+		if affected, _ := res.RowsAffected(); affected == 0 {
+			return fmt.Errorf("invite link has reached its usage limit")
+		}
+
+		if inv.Role == "student" {
+			result, err = s.redeemStudentInvite(ctx, tx, inv.Classroom, userID)
+		} else {
+			result, err = addClassroomMember(ctx, tx, inv.ClassroomID, userID, inv.Role)
+		}
+		if err != nil {
+			return err
+		}
+
+		use := &model.ClassroomInviteUses{
+			ID:       uuid.New(),
+			InviteID: inv.ID,
+			UserID:   userID,
+			Result:   result.Status,
+			UsedAt:   now,
+		}
+		if _, err := tx.NewInsert().Model(use).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to record invite link use: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// redeemStudentInvite enrolls a student from an invite link, queueing them when there is no free seat.
+// Classrooms that require approval get a pending request instead, as with joining by code.
+func (s *ClassroomService) redeemStudentInvite(ctx context.Context, tx bun.Tx, classroom *model.Classrooms, studentID uuid.UUID) (*JoinClassroomResult, error) {
+	if err := lockClassroom(ctx, tx, classroom.ID); err != nil {
+		return nil, err
+	}
+
+	if classroom.RequireApproval {
+		enrolled, err := tx.NewSelect().
+			Model((*model.ClassroomStudents)(nil)).
+			Where("classroom_id = ? AND student_id = ? AND is_active = true", classroom.ID, studentID).
+			Exists(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check enrollment: %w", err)
+		}
+		if enrolled {
+			return nil, fmt.Errorf("already enrolled in this classroom")
+		}
+
+		pending, err := tx.NewSelect().
+			Model((*model.ClassroomMembers)(nil)).
+			Where("classroom_id = ? AND user_id = ? AND status = 'pending'", classroom.ID, studentID).
+			Exists(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check join requests: %w", err)
+		}
+		if pending {
+			return nil, fmt.Errorf("join request is already pending")
+		}
+
+		member, err := createJoinRequest(ctx, tx, classroom, studentID)
+		if err != nil {
+			return nil, err
+		}
+		return &JoinClassroomResult{Status: "pending", Membership: member}, nil
+	}
+
+	queued, err := hasWaitlist(ctx, tx, classroom.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !queued {
+		enrollment, err := enrollStudent(ctx, tx, classroom.ID, studentID)
+		if err == nil {
+			return &JoinClassroomResult{Status: "enrolled", Enrollment: enrollment}, nil
+		}
+		if err.Error() != "classroom is full" {
+			return nil, err
+		}
+	}
+
+	entry, err := enqueueWaitlist(ctx, tx, classroom.ID, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JoinClassroomResult{Status: "waitlisted", Waitlist: entry}, nil
+}
+
+// addClassroomMember adds an active non-student membership such as an assistant or observer
+func addClassroomMember(ctx context.Context, db bun.IDB, classroomID uuid.UUID, userID uuid.UUID, role string) (*JoinClassroomResult, error) {
+	exists, err := db.NewSelect().
+		Model((*model.ClassroomMembers)(nil)).
+		Where("classroom_id = ? AND user_id = ? AND status = 'active'", classroomID, userID).
+		Exists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check membership: %w", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("already a member of this classroom")
+	}
+
+	now := time.Now()
+	member := &model.ClassroomMembers{
+		ID:          uuid.New(),
+		ClassroomID: classroomID,
+		UserID:      userID,
+		Role:        role,
+		Status:      "active",
+		JoinedAt:    now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if _, err := db.NewInsert().Model(member).Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to add classroom member: %w", err)
+	}
+
+	return &JoinClassroomResult{Status: "joined", Membership: member}, nil
+}
+
+// getInvite loads an invite link belonging to a classroom
+func (s *ClassroomService) getInvite(ctx context.Context, db bun.IDB, classroomID uuid.UUID, inviteID uuid.UUID) (*model.ClassroomInvites, error) {
+	var inv model.ClassroomInvites
+	err := db.NewSelect().
+		Model(&inv).
+		Where("ci.id = ? AND ci.classroom_id = ?", inviteID, classroomID).
+		Scan(ctx)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invite link not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve invite link: %w", err)
+	}
+
+	return &inv, nil
+}
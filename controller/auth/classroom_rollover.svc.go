@@ -0,0 +1,327 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/uptrace/bun"
+)
+
+// RolloverClassroom is the plan (or result) for one source classroom
+type RolloverClassroom struct {
+	SourceClassroomID uuid.UUID                 `json:"source_classroom_id"`
+	SourceName        string                    `json:"source_name"`
+	Action            string                    `json:"action"` // create or skip
+	Note              string                    `json:"note,omitempty"`
+	DayShift          int                       `json:"day_shift"` // days added to copied assignment due dates
+	Classroom         *model.Classrooms         `json:"classroom,omitempty"`
+	Schedules         []*model.ClassSchedules   `json:"schedules,omitempty"`
+	Members           []*model.ClassroomMembers `json:"members,omitempty"`
+	Assignments       []*model.Assignments      `json:"assignments,omitempty"`
+}
+
+// RolloverReport summarizes a rollover or its preview
+type RolloverReport struct {
+	DryRun     bool                 `json:"dry_run"`
+	Committed  bool                 `json:"committed"`
+	TargetTerm *model.Terms         `json:"target_term"`
+	Created    int                  `json:"created"`
+	Skipped    int                  `json:"skipped"`
+	Classrooms []*RolloverClassroom `json:"classrooms"`
+}
+
+// RolloverClassroomsService clones classrooms into a target term: settings, weekly slots and co-teachers,
+// and optionally the assignments as unpublished drafts with due dates moved by the gap between the terms.
+// Students are not copied. Classrooms already rolled into the target term are skipped. With DryRun the
+// report shows what would be created; the classroom codes in a preview are not reserved.
+func (s *ClassroomService) RolloverClassroomsService(ctx context.Context, req *requests.RolloverClassroomsRequest, userID uuid.UUID, role string) (*RolloverReport, error) {
+	var target model.Terms
+	err := s.db.NewSelect().
+		Model(&target).
+		Where("tm.id = ?", req.TargetTermID).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("target term not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve term: %w", err)
+	}
+	if target.ArchivedAt != nil {
+		return nil, fmt.Errorf("term is archived")
+	}
+
+	report := &RolloverReport{DryRun: req.DryRun, TargetTerm: &target}
+	seen := make(map[uuid.UUID]bool)
+	codes := make(map[string]bool)
+	for _, classroomID := range req.ClassroomIDs {
+		if seen[classroomID] {
+			continue
+		}
+		seen[classroomID] = true
+
+		plan, err := s.planRollover(ctx, classroomID, &target, req.CopyAssignments, codes, userID, role)
+		if err != nil {
+			return nil, err
+		}
+		if plan.Action == "create" {
+			report.Created++
+		} else {
+			report.Skipped++
+		}
+		report.Classrooms = append(report.Classrooms, plan)
+	}
+
+	if req.DryRun || report.Created == 0 {
+		return report, nil
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		for _, plan := range report.Classrooms {
+			if plan.Action != "create" {
+				continue
+			}
+			// Checked again under the unique index, in case a concurrent rollover created the copy since planning
+			existingID, err := findRollover(ctx, tx, plan.SourceClassroomID, target.ID)
+			if err != nil {
+				return err
+			}
+			if existingID != nil {
+				return fmt.Errorf("classroom was already rolled over into the target term")
+			}
+			if err := insertRollover(ctx, tx, plan); err != nil {
+				return err
+			}
+			if err := writeAuditLog(ctx, tx, &userID, "classroom.rollover", "classrooms", &plan.Classroom.ID, nil, plan.Classroom, map[string]interface{}{
+				"source_classroom_id": plan.SourceClassroomID,
+				"target_term_id":      target.ID,
+				"schedules":           len(plan.Schedules),
+				"members":             len(plan.Members),
+				"assignments":         len(plan.Assignments),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.Committed = true
+	return report, nil
+}
+
+// planRollover builds the copies of one classroom without saving them; codes holds the codes already
+// handed out in this batch
+func (s *ClassroomService) planRollover(ctx context.Context, classroomID uuid.UUID, target *model.Terms, copyAssignments bool, codes map[string]bool, userID uuid.UUID, role string) (*RolloverClassroom, error) {
+	source, err := loadManagedClassroom(ctx, s.db, classroomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &RolloverClassroom{
+		SourceClassroomID: source.ID,
+		SourceName:        source.Name,
+		Action:            "create",
+	}
+
+	if source.SchoolID == nil || *source.SchoolID != target.SchoolID {
+		plan.Action = "skip"
+		plan.Note = "classroom does not belong to the term's school"
+		return plan, nil
+	}
+	if source.TermID != nil && *source.TermID == target.ID {
+		plan.Action = "skip"
+		plan.Note = "classroom already belongs to the target term"
+		return plan, nil
+	}
+
+	existingID, err := findRollover(ctx, s.db, source.ID, target.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existingID != nil {
+		plan.Action = "skip"
+		plan.Note = "already rolled over into the target term as " + existingID.String()
+		return plan, nil
+	}
+
+	var code string
+	for code == "" || codes[code] {
+		code, err = s.generateClassroomCode(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	codes[code] = true
+
+	now := time.Now()
+	schoolID := target.SchoolID
+	clone := &model.Classrooms{
+		ID:              uuid.New(),
+		SchoolID:        &schoolID,
+		TermID:          &target.ID,
+		RolledOverFrom:  &source.ID,
+		Name:            source.Name,
+		Subject:         source.Subject,
+		Description:     source.Description,
+		GradeLevel:      source.GradeLevel,
+		Section:         source.Section,
+		RoomNumber:      source.RoomNumber,
+		TeacherID:       source.TeacherID,
+		ClassroomCode:   code,
+		MaxStudents:     source.MaxStudents,
+		RequireApproval: source.RequireApproval,
+		Schedule:        source.Schedule,
+		IsActive:        true,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	plan.Classroom = clone
+
+	var schedules []*model.ClassSchedules
+	err = s.db.NewSelect().
+		Model(&schedules).
+		Where("csch.classroom_id = ? AND csch.is_active = true", source.ID).
+		Order("csch.day_of_week ASC", "csch.start_time ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve schedules: %w", err)
+	}
+	for _, schedule := range schedules {
+		// The copied slots run for the new term only, so they never clash with the old term's slots
+		from, until := target.StartDate, target.EndDate
+		plan.Schedules = append(plan.Schedules, &model.ClassSchedules{
+			ID:             uuid.New(),
+			ClassroomID:    clone.ID,
+			DayOfWeek:      schedule.DayOfWeek,
+			StartTime:      scheduleClock(schedule.StartTime.Hour(), schedule.StartTime.Minute()),
+			EndTime:        scheduleClock(schedule.EndTime.Hour(), schedule.EndTime.Minute()),
+			RoomNumber:     schedule.RoomNumber,
+			IsActive:       true,
+			EffectiveFrom:  &from,
+			EffectiveUntil: &until,
+			CreatedAt:      now,
+		})
+	}
+
+	var members []*model.ClassroomMembers
+	err = s.db.NewSelect().
+		Model(&members).
+		Where("cm.classroom_id = ? AND cm.status = 'active' AND cm.role IN ('teacher', 'assistant')", source.ID).
+		Order("cm.joined_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve classroom members: %w", err)
+	}
+	for _, member := range members {
+		plan.Members = append(plan.Members, &model.ClassroomMembers{
+			ID:          uuid.New(),
+			ClassroomID: clone.ID,
+			UserID:      member.UserID,
+			Role:        member.Role,
+			Status:      "active",
+			JoinedAt:    now,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+	}
+
+	if !copyAssignments {
+		return plan, nil
+	}
+
+	plan.DayShift, err = rolloverDayShift(ctx, s.db, source, target)
+	if err != nil {
+		return nil, err
+	}
+
+	var assignments []*model.Assignments
+	err = s.db.NewSelect().
+		Model(&assignments).
+		Where("a.classroom_id = ?", source.ID).
+		Order("a.due_date ASC NULLS LAST", "a.created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve assignments: %w", err)
+	}
+	for _, assignment := range assignments {
+		copied := *assignment
+		copied.ID = uuid.New()
+		copied.ClassroomID = clone.ID
+		copied.IsPublished = false
+		copied.Status = "draft"
+		copied.CreatedBy = userID
+		copied.CreatedAt = now
+		copied.UpdatedAt = now
+		if assignment.DueDate != nil {
+			due := assignment.DueDate.AddDate(0, 0, plan.DayShift)
+			copied.DueDate = &due
+		}
+		plan.Assignments = append(plan.Assignments, &copied)
+	}
+
+	return plan, nil
+}
+
+// rolloverDayShift is the number of days between the start of the source classroom's term (or its creation
+// date when it has none) and the start of the target term
+func rolloverDayShift(ctx context.Context, db bun.IDB, source *model.Classrooms, target *model.Terms) (int, error) {
+	sourceStart, _, err := termPeriod(ctx, db, source)
+	if err != nil {
+		return 0, err
+	}
+	sourceStart = time.Date(sourceStart.Year(), sourceStart.Month(), sourceStart.Day(), 0, 0, 0, 0, time.UTC)
+	targetStart := time.Date(target.StartDate.Year(), target.StartDate.Month(), target.StartDate.Day(), 0, 0, 0, 0, time.UTC)
+	return int(targetStart.Sub(sourceStart).Hours() / 24), nil
+}
+
+// findRollover returns the copy of a classroom already rolled over into a term, or nil
+func findRollover(ctx context.Context, db bun.IDB, sourceID, termID uuid.UUID) (*uuid.UUID, error) {
+	var existing model.Classrooms
+	err := db.NewSelect().
+		Model(&existing).
+		Column("id").
+		Where("c.rolled_over_from = ? AND c.term_id = ?", sourceID, termID).
+		Limit(1).
+		Scan(ctx)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check earlier rollovers: %w", err)
+	}
+	return &existing.ID, nil
+}
+
+// insertRollover saves the copies planned for one classroom
+func insertRollover(ctx context.Context, tx bun.Tx, plan *RolloverClassroom) error {
+	if _, err := tx.NewInsert().Model(plan.Classroom).Exec(ctx); err != nil {
+		if strings.Contains(err.Error(), "idx_classrooms_rollover_term") {
+			return fmt.Errorf("classroom was already rolled over into the target term")
+		}
+		return fmt.Errorf("failed to create classroom %s: %w", plan.SourceName, err)
+	}
+	if len(plan.Schedules) > 0 {
+		if _, err := tx.NewInsert().Model(&plan.Schedules).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to copy schedules of %s: %w", plan.SourceName, err)
+		}
+	}
+	if len(plan.Members) > 0 {
+		if _, err := tx.NewInsert().Model(&plan.Members).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to copy members of %s: %w", plan.SourceName, err)
+		}
+	}
+	if len(plan.Assignments) > 0 {
+		if _, err := tx.NewInsert().Model(&plan.Assignments).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to copy assignments of %s: %w", plan.SourceName, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/uptrace/bun"
+)
+
+// GetRosterService lists a classroom's students with filtering, sorting and pagination
+func (s *ClassroomService) GetRosterService(ctx context.Context, classroomID uuid.UUID, req *requests.RosterQueryRequest, userID uuid.UUID, role string) ([]*model.ClassroomStudents, int64, error) {
+	classroom, err := s.getManagedClassroom(ctx, classroomID, userID, role)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var students []*model.ClassroomStudents
+	query := s.db.NewSelect().
+		Model(&students).
+		Relation("Student").
+		Where("cs.classroom_id = ?", classroom.ID)
+
+	switch req.Status {
+	case "inactive":
+		query = query.Where("cs.is_active = false")
+	case "all":
+	default:
+		query = query.Where("cs.is_active = true")
+	}
+
+	if req.Search != nil && *req.Search != "" {
+		searchTerm := "%" + strings.ToLower(*req.Search) + "%"
+		query = query.Where("(LOWER(student.first_name) LIKE ? OR LOWER(student.last_name) LIKE ? OR LOWER(cs.student_number) LIKE ?)",
+			searchTerm, searchTerm, searchTerm)
+	}
+
+	total, err := query.Count(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count classroom students: %w", err)
+	}
+
+	direction := "ASC"
+	if req.Order == "desc" {
+		direction = "DESC"
+	}
+
+	switch req.Sort {
+	case "name":
+		query = query.OrderExpr("student.first_name " + direction).OrderExpr("student.last_name " + direction)
+	case "enrolled_at":
+		query = query.OrderExpr("cs.enrolled_at " + direction)
+	default:
+		query = query.OrderExpr("cs.student_number " + direction + " NULLS LAST").OrderExpr("student.first_name ASC")
+	}
+
+	page := 1
+	limit := 20
+	if req.Page > 0 {
+		page = req.Page
+	}
+	if req.Limit > 0 {
+		limit = req.Limit
+	}
+
+	err = query.Limit(limit).Offset((page - 1) * limit).Scan(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve classroom students: %w", err)
+	}
+
+	return students, int64(total), nil
+}
+
+// AddRosterStudentService enrolls a student on the teacher's behalf
+func (s *ClassroomService) AddRosterStudentService(ctx context.Context, classroomID uuid.UUID, req *requests.AddRosterStudentRequest, userID uuid.UUID, role string) (*model.ClassroomStudents, error) {
+	classroom, err := s.getManagedClassroom(ctx, classroomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	var student model.Users
+	err = s.db.NewSelect().
+		Model(&student).
+		Where("u.id = ? AND u.deleted_at IS NULL", req.StudentID).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("student not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve student: %w", err)
+	}
+	if student.Role != "student" {
+		return nil, fmt.Errorf("user is not a student")
+	}
+
+	var enrollment *model.ClassroomStudents
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var err error
+		enrollment, err = enrollStudent(ctx, tx, classroom.ID, student.ID)
+		if err != nil {
+			return err
+		}
+
+		return applyRosterDetails(ctx, tx, enrollment, req.StudentNumber, req.SeatNumber, req.Notes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getRosterEntry(ctx, classroom.ID, student.ID)
+}
+
+// UpdateRosterStudentService edits a roster entry; setting is_active re-enrolls or deactivates the student
+func (s *ClassroomService) UpdateRosterStudentService(ctx context.Context, classroomID uuid.UUID, studentID uuid.UUID, req *requests.UpdateRosterStudentRequest, userID uuid.UUID, role string) (*model.ClassroomStudents, error) {
+	classroom, err := s.getManagedClassroom(ctx, classroomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.StudentNumber == nil && req.SeatNumber == nil && req.Notes == nil && req.IsActive == nil {
+		return nil, fmt.Errorf("no data to update")
+	}
+
+	enrollment, err := s.getRosterEntry(ctx, classroom.ID, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if req.IsActive != nil && *req.IsActive != enrollment.IsActive {
+			if *req.IsActive {
+				if _, err := enrollStudent(ctx, tx, classroom.ID, studentID); err != nil {
+					return err
+				}
+				enrollment.IsActive = true
+			} else {
+				if err := deactivateEnrollment(ctx, tx, enrollment); err != nil {
+					return err
+				}
+				if err := promoteFromWaitlist(ctx, tx, classroom.ID); err != nil {
+					return err
+				}
+			}
+		}
+
+		return applyRosterDetails(ctx, tx, enrollment, req.StudentNumber, req.SeatNumber, req.Notes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getRosterEntry(ctx, classroom.ID, studentID)
+}
+
+// RemoveRosterStudentService deactivates a student's enrollment; the row and its history are kept
+func (s *ClassroomService) RemoveRosterStudentService(ctx context.Context, classroomID uuid.UUID, studentID uuid.UUID, userID uuid.UUID, role string) error {
+	classroom, err := s.getManagedClassroom(ctx, classroomID, userID, role)
+	if err != nil {
+		return err
+	}
+
+	enrollment, err := s.getRosterEntry(ctx, classroom.ID, studentID)
+	if err != nil {
+		return err
+	}
+	if !enrollment.IsActive {
+		return fmt.Errorf("student is already inactive")
+	}
+
+	return s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := deactivateEnrollment(ctx, tx, enrollment); err != nil {
+			return err
+		}
+		// The freed seat goes to the next student on the waitlist
+		return promoteFromWaitlist(ctx, tx, classroom.ID)
+	})
+}
+
+// getRosterEntry loads one student's enrollment in a classroom, active or not
+func (s *ClassroomService) getRosterEntry(ctx context.Context, classroomID uuid.UUID, studentID uuid.UUID) (*model.ClassroomStudents, error) {
+	var enrollment model.ClassroomStudents
+	err := s.db.NewSelect().
+		Model(&enrollment).
+		Relation("Student").
+		Where("cs.classroom_id = ? AND cs.student_id = ?", classroomID, studentID).
+		Scan(ctx)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("student is not in this classroom")
+		}
+		return nil, fmt.Errorf("failed to retrieve classroom student: %w", err)
+	}
+
+	return &enrollment, nil
+}
+
+// applyRosterDetails sets the optional roster fields that were provided, keeping student numbers unique per classroom
+func applyRosterDetails(ctx context.Context, db bun.IDB, enrollment *model.ClassroomStudents, studentNumber, seatNumber, notes *string) error {
+	if studentNumber == nil && seatNumber == nil && notes == nil {
+		return nil
+	}
+
+	query := db.NewUpdate().
+		Model((*model.ClassroomStudents)(nil)).
+		Where("id = ?", enrollment.ID)
+
+	if studentNumber != nil {
+		number := strings.TrimSpace(*studentNumber)
+		if number != "" {
+			taken, err := db.NewSelect().
+				Model((*model.ClassroomStudents)(nil)).
+				Where("classroom_id = ? AND student_number = ? AND id <> ?", enrollment.ClassroomID, number, enrollment.ID).
+				Exists(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to check student number: %w", err)
+			}
+			if taken {
+				return fmt.Errorf("student number is already in use")
+			}
+			query = query.Set("student_number = ?", number)
+		} else {
+			query = query.Set("student_number = NULL")
+		}
+	}
+	if seatNumber != nil {
+		query = query.Set("seat_number = ?", *seatNumber)
+	}
+	if notes != nil {
+		query = query.Set("notes = ?", *notes)
+	}
+
+	if _, err := query.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to update classroom student: %w", err)
+	}
+	return nil
+}
+
+// deactivateEnrollment marks an enrollment inactive and ends the matching classroom membership
+func deactivateEnrollment(ctx context.Context, db bun.IDB, enrollment *model.ClassroomStudents) error {
+	_, err := db.NewUpdate().
+		Model((*model.ClassroomStudents)(nil)).
+		Set("is_active = false").
+		Where("id = ?", enrollment.ID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to deactivate classroom student: %w", err)
+	}
+
+	now := time.Now()
+	_, err = db.NewUpdate().
+		Model((*model.ClassroomMembers)(nil)).
+		Set("status = 'inactive'").
+		Set("left_at = ?", now).
+		Set("updated_at = ?", now).
+		Where("classroom_id = ? AND user_id = ? AND status = 'active'", enrollment.ClassroomID, enrollment.StudentID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update classroom membership: %w", err)
+	}
+
+	enrollment.IsActive = false
+	return nil
+}
@@ -0,0 +1,463 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/uptrace/bun"
+)
+
+// Seat is one position on a seat map
+type Seat struct {
+	Label         string     `json:"label"`
+	Row           int        `json:"row"`
+	Column        int        `json:"column"`
+	Available     bool       `json:"available"`
+	StudentID     *uuid.UUID `json:"student_id,omitempty"`
+	StudentName   string     `json:"student_name,omitempty"`
+	StudentNumber *string    `json:"student_number,omitempty"`
+}
+
+// SeatingPlan is a classroom's seat map, row by row, with the active students who have no seat on it
+type SeatingPlan struct {
+	ClassroomID      uuid.UUID                  `json:"classroom_id"`
+	Rows             int                        `json:"rows"`
+	Columns          int                        `json:"columns"`
+	UnavailableSeats []string                   `json:"unavailable_seats"`
+	Seats            [][]*Seat                  `json:"seats"`
+	Unseated         []*model.ClassroomStudents `json:"unseated"`
+	UpdatedAt        time.Time                  `json:"updated_at"`
+}
+
+// GetSeatingPlanService returns a classroom's seat map
+func (s *ClassroomService) GetSeatingPlanService(ctx context.Context, classroomID uuid.UUID, userID uuid.UUID, role string) (*SeatingPlan, error) {
+	classroom, err := s.getManagedClassroom(ctx, classroomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	layout, err := loadSeatingLayout(ctx, s.db, classroom.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildSeatingPlan(ctx, s.db, layout)
+}
+
+// SetSeatingLayoutService creates or replaces a classroom's grid. Students whose seat is no longer an
+// available seat on the new grid lose it.
+func (s *ClassroomService) SetSeatingLayoutService(ctx context.Context, classroomID uuid.UUID, req *requests.SeatingLayoutRequest, userID uuid.UUID, role string) (*SeatingPlan, error) {
+	classroom, err := s.getManagedClassroom(ctx, classroomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	unavailable := make([]string, 0, len(req.UnavailableSeats))
+	blocked := make(map[string]bool)
+	for _, label := range req.UnavailableSeats {
+		row, column, err := parseSeatLabel(label, req.Rows, req.Columns)
+		if err != nil {
+			return nil, err
+		}
+		label = seatLabel(row, column)
+		if !blocked[label] {
+			blocked[label] = true
+			unavailable = append(unavailable, label)
+		}
+	}
+	sort.Slice(unavailable, func(i, j int) bool {
+		ri, ci, _ := parseSeatLabel(unavailable[i], req.Rows, req.Columns)
+		rj, cj, _ := parseSeatLabel(unavailable[j], req.Rows, req.Columns)
+		return ri < rj || (ri == rj && ci < cj)
+	})
+	data, err := json.Marshal(unavailable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode unavailable seats: %w", err)
+	}
+	unavailableJSON := string(data)
+
+	now := time.Now()
+	layout := &model.ClassroomSeatingPlans{
+		ID:               uuid.New(),
+		ClassroomID:      classroom.ID,
+		Rows:             req.Rows,
+		Columns:          req.Columns,
+		UnavailableSeats: &unavailableJSON,
+		UpdatedBy:        userID,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().
+			Model(layout).
+			On("CONFLICT (classroom_id) DO UPDATE").
+			Set("rows = EXCLUDED.rows").
+			Set("columns = EXCLUDED.columns").
+			Set("unavailable_seats = EXCLUDED.unavailable_seats").
+			Set("updated_by = EXCLUDED.updated_by").
+			Set("updated_at = EXCLUDED.updated_at").
+			Returning("*").
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to save seating plan: %w", err)
+		}
+
+		var enrollments []*model.ClassroomStudents
+		err = tx.NewSelect().
+			Model(&enrollments).
+			Where("cs.classroom_id = ? AND cs.is_active = true AND cs.seat_number IS NOT NULL", classroom.ID).
+			Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve classroom students: %w", err)
+		}
+
+		var cleared []uuid.UUID
+		for _, enrollment := range enrollments {
+			row, column, err := parseSeatLabel(*enrollment.SeatNumber, layout.Rows, layout.Columns)
+			if err != nil || blocked[seatLabel(row, column)] {
+				cleared = append(cleared, enrollment.ID)
+			}
+		}
+		if len(cleared) > 0 {
+			_, err = tx.NewUpdate().
+				Model((*model.ClassroomStudents)(nil)).
+				Set("seat_number = NULL").
+				Where("id IN (?)", bun.In(cleared)).
+				Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to clear seats: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buildSeatingPlan(ctx, s.db, layout)
+}
+
+// AssignSeatsService places students in seats. A seat may only be taken if its current occupant is moved
+// elsewhere in the same request.
+func (s *ClassroomService) AssignSeatsService(ctx context.Context, classroomID uuid.UUID, req *requests.AssignSeatsRequest, userID uuid.UUID, role string) (*SeatingPlan, error) {
+	classroom, err := s.getManagedClassroom(ctx, classroomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	layout, err := loadSeatingLayout(ctx, s.db, classroom.ID)
+	if err != nil {
+		return nil, err
+	}
+	unavailable, err := unavailableSeats(layout)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollments, err := seatedEnrollments(ctx, s.db, classroom.ID)
+	if err != nil {
+		return nil, err
+	}
+	byStudent := make(map[uuid.UUID]*model.ClassroomStudents, len(enrollments))
+	seats := make(map[uuid.UUID]string, len(enrollments))
+	for _, enrollment := range enrollments {
+		byStudent[enrollment.StudentID] = enrollment
+		if label, ok := validSeat(enrollment.SeatNumber, layout, unavailable); ok {
+			seats[enrollment.StudentID] = label
+		}
+	}
+
+	changed := make(map[uuid.UUID]bool)
+	for _, assignment := range req.Assignments {
+		if _, ok := byStudent[assignment.StudentID]; !ok {
+			return nil, fmt.Errorf("student is not in this classroom")
+		}
+		if changed[assignment.StudentID] {
+			return nil, fmt.Errorf("student is listed more than once")
+		}
+		changed[assignment.StudentID] = true
+
+		if strings.TrimSpace(assignment.Seat) == "" {
+			delete(seats, assignment.StudentID)
+			continue
+		}
+		row, column, err := parseSeatLabel(assignment.Seat, layout.Rows, layout.Columns)
+		if err != nil {
+			return nil, err
+		}
+		label := seatLabel(row, column)
+		if unavailable[label] {
+			return nil, fmt.Errorf("seat is unavailable")
+		}
+		seats[assignment.StudentID] = label
+	}
+
+	occupants := make(map[string]int, len(seats))
+	for _, label := range seats {
+		occupants[label]++
+	}
+	for studentID := range changed {
+		if label, ok := seats[studentID]; ok && occupants[label] > 1 {
+			return nil, fmt.Errorf("seat is already taken")
+		}
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		for studentID := range changed {
+			var seat *string
+			if label, ok := seats[studentID]; ok {
+				seat = &label
+			}
+			if err := setSeat(ctx, tx, byStudent[studentID].ID, seat); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buildSeatingPlan(ctx, s.db, layout)
+}
+
+// ArrangeSeatsService seats every active student row by row, either in student number order or at random
+func (s *ClassroomService) ArrangeSeatsService(ctx context.Context, classroomID uuid.UUID, req *requests.ArrangeSeatsRequest, userID uuid.UUID, role string) (*SeatingPlan, error) {
+	classroom, err := s.getManagedClassroom(ctx, classroomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	layout, err := loadSeatingLayout(ctx, s.db, classroom.ID)
+	if err != nil {
+		return nil, err
+	}
+	unavailable, err := unavailableSeats(layout)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	for row := 1; row <= layout.Rows; row++ {
+		for column := 1; column <= layout.Columns; column++ {
+			if label := seatLabel(row, column); !unavailable[label] {
+				labels = append(labels, label)
+			}
+		}
+	}
+
+	enrollments, err := seatedEnrollments(ctx, s.db, classroom.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(enrollments) > len(labels) {
+		return nil, fmt.Errorf("not enough seats")
+	}
+
+	if req.Mode == "random" {
+		rand.Shuffle(len(enrollments), func(i, j int) {
+			enrollments[i], enrollments[j] = enrollments[j], enrollments[i]
+		})
+	} else {
+		sort.SliceStable(enrollments, func(i, j int) bool {
+			return studentNumberLess(enrollments[i], enrollments[j])
+		})
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		for i, enrollment := range enrollments {
+			if err := setSeat(ctx, tx, enrollment.ID, &labels[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buildSeatingPlan(ctx, s.db, layout)
+}
+
+// loadSeatingLayout returns a classroom's saved grid
+func loadSeatingLayout(ctx context.Context, db bun.IDB, classroomID uuid.UUID) (*model.ClassroomSeatingPlans, error) {
+	var layout model.ClassroomSeatingPlans
+	err := db.NewSelect().
+		Model(&layout).
+		Where("csp.classroom_id = ?", classroomID).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("seating plan not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve seating plan: %w", err)
+	}
+	return &layout, nil
+}
+
+// buildSeatingPlan places the classroom's active students on the grid
+func buildSeatingPlan(ctx context.Context, db bun.IDB, layout *model.ClassroomSeatingPlans) (*SeatingPlan, error) {
+	unavailable, err := unavailableSeats(layout)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &SeatingPlan{
+		ClassroomID:      layout.ClassroomID,
+		Rows:             layout.Rows,
+		Columns:          layout.Columns,
+		UnavailableSeats: []string{},
+		Unseated:         []*model.ClassroomStudents{},
+		UpdatedAt:        layout.UpdatedAt,
+	}
+
+	byLabel := make(map[string]*Seat)
+	for row := 1; row <= layout.Rows; row++ {
+		line := make([]*Seat, 0, layout.Columns)
+		for column := 1; column <= layout.Columns; column++ {
+			seat := &Seat{Label: seatLabel(row, column), Row: row, Column: column, Available: !unavailable[seatLabel(row, column)]}
+			if !seat.Available {
+				plan.UnavailableSeats = append(plan.UnavailableSeats, seat.Label)
+			}
+			byLabel[seat.Label] = seat
+			line = append(line, seat)
+		}
+		plan.Seats = append(plan.Seats, line)
+	}
+
+	enrollments, err := seatedEnrollments(ctx, db, layout.ClassroomID)
+	if err != nil {
+		return nil, err
+	}
+	for _, enrollment := range enrollments {
+		label, ok := validSeat(enrollment.SeatNumber, layout, unavailable)
+		if !ok || byLabel[label].StudentID != nil {
+			plan.Unseated = append(plan.Unseated, enrollment)
+			continue
+		}
+		seat := byLabel[label]
+		studentID := enrollment.StudentID
+		seat.StudentID = &studentID
+		seat.StudentNumber = enrollment.StudentNumber
+		if enrollment.Student != nil {
+			seat.StudentName = enrollment.Student.FirstName + " " + enrollment.Student.LastName
+		}
+	}
+
+	return plan, nil
+}
+
+// seatedEnrollments returns a classroom's active enrollments with their students
+func seatedEnrollments(ctx context.Context, db bun.IDB, classroomID uuid.UUID) ([]*model.ClassroomStudents, error) {
+	var enrollments []*model.ClassroomStudents
+	err := db.NewSelect().
+		Model(&enrollments).
+		Relation("Student").
+		Where("cs.classroom_id = ? AND cs.is_active = true", classroomID).
+		Order("cs.enrolled_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve classroom students: %w", err)
+	}
+	return enrollments, nil
+}
+
+// setSeat stores a student's seat label, or clears it when seat is nil
+func setSeat(ctx context.Context, db bun.IDB, enrollmentID uuid.UUID, seat *string) error {
+	_, err := db.NewUpdate().
+		Model((*model.ClassroomStudents)(nil)).
+		Set("seat_number = ?", seat).
+		Where("id = ?", enrollmentID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to assign seat: %w", err)
+	}
+	return nil
+}
+
+// unavailableSeats decodes the layout's unavailable seat labels
+func unavailableSeats(layout *model.ClassroomSeatingPlans) (map[string]bool, error) {
+	unavailable := make(map[string]bool)
+	if layout.UnavailableSeats == nil {
+		return unavailable, nil
+	}
+	var labels []string
+	if err := json.Unmarshal([]byte(*layout.UnavailableSeats), &labels); err != nil {
+		return nil, fmt.Errorf("failed to decode unavailable seats: %w", err)
+	}
+	for _, label := range labels {
+		unavailable[label] = true
+	}
+	return unavailable, nil
+}
+
+// validSeat normalizes a stored seat number and reports whether it is an available seat on the layout
+func validSeat(seatNumber *string, layout *model.ClassroomSeatingPlans, unavailable map[string]bool) (string, bool) {
+	if seatNumber == nil {
+		return "", false
+	}
+	row, column, err := parseSeatLabel(*seatNumber, layout.Rows, layout.Columns)
+	if err != nil {
+		return "", false
+	}
+	label := seatLabel(row, column)
+	return label, !unavailable[label]
+}
+
+// seatLabel names the seat at a 1-based row and column
+func seatLabel(row, column int) string {
+	return strconv.Itoa(row) + "-" + strconv.Itoa(column)
+}
+
+// parseSeatLabel reads a "row-column" label and checks it lies on a rows × columns grid
+func parseSeatLabel(label string, rows, columns int) (int, int, error) {
+	rowPart, columnPart, ok := strings.Cut(strings.TrimSpace(label), "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid seat")
+	}
+	row, err := strconv.Atoi(strings.TrimSpace(rowPart))
+	if err != nil || row < 1 || row > rows {
+		return 0, 0, fmt.Errorf("invalid seat")
+	}
+	column, err := strconv.Atoi(strings.TrimSpace(columnPart))
+	if err != nil || column < 1 || column > columns {
+		return 0, 0, fmt.Errorf("invalid seat")
+	}
+	return row, column, nil
+}
+
+// studentNumberLess orders enrollments by student number, numerically when both are numbers, with
+// unnumbered students last in name order
+func studentNumberLess(a, b *model.ClassroomStudents) bool {
+	switch {
+	case a.StudentNumber != nil && b.StudentNumber == nil:
+		return true
+	case a.StudentNumber == nil && b.StudentNumber != nil:
+		return false
+	case a.StudentNumber != nil && b.StudentNumber != nil && *a.StudentNumber != *b.StudentNumber:
+		na, errA := strconv.Atoi(*a.StudentNumber)
+		nb, errB := strconv.Atoi(*b.StudentNumber)
+		if errA == nil && errB == nil {
+			return na < nb
+		}
+		return *a.StudentNumber < *b.StudentNumber
+	}
+	if a.Student == nil || b.Student == nil {
+		return false
+	}
+	if a.Student.FirstName != b.Student.FirstName {
+		return a.Student.FirstName < b.Student.FirstName
+	}
+	return a.Student.LastName < b.Student.LastName
+}
@@ -0,0 +1,418 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/uptrace/bun"
+)
+
+// RequestTransferService files a transfer of one classroom, or every classroom of a teacher, to another
+// teacher. Teachers may only give away their own classrooms and need a school admin to approve; a transfer
+// filed by an admin is applied straight away.
+func (s *ClassroomService) RequestTransferService(ctx context.Context, req *requests.TransferClassroomsRequest, userID uuid.UUID, role string) (*model.ClassroomTransfers, error) {
+	fromID := userID
+	if req.FromTeacherID != nil {
+		fromID = *req.FromTeacherID
+	}
+	if role == "student" || (!isAdminRole(role) && fromID != userID) {
+		return nil, fmt.Errorf("unauthorized to transfer classrooms")
+	}
+	if fromID == req.ToTeacherID {
+		return nil, fmt.Errorf("cannot transfer to the same teacher")
+	}
+
+	from, err := loadTransferTeacher(ctx, s.db, fromID)
+	if err != nil {
+		return nil, err
+	}
+	to, err := loadTransferTeacher(ctx, s.db, req.ToTeacherID)
+	if err != nil {
+		return nil, err
+	}
+	if !to.IsActive || (to.Role != "teacher" && to.Role != "admin") {
+		return nil, fmt.Errorf("target user is not an active teacher")
+	}
+	if from.SchoolID != nil && to.SchoolID != nil && *from.SchoolID != *to.SchoolID {
+		return nil, fmt.Errorf("teachers belong to different schools")
+	}
+	if err := checkTransferSchool(ctx, s.db, from.SchoolID, userID, role); err != nil {
+		return nil, err
+	}
+
+	if req.ClassroomID != nil {
+		var classroom model.Classrooms
+		err := s.db.NewSelect().
+			Model(&classroom).
+			Where("c.id = ?", *req.ClassroomID).
+			Scan(ctx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, fmt.Errorf("classroom not found")
+			}
+			return nil, fmt.Errorf("failed to retrieve classroom: %w", err)
+		}
+		if classroom.TeacherID != fromID {
+			return nil, fmt.Errorf("classroom does not belong to this teacher")
+		}
+	} else {
+		owns, err := s.db.NewSelect().
+			Model((*model.Classrooms)(nil)).
+			Where("teacher_id = ?", fromID).
+			Exists(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check classrooms: %w", err)
+		}
+		if !owns {
+			return nil, fmt.Errorf("teacher has no classrooms to transfer")
+		}
+	}
+
+	pending := s.db.NewSelect().
+		Model((*model.ClassroomTransfers)(nil)).
+		Where("from_teacher_id = ? AND status = 'pending'", fromID)
+	if req.ClassroomID != nil {
+		pending = pending.Where("classroom_id IS NULL OR classroom_id = ?", *req.ClassroomID)
+	}
+	exists, err := pending.Exists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check pending transfers: %w", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("a transfer is already pending")
+	}
+
+	now := time.Now()
+	transfer := &model.ClassroomTransfers{
+		ID:             uuid.New(),
+		SchoolID:       from.SchoolID,
+		ClassroomID:    req.ClassroomID,
+		FromTeacherID:  fromID,
+		ToTeacherID:    req.ToTeacherID,
+		KeepAsObserver: req.KeepAsObserver,
+		Status:         "pending",
+		Reason:         req.Reason,
+		RequestedBy:    userID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(transfer).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to create transfer: %w", err)
+		}
+		if !isAdminRole(role) {
+			return writeAuditLog(ctx, tx, &userID, "classroom.transfer_request", "classroom_transfers", &transfer.ID, nil, transfer, nil)
+		}
+		return applyTransfer(ctx, tx, transfer, userID, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// ReviewTransferService lets a school admin approve (and apply) or reject a pending transfer
+func (s *ClassroomService) ReviewTransferService(ctx context.Context, transferID uuid.UUID, req *requests.ReviewTransferRequest, userID uuid.UUID, role string) (*model.ClassroomTransfers, error) {
+	if !isAdminRole(role) {
+		return nil, fmt.Errorf("only school admins can review transfers")
+	}
+
+	transfer, err := s.getTransfer(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTransferSchool(ctx, s.db, transfer.SchoolID, userID, role); err != nil {
+		return nil, err
+	}
+	if transfer.Status != "pending" {
+		return nil, fmt.Errorf("transfer has already been reviewed")
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if req.Status == "approved" {
+			return applyTransfer(ctx, tx, transfer, userID, req.Note)
+		}
+
+		now := time.Now()
+		transfer.Status = "rejected"
+		transfer.ReviewedBy = &userID
+		transfer.ReviewedAt = &now
+		transfer.ReviewNote = req.Note
+		transfer.UpdatedAt = now
+		_, err := tx.NewUpdate().
+			Model(transfer).
+			Column("status", "reviewed_by", "reviewed_at", "review_note", "updated_at").
+			WherePK().
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to update transfer: %w", err)
+		}
+		return writeAuditLog(ctx, tx, &userID, "classroom.transfer_reject", "classroom_transfers", &transfer.ID, nil, nil, map[string]interface{}{
+			"note": req.Note,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// CancelTransferService withdraws a pending transfer; only the requester or an admin may cancel it
+func (s *ClassroomService) CancelTransferService(ctx context.Context, transferID uuid.UUID, userID uuid.UUID, role string) (*model.ClassroomTransfers, error) {
+	transfer, err := s.getTransfer(ctx, transferID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.RequestedBy != userID {
+		if !isAdminRole(role) {
+			return nil, fmt.Errorf("unauthorized to transfer classrooms")
+		}
+		if err := checkTransferSchool(ctx, s.db, transfer.SchoolID, userID, role); err != nil {
+			return nil, err
+		}
+	}
+	if transfer.Status != "pending" {
+		return nil, fmt.Errorf("transfer has already been reviewed")
+	}
+
+	transfer.Status = "cancelled"
+	transfer.UpdatedAt = time.Now()
+	_, err = s.db.NewUpdate().
+		Model(transfer).
+		Column("status", "updated_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel transfer: %w", err)
+	}
+
+	return transfer, nil
+}
+
+// GetTransfersService lists transfers: admins see their school's, teachers the ones they give or receive
+func (s *ClassroomService) GetTransfersService(ctx context.Context, userID uuid.UUID, role string, status string) ([]*model.ClassroomTransfers, error) {
+	var transfers []*model.ClassroomTransfers
+	query := s.db.NewSelect().
+		Model(&transfers).
+		Relation("Classroom").
+		Relation("FromTeacher").
+		Relation("ToTeacher").
+		Order("ctr.created_at DESC")
+
+	switch role {
+	case "super_admin":
+	case "admin":
+		schoolID, err := userSchoolID(ctx, s.db, userID)
+		if err != nil {
+			return nil, err
+		}
+		if schoolID == nil {
+			return []*model.ClassroomTransfers{}, nil
+		}
+		query = query.Where("ctr.school_id = ?", *schoolID)
+	default:
+		query = query.Where("ctr.from_teacher_id = ? OR ctr.to_teacher_id = ?", userID, userID)
+	}
+
+	switch status {
+	case "":
+	case "pending", "approved", "rejected", "cancelled":
+		query = query.Where("ctr.status = ?", status)
+	default:
+		return nil, fmt.Errorf("invalid transfer status")
+	}
+
+	if err := query.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("failed to retrieve transfers: %w", err)
+	}
+
+	return transfers, nil
+}
+
+// applyTransfer hands the transfer's classrooms to the new teacher. Sessions that have not taken place
+// and assignments that are not yet due follow the classroom; the previous teacher optionally stays on as
+// an observer. Each classroom's change of owner is written to the audit log.
+func applyTransfer(ctx context.Context, tx bun.Tx, transfer *model.ClassroomTransfers, approvedBy uuid.UUID, note *string) error {
+	query := tx.NewSelect().
+		Model((*model.Classrooms)(nil)).
+		Column("id").
+		Where("teacher_id = ?", transfer.FromTeacherID).
+		For("UPDATE")
+	if transfer.ClassroomID != nil {
+		query = query.Where("id = ?", *transfer.ClassroomID)
+	}
+	var classroomIDs []uuid.UUID
+	if err := query.Scan(ctx, &classroomIDs); err != nil {
+		return fmt.Errorf("failed to retrieve classrooms: %w", err)
+	}
+	if len(classroomIDs) == 0 {
+		if transfer.ClassroomID != nil {
+			return fmt.Errorf("classroom does not belong to this teacher")
+		}
+		return fmt.Errorf("teacher has no classrooms to transfer")
+	}
+
+	now := time.Now()
+	today := now.In(schoolLocation).Format("2006-01-02")
+
+	_, err := tx.NewUpdate().
+		Model((*model.Classrooms)(nil)).
+		Set("teacher_id = ?", transfer.ToTeacherID).
+		Set("updated_at = ?", now).
+		Where("id IN (?)", bun.In(classroomIDs)).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to transfer classrooms: %w", err)
+	}
+
+	res, err := tx.NewUpdate().
+		Model((*model.AttendanceSessions)(nil)).
+		Set("created_by = ?", transfer.ToTeacherID).
+		Set("updated_at = ?", now).
+		Where("classroom_id IN (?) AND created_by = ?", bun.In(classroomIDs), transfer.FromTeacherID).
+		Where("session_date >= ? AND status IN ('scheduled', 'active')", today).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to transfer sessions: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil {
+		transfer.SessionsMoved = int(affected)
+	}
+
+	res, err = tx.NewUpdate().
+		Model((*model.Assignments)(nil)).
+		Set("created_by = ?", transfer.ToTeacherID).
+		Set("updated_at = ?", now).
+		Where("classroom_id IN (?) AND created_by = ?", bun.In(classroomIDs), transfer.FromTeacherID).
+		Where("status <> 'archived' AND (due_date IS NULL OR due_date >= ?)", now).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to transfer assignments: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil {
+		transfer.AssignmentsMoved = int(affected)
+	}
+
+	for _, classroomID := range classroomIDs {
+		if err := transferMemberships(ctx, tx, classroomID, transfer, now); err != nil {
+			return err
+		}
+		id := classroomID
+		err := writeAuditLog(ctx, tx, &approvedBy, "classroom.transfer", "classrooms", &id,
+			map[string]interface{}{"teacher_id": transfer.FromTeacherID},
+			map[string]interface{}{"teacher_id": transfer.ToTeacherID},
+			map[string]interface{}{"transfer_id": transfer.ID, "requested_by": transfer.RequestedBy, "keep_as_observer": transfer.KeepAsObserver})
+		if err != nil {
+			return err
+		}
+	}
+
+	transfer.Status = "approved"
+	transfer.ReviewedBy = &approvedBy
+	transfer.ReviewedAt = &now
+	transfer.ReviewNote = note
+	transfer.ClassroomsMoved = len(classroomIDs)
+	transfer.UpdatedAt = now
+	_, err = tx.NewUpdate().
+		Model(transfer).
+		Column("status", "reviewed_by", "reviewed_at", "review_note", "classrooms_moved", "sessions_moved", "assignments_moved", "updated_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update transfer: %w", err)
+	}
+	return nil
+}
+
+// transferMemberships drops the new owner's own membership of a classroom and keeps the previous teacher
+// as an observer or removes them, depending on the transfer
+func transferMemberships(ctx context.Context, tx bun.Tx, classroomID uuid.UUID, transfer *model.ClassroomTransfers, now time.Time) error {
+	leaving := []uuid.UUID{transfer.ToTeacherID}
+	if !transfer.KeepAsObserver {
+		leaving = append(leaving, transfer.FromTeacherID)
+	}
+	_, err := tx.NewUpdate().
+		Model((*model.ClassroomMembers)(nil)).
+		Set("status = 'removed'").
+		Set("left_at = ?", now).
+		Set("updated_at = ?", now).
+		Where("classroom_id = ? AND status = 'active'", classroomID).
+		Where("user_id IN (?)", bun.In(leaving)).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update classroom membership: %w", err)
+	}
+	if !transfer.KeepAsObserver {
+		return nil
+	}
+
+	res, err := tx.NewUpdate().
+		Model((*model.ClassroomMembers)(nil)).
+		Set("role = 'observer'").
+		Set("updated_at = ?", now).
+		Where("classroom_id = ? AND user_id = ? AND status = 'active'", classroomID, transfer.FromTeacherID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update classroom membership: %w", err)
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected > 0 {
+		return nil
+	}
+	_, err = addClassroomMember(ctx, tx, classroomID, transfer.FromTeacherID, "observer")
+	return err
+}
+
+// getTransfer loads a transfer by ID
+func (s *ClassroomService) getTransfer(ctx context.Context, transferID uuid.UUID) (*model.ClassroomTransfers, error) {
+	var transfer model.ClassroomTransfers
+	err := s.db.NewSelect().
+		Model(&transfer).
+		Where("ctr.id = ?", transferID).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("transfer not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve transfer: %w", err)
+	}
+	return &transfer, nil
+}
+
+// loadTransferTeacher loads a user taking part in a transfer
+func loadTransferTeacher(ctx context.Context, db bun.IDB, userID uuid.UUID) (*model.Users, error) {
+	var user model.Users
+	err := db.NewSelect().
+		Model(&user).
+		Where("u.id = ?", userID).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("teacher not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve teacher: %w", err)
+	}
+	return &user, nil
+}
+
+// checkTransferSchool makes sure a school admin only handles transfers within their own school
+func checkTransferSchool(ctx context.Context, db bun.IDB, schoolID *uuid.UUID, userID uuid.UUID, role string) error {
+	if role != "admin" {
+		return nil
+	}
+	userSchool, err := userSchoolID(ctx, db, userID)
+	if err != nil {
+		return err
+	}
+	if userSchool == nil || schoolID == nil || *userSchool != *schoolID {
+		return fmt.Errorf("unauthorized to transfer classrooms")
+	}
+	return nil
+}
@@ -0,0 +1,323 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/uptrace/bun"
+)
+
+// GetWaitlistService lists the students waiting for a seat, in order
+func (s *ClassroomService) GetWaitlistService(ctx context.Context, classroomID uuid.UUID, userID uuid.UUID, role string) ([]*model.ClassroomWaitlist, error) {
+	classroom, err := s.getManagedClassroom(ctx, classroomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*model.ClassroomWaitlist
+	err = s.db.NewSelect().
+		Model(&entries).
+		Relation("Student").
+		Where("cw.classroom_id = ? AND cw.status = 'waiting'", classroom.ID).
+		Order("cw.position ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve waitlist: %w", err)
+	}
+
+	return entries, nil
+}
+
+// ReorderWaitlistService sets the waitlist order; studentIDs must list every waiting student exactly once
+func (s *ClassroomService) ReorderWaitlistService(ctx context.Context, classroomID uuid.UUID, studentIDs []uuid.UUID, userID uuid.UUID, role string) ([]*model.ClassroomWaitlist, error) {
+	classroom, err := s.getManagedClassroom(ctx, classroomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := lockClassroom(ctx, tx, classroom.ID); err != nil {
+			return err
+		}
+
+		var waiting []uuid.UUID
+		err := tx.NewSelect().
+			Model((*model.ClassroomWaitlist)(nil)).
+			Column("student_id").
+			Where("classroom_id = ? AND status = 'waiting'", classroom.ID).
+			Scan(ctx, &waiting)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve waitlist: %w", err)
+		}
+
+		remaining := make(map[uuid.UUID]bool, len(waiting))
+		for _, id := range waiting {
+			remaining[id] = true
+		}
+		if len(studentIDs) != len(waiting) {
+			return fmt.Errorf("student list must match the waitlist")
+		}
+		for _, id := range studentIDs {
+			if !remaining[id] {
+				return fmt.Errorf("student list must match the waitlist")
+			}
+			delete(remaining, id)
+		}
+
+		now := time.Now()
+		for i, id := range studentIDs {
+			_, err := tx.NewUpdate().
+				Model((*model.ClassroomWaitlist)(nil)).
+				Set("position = ?", i+1).
+				Set("updated_at = ?", now).
+				Where("classroom_id = ? AND student_id = ? AND status = 'waiting'", classroom.ID, id).
+				Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to reorder waitlist: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetWaitlistService(ctx, classroomID, userID, role)
+}
+
+// RemoveFromWaitlistService takes one student off the waitlist; students may remove themselves
+func (s *ClassroomService) RemoveFromWaitlistService(ctx context.Context, classroomID uuid.UUID, studentID uuid.UUID, userID uuid.UUID, role string) error {
+	if studentID != userID {
+		if _, err := s.getManagedClassroom(ctx, classroomID, userID, role); err != nil {
+			return err
+		}
+	}
+
+	return s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := lockClassroom(ctx, tx, classroomID); err != nil {
+			return err
+		}
+
+		var entry model.ClassroomWaitlist
+		err := tx.NewSelect().
+			Model(&entry).
+			Where("cw.classroom_id = ? AND cw.student_id = ? AND cw.status = 'waiting'", classroomID, studentID).
+			Scan(ctx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("student is not on the waitlist")
+			}
+			return fmt.Errorf("failed to retrieve waitlist entry: %w", err)
+		}
+
+		return closeWaitlistEntry(ctx, tx, &entry, "removed")
+	})
+}
+
+// ClearWaitlistService removes every waiting student and lets them know
+func (s *ClassroomService) ClearWaitlistService(ctx context.Context, classroomID uuid.UUID, userID uuid.UUID, role string) (int, error) {
+	classroom, err := s.getManagedClassroom(ctx, classroomID, userID, role)
+	if err != nil {
+		return 0, err
+	}
+
+	var studentIDs []uuid.UUID
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := lockClassroom(ctx, tx, classroom.ID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		_, err := tx.NewUpdate().
+			Model((*model.ClassroomWaitlist)(nil)).
+			Set("status = 'removed'").
+			Set("removed_at = ?", now).
+			Set("updated_at = ?", now).
+			Where("classroom_id = ? AND status = 'waiting'", classroom.ID).
+			Returning("student_id").
+			Exec(ctx, &studentIDs)
+		if err != nil {
+			return fmt.Errorf("failed to clear waitlist: %w", err)
+		}
+
+		message := fmt.Sprintf("The waitlist for %s has been closed.", classroom.Name)
+		return notifyUsers(ctx, tx, studentIDs, "info", "Waitlist closed", message, "classroom", &classroom.ID, nil)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(studentIDs), nil
+}
+
+// joinWaitlist queues a student at the end of a classroom's waitlist
+func (s *ClassroomService) joinWaitlist(ctx context.Context, classroom *model.Classrooms, studentID uuid.UUID) (*model.ClassroomWaitlist, error) {
+	var entry *model.ClassroomWaitlist
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := lockClassroom(ctx, tx, classroom.ID); err != nil {
+			return err
+		}
+
+		var err error
+		entry, err = enqueueWaitlist(ctx, tx, classroom.ID, studentID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// enqueueWaitlist appends a student to the waitlist; callers hold the classroom lock
+func enqueueWaitlist(ctx context.Context, db bun.IDB, classroomID uuid.UUID, studentID uuid.UUID) (*model.ClassroomWaitlist, error) {
+	waiting, err := db.NewSelect().
+		Model((*model.ClassroomWaitlist)(nil)).
+		Where("classroom_id = ? AND student_id = ? AND status = 'waiting'", classroomID, studentID).
+		Exists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check waitlist: %w", err)
+	}
+	if waiting {
+		return nil, fmt.Errorf("already on the waitlist")
+	}
+
+	var lastPosition int
+	err = db.NewSelect().
+		Model((*model.ClassroomWaitlist)(nil)).
+		ColumnExpr("COALESCE(MAX(position), 0)").
+		Where("classroom_id = ? AND status = 'waiting'", classroomID).
+		Scan(ctx, &lastPosition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve waitlist position: %w", err)
+	}
+
+	now := time.Now()
+	entry := &model.ClassroomWaitlist{
+		ID:          uuid.New(),
+		ClassroomID: classroomID,
+		StudentID:   studentID,
+		Position:    lastPosition + 1,
+		Status:      "waiting",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if _, err := db.NewInsert().Model(entry).Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to join waitlist: %w", err)
+	}
+
+	return entry, nil
+}
+
+// hasWaitlist reports whether anyone is waiting for a seat, so new joiners do not jump the queue
+func hasWaitlist(ctx context.Context, db bun.IDB, classroomID uuid.UUID) (bool, error) {
+	waiting, err := db.NewSelect().
+		Model((*model.ClassroomWaitlist)(nil)).
+		Where("classroom_id = ? AND status = 'waiting'", classroomID).
+		Exists(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to check waitlist: %w", err)
+	}
+	return waiting, nil
+}
+
+// promoteFromWaitlist enrolls waiting students in order while the classroom has free seats
+func promoteFromWaitlist(ctx context.Context, db bun.IDB, classroomID uuid.UUID) error {
+	var classroom model.Classrooms
+	err := db.NewSelect().
+		Model(&classroom).
+		Where("c.id = ?", classroomID).
+		For("UPDATE").
+		Scan(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve classroom: %w", err)
+	}
+	if !classroom.IsActive {
+		return nil
+	}
+
+	for {
+		var entry model.ClassroomWaitlist
+		err := db.NewSelect().
+			Model(&entry).
+			Where("cw.classroom_id = ? AND cw.status = 'waiting'", classroomID).
+			Order("cw.position ASC").
+			Limit(1).
+			Scan(ctx)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to retrieve waitlist: %w", err)
+		}
+
+		_, err = enrollStudent(ctx, db, classroomID, entry.StudentID)
+		if err != nil && err.Error() == "classroom is full" {
+			return nil
+		}
+		// A teacher may have enrolled the student directly in the meantime
+		if err != nil && err.Error() != "already enrolled in this classroom" {
+			return err
+		}
+
+		if err := closeWaitlistEntry(ctx, db, &entry, "enrolled"); err != nil {
+			return err
+		}
+
+		message := fmt.Sprintf("A seat opened up and you have been enrolled in %s.", classroom.Name)
+		if err := notifyUsers(ctx, db, []uuid.UUID{entry.StudentID}, "success", "Enrolled from waitlist", message,
+			"classroom", &classroom.ID, nil); err != nil {
+			return err
+		}
+	}
+}
+
+// closeWaitlistEntry marks an entry enrolled or removed and moves everyone behind it up one place
+func closeWaitlistEntry(ctx context.Context, db bun.IDB, entry *model.ClassroomWaitlist, status string) error {
+	now := time.Now()
+	query := db.NewUpdate().
+		Model((*model.ClassroomWaitlist)(nil)).
+		Set("status = ?", status).
+		Set("updated_at = ?", now).
+		Where("id = ?", entry.ID)
+	if status == "enrolled" {
+		query = query.Set("enrolled_at = ?", now)
+	} else {
+		query = query.Set("removed_at = ?", now)
+	}
+	if _, err := query.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to update waitlist entry: %w", err)
+	}
+
+	_, err := db.NewUpdate().
+		Model((*model.ClassroomWaitlist)(nil)).
+		Set("position = position - 1").
+		Where("classroom_id = ? AND status = 'waiting' AND position > ?", entry.ClassroomID, entry.Position).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update waitlist positions: %w", err)
+	}
+	return nil
+}
+
+// lockClassroom takes a row lock on the classroom to serialize seat and waitlist changes
+func lockClassroom(ctx context.Context, db bun.IDB, classroomID uuid.UUID) error {
+	var id uuid.UUID
+	err := db.NewSelect().
+		Model((*model.Classrooms)(nil)).
+		Column("id").
+		Where("id = ?", classroomID).
+		For("UPDATE").
+		Scan(ctx, &id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("classroom not found")
+		}
+		return fmt.Errorf("failed to lock classroom: %w", err)
+	}
+	return nil
+}
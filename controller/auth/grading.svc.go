@@ -0,0 +1,270 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/uptrace/bun"
+)
+
+// GetSubmissionsService lists the handed-in submissions of an assignment for its teacher; status narrows
+// the list to submitted, graded or returned
+func (s *SubmissionService) GetSubmissionsService(ctx context.Context, assignmentID uuid.UUID, status string, userID uuid.UUID, role string) ([]*model.AssignmentSubmissions, error) {
+	if _, err := loadGradableAssignment(ctx, s.db, assignmentID, userID, role); err != nil {
+		return nil, err
+	}
+
+	var submissions []*model.AssignmentSubmissions
+	query := s.db.NewSelect().
+		Model(&submissions).
+		Relation("Student").
+		Where("asub.assignment_id = ?", assignmentID).
+		Where("asub.status <> 'draft'").
+		Order("asub.submitted_at ASC")
+	if status != "" {
+		query = query.Where("asub.status = ?", status)
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("failed to retrieve submissions: %w", err)
+	}
+
+	return submissions, nil
+}
+
+// GradeSubmissionService scores one submission and moves it to graded. A submission that was already
+// returned stays returned, so the student sees the corrected grade straight away.
+func (s *SubmissionService) GradeSubmissionService(ctx context.Context, submissionID uuid.UUID, req *requests.GradeSubmissionRequest, graderID uuid.UUID, role string) (*model.AssignmentSubmissions, error) {
+	var submission model.AssignmentSubmissions
+	err := s.db.NewSelect().
+		Model(&submission).
+		Where("asub.id = ?", submissionID).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("submission not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve submission: %w", err)
+	}
+
+	assignment, err := loadGradableAssignment(ctx, s.db, submission.AssignmentID, graderID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		return gradeSubmission(ctx, tx, assignment, &submission, *req.Score, req.Feedback, graderID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &submission, nil
+}
+
+// BatchGradeService grades many submissions of one assignment. Either every grade is saved or none is.
+func (s *SubmissionService) BatchGradeService(ctx context.Context, assignmentID uuid.UUID, req *requests.BatchGradeRequest, graderID uuid.UUID, role string) ([]*model.AssignmentSubmissions, error) {
+	assignment, err := loadGradableAssignment(ctx, s.db, assignmentID, graderID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(req.Grades))
+	seen := make(map[uuid.UUID]bool, len(req.Grades))
+	for _, item := range req.Grades {
+		if seen[item.SubmissionID] {
+			return nil, fmt.Errorf("submission is listed more than once")
+		}
+		seen[item.SubmissionID] = true
+		ids = append(ids, item.SubmissionID)
+	}
+
+	var submissions []*model.AssignmentSubmissions
+	err = s.db.NewSelect().
+		Model(&submissions).
+		Where("asub.id IN (?)", bun.In(ids)).
+		Where("asub.assignment_id = ?", assignmentID).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve submissions: %w", err)
+	}
+	if len(submissions) != len(ids) {
+		return nil, fmt.Errorf("submission not found")
+	}
+	byID := make(map[uuid.UUID]*model.AssignmentSubmissions, len(submissions))
+	for _, submission := range submissions {
+		byID[submission.ID] = submission
+	}
+
+	graded := make([]*model.AssignmentSubmissions, 0, len(req.Grades))
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		for _, item := range req.Grades {
+			submission := byID[item.SubmissionID]
+			if err := gradeSubmission(ctx, tx, assignment, submission, *item.Score, item.Feedback, graderID); err != nil {
+				return err
+			}
+			graded = append(graded, submission)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return graded, nil
+}
+
+// ReturnSubmissionsService releases graded submissions to their students. Without IDs every graded
+// submission of the assignment is returned.
+func (s *SubmissionService) ReturnSubmissionsService(ctx context.Context, assignmentID uuid.UUID, req *requests.ReturnSubmissionsRequest, userID uuid.UUID, role string) ([]*model.AssignmentSubmissions, error) {
+	if _, err := loadGradableAssignment(ctx, s.db, assignmentID, userID, role); err != nil {
+		return nil, err
+	}
+
+	var submissions []*model.AssignmentSubmissions
+	query := s.db.NewSelect().
+		Model(&submissions).
+		Where("asub.assignment_id = ?", assignmentID)
+	if len(req.SubmissionIDs) > 0 {
+		query = query.Where("asub.id IN (?)", bun.In(req.SubmissionIDs))
+	} else {
+		query = query.Where("asub.status = 'graded'")
+	}
+	if err := query.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("failed to retrieve submissions: %w", err)
+	}
+	if len(submissions) < len(req.SubmissionIDs) {
+		return nil, fmt.Errorf("submission not found")
+	}
+	for _, submission := range submissions {
+		if submission.Status != "graded" && submission.Status != "returned" {
+			return nil, fmt.Errorf("submission has not been graded")
+		}
+	}
+
+	now := time.Now()
+	returned := make([]*model.AssignmentSubmissions, 0, len(submissions))
+	ids := make([]uuid.UUID, 0, len(submissions))
+	for _, submission := range submissions {
+		if submission.Status == "graded" {
+			submission.Status = "returned"
+			submission.ReturnedAt = &now
+			submission.UpdatedAt = now
+			returned = append(returned, submission)
+			ids = append(ids, submission.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return returned, nil
+	}
+
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().
+			Model((*model.AssignmentSubmissions)(nil)).
+			Set("status = 'returned'").
+			Set("returned_at = ?", now).
+			Set("updated_at = ?", now).
+			Where("id IN (?)", bun.In(ids)).
+			Where("status = 'graded'").
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to return submissions: %w", err)
+		}
+		return writeAuditLog(ctx, tx, &userID, "submission.return", "assignment_submissions", nil, nil, nil,
+			map[string]any{"assignment_id": assignmentID, "submission_ids": ids})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return returned, nil
+}
+
+// gradeSubmission validates the score against the assignment and saves both the raw score and the
+// score after the late penalty
+func gradeSubmission(ctx context.Context, tx bun.Tx, assignment *model.Assignments, submission *model.AssignmentSubmissions, score float64, feedback *string, graderID uuid.UUID) error {
+	if submission.Status == "draft" {
+		return fmt.Errorf("submission has not been submitted")
+	}
+	if score > assignment.MaxScore {
+		return fmt.Errorf("score exceeds the assignment's max score")
+	}
+
+	old := *submission
+	adjusted := score
+	if submission.IsLate && assignment.LatePenaltyPercent > 0 {
+		// Penalties stored before the 0-100 check existed could otherwise push the grade below zero
+		adjusted = math.Max(0, math.Round(score*(100-assignment.LatePenaltyPercent))/100)
+	}
+
+	now := time.Now()
+	submission.RawScore = &score
+	submission.Score = &adjusted
+	submission.Feedback = feedback
+	submission.GradedBy = &graderID
+	submission.GradedAt = &now
+	submission.UpdatedAt = now
+	if submission.Status == "submitted" {
+		submission.Status = "graded"
+	}
+
+	_, err := tx.NewUpdate().
+		Model(submission).
+		Column("raw_score", "score", "feedback", "graded_by", "graded_at", "status", "updated_at").
+		Where("id = ?", submission.ID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to grade submission: %w", err)
+	}
+
+	return writeAuditLog(ctx, tx, &graderID, "submission.grade", "assignment_submissions", &submission.ID,
+		map[string]any{"raw_score": old.RawScore, "score": old.Score, "status": old.Status},
+		map[string]any{"raw_score": submission.RawScore, "score": submission.Score, "status": submission.Status}, nil)
+}
+
+// loadGradableAssignment returns an assignment the user may grade
+func loadGradableAssignment(ctx context.Context, db bun.IDB, assignmentID, userID uuid.UUID, role string) (*model.Assignments, error) {
+	assignment, err := loadAssignmentWithClassroom(ctx, db, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := canManageAssignment(ctx, db, assignment, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("unauthorized to grade this assignment")
+	}
+	return assignment, nil
+}
+
+// canManageAssignment reports whether the user manages the assignment's classroom, or created the
+// assignment and still teaches or assists in the classroom; a teacher who transferred the classroom away
+// loses it. The assignment must be loaded with its Classroom relation.
+func canManageAssignment(ctx context.Context, db bun.IDB, assignment *model.Assignments, userID uuid.UUID, role string) (bool, error) {
+	if assignment.Classroom == nil {
+		return false, nil
+	}
+	allowed, err := canManageClassroom(ctx, db, assignment.Classroom, userID, role)
+	if err != nil || allowed || assignment.CreatedBy != userID {
+		return allowed, err
+	}
+	return isClassroomStaff(ctx, db, assignment.ClassroomID, userID)
+}
+
+// hideUnreturnedGrade clears the grade from a submission the teacher has not returned yet
+func hideUnreturnedGrade(submission *model.AssignmentSubmissions) {
+	if submission.Status == "returned" {
+		return
+	}
+	submission.RawScore = nil
+	submission.Score = nil
+	submission.Feedback = nil
+	submission.GradedBy = nil
+	submission.GradedAt = nil
+}
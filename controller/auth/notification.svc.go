@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/uptrace/bun"
+)
+
+// notifyUsers creates the same in-app notification for every user in userIDs
+func notifyUsers(ctx context.Context, db bun.IDB, userIDs []uuid.UUID, notificationType, title, message string, referenceType string, referenceID *uuid.UUID, data any) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	notifications := make([]*model.Notifications, 0, len(userIDs))
+	for _, userID := range userIDs {
+		notification := &model.Notifications{
+			ID:              uuid.New(),
+			UserID:          userID,
+			Type:            notificationType,
+			Title:           title,
+			Message:         message,
+			Data:            toJSONString(data),
+			ReferenceID:     referenceID,
+			SentAt:          &now,
+			DeliveryStatus:  "sent",
+			DeliveryChannel: "in_app",
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+		if referenceType != "" {
+			refType := referenceType
+			notification.ReferenceType = &refType
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if _, err := db.NewInsert().Model(&notifications).Exec(ctx); err != nil {
+		return fmt.Errorf("failed to create notifications: %w", err)
+	}
+	return nil
+}
+
+// activeStudentIDs returns the students currently enrolled in a classroom
+func activeStudentIDs(ctx context.Context, db bun.IDB, classroomID uuid.UUID) ([]uuid.UUID, error) {
+	var studentIDs []uuid.UUID
+	err := db.NewSelect().
+		Model((*model.ClassroomStudents)(nil)).
+		Column("student_id").
+		Where("classroom_id = ? AND is_active = true", classroomID).
+		Scan(ctx, &studentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve classroom students: %w", err)
+	}
+	return studentIDs, nil
+}
@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/komkem01/easy-attend-service/response"
+	"github.com/uptrace/bun"
+)
+
+// PromotionController handles end-of-year promotion endpoints
+type PromotionController struct {
+	promotionService *PromotionService
+}
+
+// NewPromotionController creates a new promotion controller
+func NewPromotionController(db *bun.DB) *PromotionController {
+	return &PromotionController{
+		promotionService: NewPromotionService(db),
+	}
+}
+
+// PromoteStudents moves the mapped grade levels up a year; dry_run previews the report
+func (ctrl *PromotionController) PromoteStudents(c *gin.Context) {
+	var req requests.PromoteStudentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	report, err := ctrl.promotionService.PromoteStudentsService(c.Request.Context(), &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondPromotionError(c, err, "Failed to promote students")
+		return
+	}
+
+	switch {
+	case report.DryRun:
+		response.Success(c, report)
+	case len(report.Errors) > 0:
+		response.UnprocessableEntity(c, "Target classrooms do not have enough seats; nothing was changed", report)
+	case !report.Committed:
+		response.Success(c, report)
+	default:
+		response.Created(c, report)
+	}
+}
+
+// GetPromotions lists past promotions; ?school_id defaults to the user's school
+func (ctrl *PromotionController) GetPromotions(c *gin.Context) {
+	schoolID, ok := parseSchoolIDQuery(c)
+	if !ok {
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	promotions, err := ctrl.promotionService.GetPromotionsService(c.Request.Context(), schoolID, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondPromotionError(c, err, "Failed to retrieve promotions")
+		return
+	}
+
+	response.Success(c, promotions)
+}
+
+// GetPromotion returns a promotion with the changes it made
+func (ctrl *PromotionController) GetPromotion(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid promotion ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	promotion, err := ctrl.promotionService.GetPromotionService(c.Request.Context(), id, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondPromotionError(c, err, "Failed to retrieve promotion")
+		return
+	}
+
+	response.Success(c, promotion)
+}
+
+// RevertPromotion undoes a promotion within its grace period
+func (ctrl *PromotionController) RevertPromotion(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid promotion ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	promotion, err := ctrl.promotionService.RevertPromotionService(c.Request.Context(), id, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondPromotionError(c, err, "Failed to revert promotion")
+		return
+	}
+
+	response.Success(c, promotion)
+}
+
+// respondPromotionError maps promotion service errors to HTTP responses
+func respondPromotionError(c *gin.Context, err error, fallback string) {
+	switch err.Error() {
+	case "promotion not found", "target classroom not found":
+		response.NotFound(c, err.Error())
+	case "unauthorized to promote students":
+		response.Forbidden(c, err.Error())
+	case "promotion is already reverted", "promotion can no longer be reverted":
+		response.Conflict(c, err.Error())
+	case "school_id is required", "each mapping needs either target classrooms or graduate", "mappings overlap",
+		"target classroom belongs to another school", "target classroom is inactive":
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback+": "+err.Error())
+	}
+}
@@ -0,0 +1,590 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/uptrace/bun"
+)
+
+// promotionRevertDays is how long a promotion can still be reverted
+const promotionRevertDays = 30
+
+// PromotionService moves students up a grade level at the end of an academic year
+type PromotionService struct {
+	db *bun.DB
+}
+
+// NewPromotionService creates a new promotion service
+func NewPromotionService(db *bun.DB) *PromotionService {
+	return &PromotionService{db: db}
+}
+
+// PromotionClassroom names a classroom in a promotion report
+type PromotionClassroom struct {
+	ClassroomID uuid.UUID `json:"classroom_id"`
+	Name        string    `json:"name"`
+	Note        string    `json:"note,omitempty"`
+}
+
+// PromotionStudent is the outcome for one student
+type PromotionStudent struct {
+	StudentID  uuid.UUID             `json:"student_id"`
+	Name       string                `json:"name"`
+	GradeLevel string                `json:"grade_level"`
+	Section    string                `json:"section,omitempty"`
+	Action     string                `json:"action"` // promote, graduate or skip
+	Note       string                `json:"note,omitempty"`
+	Ended      []*PromotionClassroom `json:"ended_enrollments"`
+	Enrolled   []*PromotionClassroom `json:"new_enrollments,omitempty"`
+
+	enrollments []*model.ClassroomStudents
+	wasActive   bool
+}
+
+// PromotionReport summarizes a promotion or its preview
+type PromotionReport struct {
+	DryRun    bool                     `json:"dry_run"`
+	Committed bool                     `json:"committed"`
+	Promotion *model.StudentPromotions `json:"promotion,omitempty"`
+	Promoted  int                      `json:"promoted"`
+	Graduated int                      `json:"graduated"`
+	Skipped   int                      `json:"skipped"`
+	Errors    []string                 `json:"errors,omitempty"`
+	Students  []*PromotionStudent      `json:"students"`
+}
+
+// PromoteStudentsService ends the current enrollments of every student in the mapped grade levels and
+// enrolls them into their next-year classrooms, or marks them as alumni and deactivates their accounts.
+// Nothing is changed when a target classroom would overflow. Each change is recorded so the promotion can
+// be reverted for promotionRevertDays days.
+func (s *PromotionService) PromoteStudentsService(ctx context.Context, req *requests.PromoteStudentsRequest, userID uuid.UUID, role string) (*PromotionReport, error) {
+	schoolID, err := promotionSchool(ctx, s.db, req.SchoolID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validatePromotionMappings(req.Mappings); err != nil {
+		return nil, err
+	}
+
+	targets, err := s.promotionTargets(ctx, schoolID, req.Mappings)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &PromotionReport{DryRun: req.DryRun}
+	students := make(map[uuid.UUID]*PromotionStudent)
+	mappingOf := make(map[uuid.UUID]int)
+	for i := range req.Mappings {
+		enrollments, err := s.promotionEnrollments(ctx, schoolID, &req.Mappings[i], targets)
+		if err != nil {
+			return nil, err
+		}
+		for _, enrollment := range enrollments {
+			student, ok := students[enrollment.StudentID]
+			if !ok {
+				student = &PromotionStudent{
+					StudentID:  enrollment.StudentID,
+					Name:       enrollment.Student.FirstName + " " + enrollment.Student.LastName,
+					GradeLevel: req.Mappings[i].FromGradeLevel,
+					wasActive:  enrollment.Student.IsActive,
+				}
+				if enrollment.Classroom.Section != nil {
+					student.Section = *enrollment.Classroom.Section
+				}
+				students[enrollment.StudentID] = student
+				mappingOf[enrollment.StudentID] = i
+			} else if mappingOf[enrollment.StudentID] != i {
+				student.Action = "skip"
+				student.Note = "student is enrolled in more than one promoted grade level"
+			}
+			student.enrollments = append(student.enrollments, enrollment)
+			student.Ended = append(student.Ended, &PromotionClassroom{ClassroomID: enrollment.ClassroomID, Name: enrollment.Classroom.Name})
+		}
+	}
+
+	seats := make(map[uuid.UUID]int)
+	for _, student := range students {
+		mapping := req.Mappings[mappingOf[student.StudentID]]
+		switch {
+		case student.Action == "skip":
+			student.Ended = nil
+			report.Skipped++
+		case mapping.Graduate:
+			student.Action = "graduate"
+			report.Graduated++
+		default:
+			student.Action = "promote"
+			for _, classroomID := range mapping.ToClassroomIDs {
+				target := targets[classroomID]
+				entry := &PromotionClassroom{ClassroomID: target.ID, Name: target.Name}
+				enrolled, err := s.db.NewSelect().
+					Model((*model.ClassroomStudents)(nil)).
+					Where("classroom_id = ? AND student_id = ? AND is_active = true", target.ID, student.StudentID).
+					Exists(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("failed to check enrollment: %w", err)
+				}
+				if enrolled {
+					entry.Note = "already enrolled"
+				} else {
+					seats[target.ID]++
+				}
+				student.Enrolled = append(student.Enrolled, entry)
+			}
+			report.Promoted++
+		}
+		report.Students = append(report.Students, student)
+	}
+
+	sort.Slice(report.Students, func(i, j int) bool {
+		a, b := report.Students[i], report.Students[j]
+		if a.GradeLevel != b.GradeLevel {
+			return a.GradeLevel < b.GradeLevel
+		}
+		if a.Section != b.Section {
+			return a.Section < b.Section
+		}
+		return a.Name < b.Name
+	})
+
+	for classroomID, needed := range seats {
+		target := targets[classroomID]
+		active, err := s.db.NewSelect().
+			Model((*model.ClassroomStudents)(nil)).
+			Where("classroom_id = ? AND is_active = true", classroomID).
+			Count(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count classroom students: %w", err)
+		}
+		if active+needed > target.MaxStudents {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s needs %d seats but has %d of %d free",
+				target.Name, needed, target.MaxStudents-active, target.MaxStudents))
+		}
+	}
+	sort.Strings(report.Errors)
+
+	if req.DryRun || len(report.Errors) > 0 || report.Promoted+report.Graduated == 0 {
+		return report, nil
+	}
+
+	now := time.Now()
+	promotion := &model.StudentPromotions{
+		ID:              uuid.New(),
+		SchoolID:        schoolID,
+		PerformedBy:     userID,
+		Promoted:        report.Promoted,
+		Graduated:       report.Graduated,
+		Skipped:         report.Skipped,
+		RevertibleUntil: now.AddDate(0, 0, promotionRevertDays),
+		CreatedAt:       now,
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(promotion).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to record promotion: %w", err)
+		}
+
+		var items []*model.StudentPromotionItems
+		var vacated []uuid.UUID
+		seen := make(map[uuid.UUID]bool)
+		for _, student := range report.Students {
+			if student.Action == "skip" {
+				continue
+			}
+			studentItems, err := applyPromotion(ctx, tx, promotion, student, now)
+			if err != nil {
+				return err
+			}
+			items = append(items, studentItems...)
+			for _, enrollment := range student.enrollments {
+				if !seen[enrollment.ClassroomID] {
+					seen[enrollment.ClassroomID] = true
+					vacated = append(vacated, enrollment.ClassroomID)
+				}
+			}
+		}
+		if len(items) > 0 {
+			if _, err := tx.NewInsert().Model(&items).Exec(ctx); err != nil {
+				return fmt.Errorf("failed to record promotion items: %w", err)
+			}
+		}
+
+		// Offer the seats the promoted students left to whoever is waiting for them
+		for _, classroomID := range vacated {
+			if err := promoteFromWaitlist(ctx, tx, classroomID); err != nil {
+				return err
+			}
+		}
+
+		return writeAuditLog(ctx, tx, &userID, "student.promotion", "student_promotions", &promotion.ID, nil, promotion, map[string]interface{}{
+			"mappings": req.Mappings,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.Promotion = promotion
+	report.Committed = true
+	return report, nil
+}
+
+// applyPromotion ends one student's enrollments and enrolls or graduates them, returning what it changed
+func applyPromotion(ctx context.Context, tx bun.Tx, promotion *model.StudentPromotions, student *PromotionStudent, now time.Time) ([]*model.StudentPromotionItems, error) {
+	item := func(action string, classroomID, enrollmentID *uuid.UUID) *model.StudentPromotionItems {
+		return &model.StudentPromotionItems{
+			ID:           uuid.New(),
+			PromotionID:  promotion.ID,
+			StudentID:    student.StudentID,
+			Action:       action,
+			ClassroomID:  classroomID,
+			EnrollmentID: enrollmentID,
+			WasActive:    student.wasActive,
+			CreatedAt:    now,
+		}
+	}
+
+	var items []*model.StudentPromotionItems
+	for _, enrollment := range student.enrollments {
+		if err := deactivateEnrollment(ctx, tx, enrollment); err != nil {
+			return nil, err
+		}
+		items = append(items, item("end_enrollment", &enrollment.ClassroomID, &enrollment.ID))
+	}
+
+	if student.Action == "graduate" {
+		_, err := tx.NewUpdate().
+			Model((*model.Users)(nil)).
+			Set("is_active = false").
+			Set("graduated_at = ?", now).
+			Set("updated_at = ?", now).
+			Where("id = ?", student.StudentID).
+			Exec(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to graduate student: %w", err)
+		}
+		return append(items, item("graduate", nil, nil)), nil
+	}
+
+	for _, target := range student.Enrolled {
+		if target.Note != "" {
+			continue
+		}
+		existed, err := tx.NewSelect().
+			Model((*model.ClassroomStudents)(nil)).
+			Where("classroom_id = ? AND student_id = ?", target.ClassroomID, student.StudentID).
+			Exists(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check enrollment: %w", err)
+		}
+		enrollment, err := enrollStudent(ctx, tx, target.ClassroomID, student.StudentID)
+		if err != nil {
+			if err.Error() == "classroom is full" {
+				return nil, fmt.Errorf("classroom %s is full", target.Name)
+			}
+			return nil, err
+		}
+		enrolled := item("enroll", &target.ClassroomID, &enrollment.ID)
+		enrolled.CreatedEnrollment = !existed
+		items = append(items, enrolled)
+	}
+	return items, nil
+}
+
+// GetPromotionsService lists a school's promotions, newest first
+func (s *PromotionService) GetPromotionsService(ctx context.Context, schoolID *uuid.UUID, userID uuid.UUID, role string) ([]*model.StudentPromotions, error) {
+	school, err := promotionSchool(ctx, s.db, schoolID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	var promotions []*model.StudentPromotions
+	err = s.db.NewSelect().
+		Model(&promotions).
+		Relation("Performer").
+		Where("spr.school_id = ?", school).
+		Order("spr.created_at DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve promotions: %w", err)
+	}
+
+	return promotions, nil
+}
+
+// GetPromotionService returns a promotion with every change it made
+func (s *PromotionService) GetPromotionService(ctx context.Context, id uuid.UUID, userID uuid.UUID, role string) (*model.StudentPromotions, error) {
+	var promotion model.StudentPromotions
+	err := s.db.NewSelect().
+		Model(&promotion).
+		Relation("Performer").
+		Relation("Items", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("spi.student_id ASC", "spi.created_at ASC")
+		}).
+		Relation("Items.Student").
+		Relation("Items.Classroom").
+		Where("spr.id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("promotion not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve promotion: %w", err)
+	}
+
+	if _, err := promotionSchool(ctx, s.db, &promotion.SchoolID, userID, role); err != nil {
+		return nil, err
+	}
+
+	return &promotion, nil
+}
+
+// RevertPromotionService undoes a promotion within its grace period: the new enrollments are removed, the
+// ended ones restored (or waitlisted when the classroom has filled up since) and graduated accounts returned
+// to their earlier state
+func (s *PromotionService) RevertPromotionService(ctx context.Context, id uuid.UUID, userID uuid.UUID, role string) (*model.StudentPromotions, error) {
+	promotion, err := s.GetPromotionService(ctx, id, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if promotion.RevertedAt != nil {
+		return nil, fmt.Errorf("promotion is already reverted")
+	}
+	now := time.Now()
+	if now.After(promotion.RevertibleUntil) {
+		return nil, fmt.Errorf("promotion can no longer be reverted")
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		var vacated []uuid.UUID
+		seen := make(map[uuid.UUID]bool)
+		for _, item := range promotion.Items {
+			if err := revertPromotionItem(ctx, tx, promotion, item, now); err != nil {
+				return err
+			}
+			if item.Action == "enroll" && item.ClassroomID != nil && !seen[*item.ClassroomID] {
+				seen[*item.ClassroomID] = true
+				vacated = append(vacated, *item.ClassroomID)
+			}
+		}
+		for _, classroomID := range vacated {
+			if err := promoteFromWaitlist(ctx, tx, classroomID); err != nil {
+				return err
+			}
+		}
+
+		promotion.RevertedAt = &now
+		promotion.RevertedBy = &userID
+		_, err := tx.NewUpdate().
+			Model(promotion).
+			Column("reverted_at", "reverted_by").
+			WherePK().
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to revert promotion: %w", err)
+		}
+
+		return writeAuditLog(ctx, tx, &userID, "student.promotion_revert", "student_promotions", &promotion.ID, nil, nil, map[string]interface{}{
+			"items": len(promotion.Items),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return promotion, nil
+}
+
+// revertPromotionItem undoes one recorded change
+func revertPromotionItem(ctx context.Context, tx bun.Tx, promotion *model.StudentPromotions, item *model.StudentPromotionItems, now time.Time) error {
+	switch item.Action {
+	case "end_enrollment":
+		// The freed seat may have gone to the waitlist since, so the student goes through the same capacity
+		// check as any enrollment and queues again when the classroom is full
+		_, err := enrollStudent(ctx, tx, *item.ClassroomID, item.StudentID)
+		switch {
+		case err == nil:
+		case err.Error() == "classroom is full":
+			if _, err := enqueueWaitlist(ctx, tx, *item.ClassroomID, item.StudentID); err != nil && err.Error() != "already on the waitlist" {
+				return err
+			}
+			return nil
+		case err.Error() == "already enrolled in this classroom", err.Error() == "classroom not found":
+			return nil
+		default:
+			return err
+		}
+		_, err = tx.NewUpdate().
+			Model((*model.ClassroomMembers)(nil)).
+			Set("status = 'active'").
+			Set("left_at = NULL").
+			Set("updated_at = ?", now).
+			Where("classroom_id = ? AND user_id = ? AND status = 'inactive' AND left_at >= ?", item.ClassroomID, item.StudentID, promotion.CreatedAt).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to restore classroom membership: %w", err)
+		}
+
+	case "enroll":
+		if item.CreatedEnrollment {
+			_, err := tx.NewDelete().
+				Model((*model.ClassroomStudents)(nil)).
+				Where("id = ?", item.EnrollmentID).
+				ForceDelete().
+				Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to remove enrollment: %w", err)
+			}
+			return nil
+		}
+		_, err := tx.NewUpdate().
+			Model((*model.ClassroomStudents)(nil)).
+			Set("is_active = false").
+			Where("id = ?", item.EnrollmentID).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to remove enrollment: %w", err)
+		}
+
+	case "graduate":
+		_, err := tx.NewUpdate().
+			Model((*model.Users)(nil)).
+			Set("is_active = ?", item.WasActive).
+			Set("graduated_at = NULL").
+			Set("updated_at = ?", now).
+			Where("id = ?", item.StudentID).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to restore student account: %w", err)
+		}
+	}
+	return nil
+}
+
+// promotionTargets loads the classrooms students are promoted into, keyed by ID
+func (s *PromotionService) promotionTargets(ctx context.Context, schoolID uuid.UUID, mappings []requests.PromotionMapping) (map[uuid.UUID]*model.Classrooms, error) {
+	var ids []uuid.UUID
+	for _, mapping := range mappings {
+		ids = append(ids, mapping.ToClassroomIDs...)
+	}
+	targets := make(map[uuid.UUID]*model.Classrooms)
+	if len(ids) == 0 {
+		return targets, nil
+	}
+
+	var classrooms []*model.Classrooms
+	err := s.db.NewSelect().
+		Model(&classrooms).
+		Where("c.id IN (?)", bun.In(ids)).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve target classrooms: %w", err)
+	}
+	for _, classroom := range classrooms {
+		targets[classroom.ID] = classroom
+	}
+
+	for _, id := range ids {
+		classroom, ok := targets[id]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("target classroom not found")
+		case classroom.SchoolID == nil || *classroom.SchoolID != schoolID:
+			return nil, fmt.Errorf("target classroom belongs to another school")
+		case !classroom.IsActive:
+			return nil, fmt.Errorf("target classroom is inactive")
+		}
+	}
+	return targets, nil
+}
+
+// promotionEnrollments returns the active enrollments in the classrooms a mapping promotes from: the
+// school's active classrooms of that grade level (and section) that are not themselves targets and do not
+// belong to a term that has yet to start
+func (s *PromotionService) promotionEnrollments(ctx context.Context, schoolID uuid.UUID, mapping *requests.PromotionMapping, targets map[uuid.UUID]*model.Classrooms) ([]*model.ClassroomStudents, error) {
+	today := time.Now().In(schoolLocation).Format("2006-01-02")
+	started := s.db.NewSelect().
+		Model((*model.Terms)(nil)).
+		Column("id").
+		Where("start_date <= ?", today)
+
+	classrooms := s.db.NewSelect().
+		Model((*model.Classrooms)(nil)).
+		Column("id").
+		Where("school_id = ? AND is_active = true AND grade_level = ?", schoolID, mapping.FromGradeLevel).
+		Where("term_id IS NULL OR term_id IN (?)", started)
+	if mapping.FromSection != nil {
+		classrooms = classrooms.Where("section = ?", *mapping.FromSection)
+	}
+	if len(targets) > 0 {
+		ids := make([]uuid.UUID, 0, len(targets))
+		for id := range targets {
+			ids = append(ids, id)
+		}
+		classrooms = classrooms.Where("id NOT IN (?)", bun.In(ids))
+	}
+
+	var enrollments []*model.ClassroomStudents
+	err := s.db.NewSelect().
+		Model(&enrollments).
+		Relation("Student").
+		Relation("Classroom").
+		Where("cs.is_active = true").
+		Where("cs.classroom_id IN (?)", classrooms).
+		Where("student.role = 'student'").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve enrollments: %w", err)
+	}
+
+	return enrollments, nil
+}
+
+// validatePromotionMappings checks that every mapping has a destination and that no two mappings cover
+// the same students
+func validatePromotionMappings(mappings []requests.PromotionMapping) error {
+	for i, mapping := range mappings {
+		if mapping.Graduate == (len(mapping.ToClassroomIDs) > 0) {
+			return fmt.Errorf("each mapping needs either target classrooms or graduate")
+		}
+		for _, other := range mappings[:i] {
+			if other.FromGradeLevel != mapping.FromGradeLevel {
+				continue
+			}
+			if other.FromSection == nil || mapping.FromSection == nil || *other.FromSection == *mapping.FromSection {
+				return fmt.Errorf("mappings overlap")
+			}
+		}
+	}
+	return nil
+}
+
+// promotionSchool resolves the school an admin promotes students in; super admins must name one
+func promotionSchool(ctx context.Context, db bun.IDB, schoolID *uuid.UUID, userID uuid.UUID, role string) (uuid.UUID, error) {
+	if role == "super_admin" {
+		if schoolID == nil {
+			return uuid.Nil, fmt.Errorf("school_id is required")
+		}
+		return *schoolID, nil
+	}
+	if role != "admin" {
+		return uuid.Nil, fmt.Errorf("unauthorized to promote students")
+	}
+
+	userSchool, err := userSchoolID(ctx, db, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if userSchool == nil || (schoolID != nil && *schoolID != *userSchool) {
+		return uuid.Nil, fmt.Errorf("unauthorized to promote students")
+	}
+	return *userSchool, nil
+}
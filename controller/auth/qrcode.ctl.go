@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/boombuler/barcode/qr"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/response"
+	"github.com/komkem01/easy-attend-service/utils/qrcode"
+	"github.com/uptrace/bun"
+)
+
+// QRCodeController serves QR code images for check-in and student identity
+type QRCodeController struct {
+	qrCodeService *QRCodeService
+}
+
+// NewQRCodeController creates a new QR code controller
+func NewQRCodeController(db *bun.DB) *QRCodeController {
+	return &QRCodeController{
+		qrCodeService: NewQRCodeService(db),
+	}
+}
+
+// GetSessionQRCodePNG returns the session's current check-in QR code as PNG
+func (ctrl *QRCodeController) GetSessionQRCodePNG(c *gin.Context) {
+	ctrl.getSessionQRCode(c, "png")
+}
+
+// GetSessionQRCodeSVG returns the session's current check-in QR code as SVG
+func (ctrl *QRCodeController) GetSessionQRCodeSVG(c *gin.Context) {
+	ctrl.getSessionQRCode(c, "svg")
+}
+
+// GetMyQRCodePNG returns the authenticated student's identity QR code as PNG
+func (ctrl *QRCodeController) GetMyQRCodePNG(c *gin.Context) {
+	ctrl.getMyQRCode(c, "png")
+}
+
+// GetMyQRCodeSVG returns the authenticated student's identity QR code as SVG
+func (ctrl *QRCodeController) GetMyQRCodeSVG(c *gin.Context) {
+	ctrl.getMyQRCode(c, "svg")
+}
+
+func (ctrl *QRCodeController) getSessionQRCode(c *gin.Context, format string) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid session ID format")
+		return
+	}
+
+	size, level, err := parseQRCodeOptions(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	payload, expiresAt, err := ctrl.qrCodeService.GetSessionCheckInPayloadService(c.Request.Context(), sessionID, userUUID)
+	if err != nil {
+		switch err.Error() {
+		case "session not found":
+			response.NotFound(c, "Session not found")
+		case "unauthorized to view this session":
+			response.Forbidden(c, "You can only display check-in codes for your own sessions")
+		case "session is not open for check-in":
+			response.Conflict(c, "Session is not open for check-in")
+		default:
+			response.InternalServerError(c, "Failed to generate check-in code: "+err.Error())
+		}
+		return
+	}
+
+	writeQRCode(c, payload, expiresAt, size, level, format)
+}
+
+func (ctrl *QRCodeController) getMyQRCode(c *gin.Context, format string) {
+	size, level, err := parseQRCodeOptions(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	payload, expiresAt, err := ctrl.qrCodeService.GetStudentIdentityPayloadService(c.Request.Context(), userUUID)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			response.NotFound(c, "User not found")
+		case "only students have an identity qr code":
+			response.Forbidden(c, "Only students have an identity QR code")
+		default:
+			response.InternalServerError(c, "Failed to generate identity code: "+err.Error())
+		}
+		return
+	}
+
+	writeQRCode(c, payload, expiresAt, size, level, format)
+}
+
+// parseQRCodeOptions reads the size (pixels) and level (L/M/Q/H) query parameters
+func parseQRCodeOptions(c *gin.Context) (int, qr.ErrorCorrectionLevel, error) {
+	size := qrcode.DefaultSize
+	if sizeStr := c.Query("size"); sizeStr != "" {
+		s, err := strconv.Atoi(sizeStr)
+		if err != nil {
+			return 0, qr.M, fmt.Errorf("invalid size")
+		}
+		size = s
+	}
+	if err := qrcode.ValidateSize(size); err != nil {
+		return 0, qr.M, err
+	}
+
+	level, err := qrcode.ParseLevel(c.Query("level"))
+	if err != nil {
+		return 0, qr.M, err
+	}
+
+	return size, level, nil
+}
+
+// writeQRCode renders the payload and caches it only until the token rotates
+func writeQRCode(c *gin.Context, payload string, expiresAt time.Time, size int, level qr.ErrorCorrectionLevel, format string) {
+	var (
+		data        []byte
+		contentType string
+		err         error
+	)
+
+	if format == "svg" {
+		data, err = qrcode.SVG(payload, size, level)
+		contentType = "image/svg+xml"
+	} else {
+		data, err = qrcode.PNG(payload, size, level)
+		contentType = "image/png"
+	}
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	maxAge := int(time.Until(expiresAt).Seconds())
+	if maxAge < 0 {
+		maxAge = 0
+	}
+
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", maxAge))
+	c.Header("Expires", expiresAt.UTC().Format(http.TimeFormat))
+	c.Data(http.StatusOK, contentType, data)
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/utils/checkin"
+	"github.com/uptrace/bun"
+)
+
+// QRCodeService builds the payloads rendered into QR code images
+type QRCodeService struct {
+	db *bun.DB
+}
+
+// NewQRCodeService creates a new QR code service
+func NewQRCodeService(db *bun.DB) *QRCodeService {
+	return &QRCodeService{db: db}
+}
+
+// GetSessionCheckInPayloadService returns the current check-in payload of a session and when it rotates
+func (s *QRCodeService) GetSessionCheckInPayloadService(ctx context.Context, sessionID uuid.UUID, teacherID uuid.UUID) (string, time.Time, error) {
+	var session model.AttendanceSessions
+	err := s.db.NewSelect().
+		Model(&session).
+		Relation("Classroom").
+		Where("?TableAlias.id = ? AND ?TableAlias.deleted_at IS NULL", sessionID).
+		Scan(ctx)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", time.Time{}, fmt.Errorf("session not found")
+		}
+		return "", time.Time{}, fmt.Errorf("failed to retrieve session: %w", err)
+	}
+
+	// Only the classroom teacher or the session creator may display the check-in code
+	allowed, err := canManageSession(ctx, s.db, &session, teacherID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if !allowed {
+		return "", time.Time{}, fmt.Errorf("unauthorized to view this session")
+	}
+
+	if session.Status == "completed" || session.Status == "cancelled" {
+		return "", time.Time{}, fmt.Errorf("session is not open for check-in")
+	}
+
+	token, expiresAt := checkin.Generate(checkin.PurposeSession, session.ID.String(), time.Now())
+	return checkin.EncodePayload(checkin.PurposeSession, session.ID.String(), token), expiresAt, nil
+}
+
+// GetStudentIdentityPayloadService returns the identity payload a student shows to be scanned by a teacher
+func (s *QRCodeService) GetStudentIdentityPayloadService(ctx context.Context, userID uuid.UUID) (string, time.Time, error) {
+	var user model.Users
+	err := s.db.NewSelect().
+		Model(&user).
+		Where("u.id = ? AND u.is_active = true", userID).
+		Scan(ctx)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", time.Time{}, fmt.Errorf("user not found")
+		}
+		return "", time.Time{}, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+
+	if user.Role != "student" {
+		return "", time.Time{}, fmt.Errorf("only students have an identity qr code")
+	}
+
+	token, expiresAt := checkin.Generate(checkin.PurposeStudent, user.ID.String(), time.Now())
+	return checkin.EncodePayload(checkin.PurposeStudent, user.ID.String(), token), expiresAt, nil
+}
@@ -0,0 +1,685 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/mail"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/utils"
+	"github.com/uptrace/bun"
+)
+
+// MaxRosterImportRows caps the number of data rows accepted in one import
+const MaxRosterImportRows = 2000
+
+// RosterImportRow is the outcome for one CSV row
+type RosterImportRow struct {
+	Row           int        `json:"row"`
+	Action        string     `json:"action"` // create_and_enroll, enroll, already_enrolled or invalid
+	StudentID     *uuid.UUID `json:"student_id,omitempty"`
+	Username      string     `json:"username"`
+	Email         string     `json:"email"`
+	StudentNumber *string    `json:"student_number,omitempty"`
+	FirstName     string     `json:"first_name"`
+	LastName      string     `json:"last_name"`
+	Password      *string    `json:"password,omitempty"` // generated password, only returned once the import is committed
+	Errors        []string   `json:"errors,omitempty"`
+
+	prefix   string
+	gender   string
+	prefixID *int
+	genderID *int
+	phone    *string
+}
+
+// RosterImportReport summarizes a roster import or dry run
+type RosterImportReport struct {
+	DryRun          bool               `json:"dry_run"`
+	Committed       bool               `json:"committed"`
+	TotalRows       int                `json:"total_rows"`
+	Created         int                `json:"created"`
+	Enrolled        int                `json:"enrolled"`
+	AlreadyEnrolled int                `json:"already_enrolled"`
+	Invalid         int                `json:"invalid"`
+	Rows            []*RosterImportRow `json:"rows"`
+}
+
+// rosterImportColumns maps accepted header names (English and Thai) to fields
+var rosterImportColumns = map[string]string{
+	"email":          "email",
+	"e-mail":         "email",
+	"อีเมล":          "email",
+	"username":       "username",
+	"ชื่อผู้ใช้":     "username",
+	"student_number": "student_number",
+	"student_no":     "student_number",
+	"student number": "student_number",
+	"รหัสนักเรียน":   "student_number",
+	"เลขประจำตัว":    "student_number",
+	"prefix":         "prefix",
+	"title":          "prefix",
+	"คำนำหน้า":       "prefix",
+	"first_name":     "first_name",
+	"firstname":      "first_name",
+	"first name":     "first_name",
+	"ชื่อ":           "first_name",
+	"last_name":      "last_name",
+	"lastname":       "last_name",
+	"last name":      "last_name",
+	"surname":        "last_name",
+	"นามสกุล":        "last_name",
+	"gender":         "gender",
+	"sex":            "gender",
+	"เพศ":            "gender",
+	"phone":          "phone",
+	"เบอร์โทร":       "phone",
+}
+
+var usernameCleaner = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// studentEmailDomain returns STUDENT_EMAIL_DOMAIN, used for accounts imported without an email
+func studentEmailDomain() string {
+	if domain := strings.TrimSpace(os.Getenv("STUDENT_EMAIL_DOMAIN")); domain != "" {
+		return domain
+	}
+	return "students.easy-attend.local"
+}
+
+// ImportRosterService enrolls the students listed in a registrar CSV, creating accounts for anyone not
+// found by email, username or student number. Nothing is written when dryRun is set or any row is invalid.
+func (s *ClassroomService) ImportRosterService(ctx context.Context, classroomID uuid.UUID, file io.Reader, dryRun bool, userID uuid.UUID, role string) (*RosterImportReport, error) {
+	classroom, err := s.getManagedClassroom(ctx, classroomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := parseRosterCSV(file)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RosterImportReport{DryRun: dryRun, TotalRows: len(rows), Rows: rows}
+	if err := s.validateRosterImport(ctx, classroom, rows); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		switch row.Action {
+		case "invalid":
+			report.Invalid++
+		case "create_and_enroll":
+			report.Created++
+			report.Enrolled++
+		case "enroll":
+			report.Enrolled++
+		case "already_enrolled":
+			report.AlreadyEnrolled++
+		}
+	}
+
+	if dryRun || report.Invalid > 0 {
+		return report, nil
+	}
+
+	// Hash new passwords before opening the transaction; at the repo's bcrypt cost this is the slow part
+	hashes, err := hashImportPasswords(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		now := time.Now()
+		for _, row := range rows {
+			if row.Action == "create_and_enroll" {
+				user := &model.Users{
+					ID:           uuid.New(),
+					SchoolID:     classroom.SchoolID,
+					Username:     row.Username,
+					Email:        row.Email,
+					PasswordHash: hashes[row.Row],
+					PrefixID:     row.prefixID,
+					FirstName:    row.FirstName,
+					LastName:     row.LastName,
+					GenderID:     row.genderID,
+					Role:         "student",
+					Phone:        row.phone,
+					IsActive:     true,
+					CreatedAt:    now,
+					UpdatedAt:    now,
+				}
+				if _, err := tx.NewInsert().Model(user).Exec(ctx); err != nil {
+					return fmt.Errorf("row %d: failed to create account: %w", row.Row, err)
+				}
+				row.StudentID = &user.ID
+			}
+
+			var enrollment *model.ClassroomStudents
+			if row.Action == "already_enrolled" {
+				enrollment = &model.ClassroomStudents{}
+				err := tx.NewSelect().
+					Model(enrollment).
+					Where("cs.classroom_id = ? AND cs.student_id = ?", classroom.ID, *row.StudentID).
+					Scan(ctx)
+				if err != nil {
+					return fmt.Errorf("row %d: failed to retrieve enrollment: %w", row.Row, err)
+				}
+			} else {
+				var err error
+				enrollment, err = enrollStudent(ctx, tx, classroom.ID, *row.StudentID)
+				if err != nil {
+					return fmt.Errorf("row %d: %w", row.Row, err)
+				}
+			}
+
+			if err := applyRosterDetails(ctx, tx, enrollment, row.StudentNumber, nil, nil); err != nil {
+				return fmt.Errorf("row %d: %w", row.Row, err)
+			}
+		}
+
+		return writeAuditLog(ctx, tx, &userID, "classroom.roster_import", "classrooms", &classroom.ID, nil,
+			map[string]interface{}{
+				"total_rows":       report.TotalRows,
+				"created":          report.Created,
+				"enrolled":         report.Enrolled,
+				"already_enrolled": report.AlreadyEnrolled,
+			}, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.Committed = true
+	return report, nil
+}
+
+// parseRosterCSV reads the header and data rows of a roster CSV
+func parseRosterCSV(file io.Reader) ([]*RosterImportRow, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("CSV file is empty")
+		}
+		return nil, fmt.Errorf("invalid CSV file: %v", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if field, ok := rosterImportColumns[name]; ok {
+			if _, seen := columns[field]; !seen {
+				columns[field] = i
+			}
+		}
+	}
+
+	_, hasFirst := columns["first_name"]
+	_, hasLast := columns["last_name"]
+	_, hasEmail := columns["email"]
+	_, hasUsername := columns["username"]
+	_, hasNumber := columns["student_number"]
+	if !hasFirst || !hasLast || (!hasEmail && !hasUsername && !hasNumber) {
+		return nil, fmt.Errorf("CSV header must include first_name, last_name and one of email, username or student_number")
+	}
+
+	var rows []*RosterImportRow
+	line := 1
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV file: %v", err)
+		}
+
+		value := func(field string) string {
+			i, ok := columns[field]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		// Skip blank lines spreadsheets tend to leave at the end
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		if len(rows) == MaxRosterImportRows {
+			return nil, fmt.Errorf("CSV file exceeds %d rows", MaxRosterImportRows)
+		}
+
+		row := &RosterImportRow{
+			Row:       line,
+			Username:  strings.ToLower(value("username")),
+			Email:     strings.ToLower(value("email")),
+			FirstName: value("first_name"),
+			LastName:  value("last_name"),
+		}
+		if number := value("student_number"); number != "" {
+			row.StudentNumber = &number
+		}
+		if phone := value("phone"); phone != "" {
+			row.phone = &phone
+		}
+		row.prefix = value("prefix")
+		row.gender = value("gender")
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("CSV file has no rows")
+	}
+
+	return rows, nil
+}
+
+// validateRosterImport matches each row to an existing student or plans a new account, recording row errors
+func (s *ClassroomService) validateRosterImport(ctx context.Context, classroom *model.Classrooms, rows []*RosterImportRow) error {
+	activeCount, err := s.db.NewSelect().
+		Model((*model.ClassroomStudents)(nil)).
+		Where("classroom_id = ? AND is_active = true", classroom.ID).
+		Count(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to count classroom students: %w", err)
+	}
+
+	prefixes := make(map[string]*int)
+	genders := make(map[string]*int)
+	seenStudents := make(map[uuid.UUID]int)
+	seenEmails := make(map[string]int)
+	seenUsernames := make(map[string]int)
+	seenNumbers := make(map[string]int)
+
+	for _, row := range rows {
+		if row.Email != "" {
+			if _, err := mail.ParseAddress(row.Email); err != nil {
+				row.Errors = append(row.Errors, "invalid email")
+			}
+		}
+
+		user, conflict, err := s.matchImportUser(ctx, classroom, row)
+		if err != nil {
+			return err
+		}
+		if conflict != "" {
+			row.Errors = append(row.Errors, conflict)
+		}
+
+		if user != nil {
+			row.StudentID = &user.ID
+			row.Username = user.Username
+			row.Email = user.Email
+			row.FirstName = user.FirstName
+			row.LastName = user.LastName
+
+			if user.Role != "student" {
+				row.Errors = append(row.Errors, "matched user is not a student")
+			}
+			if first, ok := seenStudents[user.ID]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("student already listed on row %d", first))
+			} else {
+				seenStudents[user.ID] = row.Row
+			}
+
+			active, err := s.db.NewSelect().
+				Model((*model.ClassroomStudents)(nil)).
+				Where("classroom_id = ? AND student_id = ? AND is_active = true", classroom.ID, user.ID).
+				Exists(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to check enrollment: %w", err)
+			}
+			row.Action = "enroll"
+			if active {
+				row.Action = "already_enrolled"
+			}
+		} else if conflict == "" {
+			row.Action = "create_and_enroll"
+			if row.FirstName == "" || row.LastName == "" {
+				row.Errors = append(row.Errors, "first_name and last_name are required for new students")
+			}
+
+			// Identifiers that matched nobody may still belong to a deleted account, which would break the unique keys
+			for _, identifier := range [][2]string{{"email", row.Email}, {"username", row.Username}} {
+				column, value := identifier[0], identifier[1]
+				if value == "" {
+					continue
+				}
+				taken, err := s.db.NewSelect().
+					Model((*model.Users)(nil)).
+					Where("LOWER(?) = ?", bun.Ident(column), value).
+					WhereAllWithDeleted().
+					Exists(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to check %s: %w", column, err)
+				}
+				if taken {
+					row.Errors = append(row.Errors, column+" belongs to a deleted account")
+				}
+			}
+
+			if row.Username == "" {
+				username, err := s.generateImportUsername(ctx, row, seenUsernames)
+				if err != nil {
+					return err
+				}
+				row.Username = username
+			} else if len(row.Username) < 3 || len(row.Username) > 50 || usernameCleaner.MatchString(row.Username) {
+				row.Errors = append(row.Errors, "username must be 3-50 characters of letters, digits, '.', '_' or '-'")
+			}
+
+			if row.Email == "" {
+				row.Email = row.Username + "@" + studentEmailDomain()
+				taken, err := s.db.NewSelect().Model((*model.Users)(nil)).Where("LOWER(email) = ?", row.Email).WhereAllWithDeleted().Exists(ctx)
+				if err != nil {
+					return fmt.Errorf("failed to check email: %w", err)
+				}
+				if taken {
+					row.Errors = append(row.Errors, "generated email "+row.Email+" is already taken, please provide an email")
+				}
+			}
+
+			if row.prefix != "" {
+				if _, ok := prefixes[row.prefix]; !ok {
+					prefixID, err := FindPrefixIDByName(ctx, row.prefix)
+					if err != nil && err != sql.ErrNoRows {
+						return fmt.Errorf("failed to look up prefix: %w", err)
+					}
+					prefixes[row.prefix] = prefixID
+				}
+				if row.prefixID = prefixes[row.prefix]; row.prefixID == nil {
+					row.Errors = append(row.Errors, fmt.Sprintf("unknown prefix %q", row.prefix))
+				}
+			}
+			if row.gender != "" {
+				if _, ok := genders[row.gender]; !ok {
+					genderID, err := FindGenderIDByName(ctx, row.gender)
+					if err != nil && err != sql.ErrNoRows {
+						return fmt.Errorf("failed to look up gender: %w", err)
+					}
+					genders[row.gender] = genderID
+				}
+				if row.genderID = genders[row.gender]; row.genderID == nil {
+					row.Errors = append(row.Errors, fmt.Sprintf("unknown gender %q", row.gender))
+				}
+			}
+
+			if first, ok := seenEmails[row.Email]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("email already listed on row %d", first))
+			} else {
+				seenEmails[row.Email] = row.Row
+			}
+			if first, ok := seenUsernames[row.Username]; ok && first != row.Row {
+				row.Errors = append(row.Errors, fmt.Sprintf("username already listed on row %d", first))
+			} else {
+				seenUsernames[row.Username] = row.Row
+			}
+		}
+
+		if row.StudentNumber != nil {
+			number := *row.StudentNumber
+			if first, ok := seenNumbers[number]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("student number already listed on row %d", first))
+			} else {
+				seenNumbers[number] = row.Row
+			}
+
+			query := s.db.NewSelect().
+				Model((*model.ClassroomStudents)(nil)).
+				Where("classroom_id = ? AND student_number = ?", classroom.ID, number)
+			if row.StudentID != nil {
+				query = query.Where("student_id <> ?", *row.StudentID)
+			}
+			taken, err := query.Exists(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to check student number: %w", err)
+			}
+			if taken {
+				row.Errors = append(row.Errors, "student number is already in use in this classroom")
+			}
+		}
+
+		if len(row.Errors) > 0 {
+			row.Action = "invalid"
+			continue
+		}
+
+		if row.Action != "already_enrolled" {
+			activeCount++
+			if activeCount > classroom.MaxStudents {
+				row.Errors = append(row.Errors, "classroom is full")
+				row.Action = "invalid"
+			}
+		}
+	}
+
+	return nil
+}
+
+// matchImportUser finds the existing user a row refers to by email, username or student number within
+// the school. A non-empty conflict means the identifiers point at different users, or at a user of another
+// school, whose details are not revealed.
+func (s *ClassroomService) matchImportUser(ctx context.Context, classroom *model.Classrooms, row *RosterImportRow) (*model.Users, string, error) {
+	matches := make(map[uuid.UUID]*model.Users)
+
+	find := func(column, value string) (string, error) {
+		var user model.Users
+		err := s.db.NewSelect().
+			Model(&user).
+			Where("LOWER(?) = ?", bun.Ident("u."+column), value).
+			Scan(ctx)
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to look up user by %s: %w", column, err)
+		}
+		if !sameSchool(user.SchoolID, classroom.SchoolID) {
+			return column + " belongs to a user of another school", nil
+		}
+		matches[user.ID] = &user
+		return "", nil
+	}
+
+	if row.Email != "" {
+		conflict, err := find("email", row.Email)
+		if err != nil || conflict != "" {
+			return nil, conflict, err
+		}
+	}
+	if row.Username != "" {
+		conflict, err := find("username", row.Username)
+		if err != nil || conflict != "" {
+			return nil, conflict, err
+		}
+	}
+
+	if row.StudentNumber != nil {
+		query := s.db.NewSelect().
+			Model((*model.ClassroomStudents)(nil)).
+			ColumnExpr("DISTINCT cs.student_id").
+			Join("JOIN classrooms AS c ON c.id = cs.classroom_id").
+			Where("cs.student_number = ?", *row.StudentNumber)
+		if classroom.SchoolID != nil {
+			query = query.Where("c.school_id = ?", *classroom.SchoolID)
+		} else {
+			query = query.Where("c.id = ?", classroom.ID)
+		}
+
+		var studentIDs []uuid.UUID
+		if err := query.Scan(ctx, &studentIDs); err != nil {
+			return nil, "", fmt.Errorf("failed to look up student number: %w", err)
+		}
+		if len(studentIDs) > 1 {
+			return nil, "student number belongs to more than one student in this school", nil
+		}
+		if len(studentIDs) == 1 {
+			if _, ok := matches[studentIDs[0]]; !ok {
+				var user model.Users
+				err := s.db.NewSelect().Model(&user).Where("u.id = ?", studentIDs[0]).Scan(ctx)
+				if err != nil && err != sql.ErrNoRows {
+					return nil, "", fmt.Errorf("failed to retrieve student: %w", err)
+				}
+				if err == nil {
+					matches[user.ID] = &user
+				}
+			}
+		}
+	}
+
+	if len(matches) > 1 {
+		return nil, "email, username and student number belong to different users", nil
+	}
+	for _, user := range matches {
+		return user, "", nil
+	}
+	return nil, "", nil
+}
+
+// sameSchool reports whether two optional school IDs refer to the same school, or both to none
+func sameSchool(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// hashImportPasswords generates a password for every row that creates an account and hashes them on one
+// worker per CPU, since bcrypt at the repo's cost takes about a second per password. The hashes are keyed by row.
+func hashImportPasswords(ctx context.Context, rows []*RosterImportRow) (map[int]string, error) {
+	var pending []*RosterImportRow
+	for _, row := range rows {
+		if row.Action != "create_and_enroll" {
+			continue
+		}
+		password, err := generatePassword(10)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate password: %w", err)
+		}
+		row.Password = &password
+		pending = append(pending, row)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan *RosterImportRow)
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+	)
+	hashes := make(map[int]string, len(pending))
+	workers := min(runtime.NumCPU(), len(pending))
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range jobs {
+				hash, err := utils.HashPassword(*row.Password)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = fmt.Errorf("failed to process password: %w", err)
+					cancel()
+				}
+				hashes[row.Row] = hash
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, row := range pending {
+		select {
+		case jobs <- row:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return hashes, nil
+}
+
+// generateImportUsername derives a free username from the student number or email
+func (s *ClassroomService) generateImportUsername(ctx context.Context, row *RosterImportRow, reserved map[string]int) (string, error) {
+	base := "student"
+	switch {
+	case row.StudentNumber != nil:
+		base = "s" + strings.ToLower(*row.StudentNumber)
+	case row.Email != "":
+		base = strings.SplitN(row.Email, "@", 2)[0]
+	}
+	base = usernameCleaner.ReplaceAllString(strings.ToLower(base), "")
+	if len(base) < 3 {
+		base = "student" + base
+	}
+	if len(base) > 40 {
+		base = base[:40]
+	}
+
+	for suffix := 0; suffix < 100; suffix++ {
+		candidate := base
+		if suffix > 0 {
+			candidate = fmt.Sprintf("%s%d", base, suffix)
+		}
+		if _, ok := reserved[candidate]; ok {
+			continue
+		}
+		taken, err := s.db.NewSelect().
+			Model((*model.Users)(nil)).
+			Where("LOWER(username) = ?", candidate).
+			WhereAllWithDeleted().
+			Exists(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to check username: %w", err)
+		}
+		if !taken {
+			reserved[candidate] = row.Row
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to generate a unique username for row %d", row.Row)
+}
+
+// generatePassword returns a random password without easily confused characters
+func generatePassword(length int) (string, error) {
+	const charset = "abcdefghjkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	password := make([]byte, length)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		if err != nil {
+			return "", err
+		}
+		password[i] = charset[n.Int64()]
+	}
+	return string(password), nil
+}
@@ -0,0 +1,264 @@
+package auth
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/komkem01/easy-attend-service/response"
+	"github.com/uptrace/bun"
+)
+
+// ScheduleController handles classroom timetable and calendar feed endpoints
+type ScheduleController struct {
+	scheduleService *ScheduleService
+}
+
+// NewScheduleController creates a new schedule controller
+func NewScheduleController(db *bun.DB) *ScheduleController {
+	return &ScheduleController{
+		scheduleService: NewScheduleService(db),
+	}
+}
+
+// GetSchedules lists a classroom's weekly slots
+func (ctrl *ScheduleController) GetSchedules(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	schedules, err := ctrl.scheduleService.GetSchedulesService(c.Request.Context(), id, c.Query("include_inactive") == "true")
+	if err != nil {
+		respondScheduleError(c, err, "Failed to retrieve schedules")
+		return
+	}
+
+	response.Success(c, schedules)
+}
+
+// CreateSchedule adds a weekly slot to a classroom
+func (ctrl *ScheduleController) CreateSchedule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	var req requests.CreateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	schedule, err := ctrl.scheduleService.CreateScheduleService(c.Request.Context(), id, &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondScheduleError(c, err, "Failed to create schedule")
+		return
+	}
+
+	response.Created(c, schedule)
+}
+
+// UpdateSchedule edits a weekly slot
+func (ctrl *ScheduleController) UpdateSchedule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	scheduleID, err := uuid.Parse(c.Param("schedule_id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid schedule ID format")
+		return
+	}
+
+	var req requests.UpdateScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	schedule, err := ctrl.scheduleService.UpdateScheduleService(c.Request.Context(), id, scheduleID, &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondScheduleError(c, err, "Failed to update schedule")
+		return
+	}
+
+	response.Success(c, schedule)
+}
+
+// DeleteSchedule removes a weekly slot
+func (ctrl *ScheduleController) DeleteSchedule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid classroom ID format")
+		return
+	}
+
+	scheduleID, err := uuid.Parse(c.Param("schedule_id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid schedule ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	err = ctrl.scheduleService.DeleteScheduleService(c.Request.Context(), id, scheduleID, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondScheduleError(c, err, "Failed to delete schedule")
+		return
+	}
+
+	c.JSON(200, gin.H{"success": true, "message": "Schedule deleted successfully"})
+}
+
+// GetTimetable returns the current user's week; ?date=YYYY-MM-DD picks the week containing that date
+func (ctrl *ScheduleController) GetTimetable(c *gin.Context) {
+	date, err := parseDateQuery(c, "date")
+	if err != nil {
+		response.BadRequest(c, "Invalid date, expected YYYY-MM-DD")
+		return
+	}
+	day := time.Now().In(schoolLocation)
+	if date != nil {
+		day = *date
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	timetable, err := ctrl.scheduleService.GetTimetableService(c.Request.Context(), userUUID, day)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to retrieve timetable")
+		return
+	}
+
+	response.Success(c, timetable)
+}
+
+// CreateCalendarFeed issues an iCalendar subscription URL, replacing any earlier one for the same scope
+func (ctrl *ScheduleController) CreateCalendarFeed(c *gin.Context) {
+	var req requests.CreateCalendarFeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	feed, err := ctrl.scheduleService.CreateCalendarFeedService(c.Request.Context(), userUUID, req.ClassroomID)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to create calendar feed")
+		return
+	}
+
+	response.Created(c, feed)
+}
+
+// GetCalendarFeeds lists the current user's calendar subscription URLs
+func (ctrl *ScheduleController) GetCalendarFeeds(c *gin.Context) {
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	feeds, err := ctrl.scheduleService.GetCalendarFeedsService(c.Request.Context(), userUUID)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to retrieve calendar feeds")
+		return
+	}
+
+	response.Success(c, feeds)
+}
+
+// RevokeCalendarFeed disables a calendar subscription URL
+func (ctrl *ScheduleController) RevokeCalendarFeed(c *gin.Context) {
+	feedID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid calendar feed ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	if err := ctrl.scheduleService.RevokeCalendarFeedService(c.Request.Context(), userUUID, feedID); err != nil {
+		respondScheduleError(c, err, "Failed to revoke calendar feed")
+		return
+	}
+
+	c.JSON(200, gin.H{"success": true, "message": "Calendar feed revoked successfully"})
+}
+
+// GetCalendarFeed serves an .ics document; the token in the URL is the only credential, so calendar
+// clients can subscribe without logging in
+func (ctrl *ScheduleController) GetCalendarFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("file"), ".ics")
+
+	body, err := ctrl.scheduleService.RenderCalendarFeedService(c.Request.Context(), token)
+	if err != nil {
+		respondScheduleError(c, err, "Failed to build calendar feed")
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="easy-attend.ics"`)
+	c.Header("Cache-Control", "private, max-age=900")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", body)
+}
+
+// respondScheduleError maps schedule service errors to HTTP responses; conflicts carry the clashing slots
+func respondScheduleError(c *gin.Context, err error, fallback string) {
+	var conflictErr *ScheduleConflictError
+	if errors.As(err, &conflictErr) {
+		response.ConflictWithData(c, conflictErr.Error(), conflictErr.Conflicts)
+		return
+	}
+
+	switch err.Error() {
+	case "classroom not found", "schedule not found", "calendar feed not found":
+		response.NotFound(c, err.Error())
+	case "unauthorized to manage this classroom", "not a member of this classroom":
+		response.Forbidden(c, err.Error())
+	case "no data to update", "invalid time format, expected HH:MM", "end time must be after start time",
+		"invalid effective date, expected YYYY-MM-DD", "effective until must not be before effective from",
+		"day of week must be between 0 (Sunday) and 6 (Saturday)":
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback+": "+err.Error())
+	}
+}
@@ -0,0 +1,376 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/uptrace/bun"
+)
+
+// ScheduleService manages the weekly timetable slots of classrooms
+type ScheduleService struct {
+	db *bun.DB
+}
+
+// NewScheduleService creates a new schedule service
+func NewScheduleService(db *bun.DB) *ScheduleService {
+	return &ScheduleService{db: db}
+}
+
+// ScheduleConflict is one existing slot that clashes with a slot being saved
+type ScheduleConflict struct {
+	Type       string                `json:"type"` // classroom, teacher, room or student
+	Schedule   *model.ClassSchedules `json:"schedule"`
+	StudentIDs []uuid.UUID           `json:"student_ids,omitempty"` // students enrolled in both classes
+}
+
+// ScheduleConflictError is returned when a slot clashes with other slots; it lists every clash
+type ScheduleConflictError struct {
+	Conflicts []ScheduleConflict
+}
+
+func (e *ScheduleConflictError) Error() string {
+	return "schedule conflicts with existing classes"
+}
+
+// GetSchedulesService lists a classroom's slots ordered by day and start time
+func (s *ScheduleService) GetSchedulesService(ctx context.Context, classroomID uuid.UUID, includeInactive bool) ([]*model.ClassSchedules, error) {
+	exists, err := s.db.NewSelect().
+		Model((*model.Classrooms)(nil)).
+		Where("id = ? AND deleted_at IS NULL", classroomID).
+		Exists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve classroom: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("classroom not found")
+	}
+
+	var schedules []*model.ClassSchedules
+	query := s.db.NewSelect().
+		Model(&schedules).
+		Where("csch.classroom_id = ?", classroomID).
+		Order("csch.day_of_week ASC", "csch.start_time ASC")
+	if !includeInactive {
+		query = query.Where("csch.is_active = true")
+	}
+
+	if err := query.Scan(ctx); err != nil {
+		return nil, fmt.Errorf("failed to retrieve schedules: %w", err)
+	}
+
+	return schedules, nil
+}
+
+// CreateScheduleService adds a weekly slot after checking it against the teacher's, the room's and the students' other classes
+func (s *ScheduleService) CreateScheduleService(ctx context.Context, classroomID uuid.UUID, req *requests.CreateScheduleRequest, userID uuid.UUID, role string) (*model.ClassSchedules, error) {
+	classroom, err := loadManagedClassroom(ctx, s.db, classroomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	startTime, err := parseScheduleClock(req.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	endTime, err := parseScheduleClock(req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	schedule := &model.ClassSchedules{
+		ID:          uuid.New(),
+		ClassroomID: classroom.ID,
+		DayOfWeek:   int16(*req.DayOfWeek),
+		StartTime:   startTime,
+		EndTime:     endTime,
+		RoomNumber:  normalizeRoomNumber(req.RoomNumber),
+		IsActive:    true,
+		CreatedAt:   time.Now(),
+	}
+	if req.IsActive != nil {
+		schedule.IsActive = *req.IsActive
+	}
+	if schedule.EffectiveFrom, err = parseScheduleDate(req.EffectiveFrom); err != nil {
+		return nil, err
+	}
+	if schedule.EffectiveUntil, err = parseScheduleDate(req.EffectiveUntil); err != nil {
+		return nil, err
+	}
+
+	if err := validateSchedule(schedule); err != nil {
+		return nil, err
+	}
+	if err := checkScheduleConflicts(ctx, s.db, classroom, schedule); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.db.NewInsert().Model(schedule).Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to create schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// UpdateScheduleService edits a weekly slot; the result is checked for conflicts like a new slot
+func (s *ScheduleService) UpdateScheduleService(ctx context.Context, classroomID uuid.UUID, scheduleID uuid.UUID, req *requests.UpdateScheduleRequest, userID uuid.UUID, role string) (*model.ClassSchedules, error) {
+	classroom, err := loadManagedClassroom(ctx, s.db, classroomID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DayOfWeek == nil && req.StartTime == nil && req.EndTime == nil && req.RoomNumber == nil &&
+		req.EffectiveFrom == nil && req.EffectiveUntil == nil && req.IsActive == nil {
+		return nil, fmt.Errorf("no data to update")
+	}
+
+	schedule, err := s.getSchedule(ctx, classroom.ID, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DayOfWeek != nil {
+		schedule.DayOfWeek = int16(*req.DayOfWeek)
+	}
+	if req.StartTime != nil {
+		if schedule.StartTime, err = parseScheduleClock(*req.StartTime); err != nil {
+			return nil, err
+		}
+	}
+	if req.EndTime != nil {
+		if schedule.EndTime, err = parseScheduleClock(*req.EndTime); err != nil {
+			return nil, err
+		}
+	}
+	if req.RoomNumber != nil {
+		schedule.RoomNumber = normalizeRoomNumber(req.RoomNumber)
+	}
+	if req.EffectiveFrom != nil {
+		if schedule.EffectiveFrom, err = parseScheduleDate(req.EffectiveFrom); err != nil {
+			return nil, err
+		}
+	}
+	if req.EffectiveUntil != nil {
+		if schedule.EffectiveUntil, err = parseScheduleDate(req.EffectiveUntil); err != nil {
+			return nil, err
+		}
+	}
+	if req.IsActive != nil {
+		schedule.IsActive = *req.IsActive
+	}
+
+	// Values read back from a time column carry year 0; rebuild them so they are written as plain clock times
+	schedule.StartTime = scheduleClock(schedule.StartTime.Hour(), schedule.StartTime.Minute())
+	schedule.EndTime = scheduleClock(schedule.EndTime.Hour(), schedule.EndTime.Minute())
+
+	if err := validateSchedule(schedule); err != nil {
+		return nil, err
+	}
+	if err := checkScheduleConflicts(ctx, s.db, classroom, schedule); err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.NewUpdate().
+		Model(schedule).
+		Column("day_of_week", "start_time", "end_time", "room_number", "is_active", "effective_from", "effective_until").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update schedule: %w", err)
+	}
+
+	return schedule, nil
+}
+
+// DeleteScheduleService removes a weekly slot (soft delete)
+func (s *ScheduleService) DeleteScheduleService(ctx context.Context, classroomID uuid.UUID, scheduleID uuid.UUID, userID uuid.UUID, role string) error {
+	classroom, err := loadManagedClassroom(ctx, s.db, classroomID, userID, role)
+	if err != nil {
+		return err
+	}
+
+	schedule, err := s.getSchedule(ctx, classroom.ID, scheduleID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.db.NewDelete().Model(schedule).WherePK().Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete schedule: %w", err)
+	}
+	return nil
+}
+
+// getSchedule loads a slot belonging to a classroom
+func (s *ScheduleService) getSchedule(ctx context.Context, classroomID uuid.UUID, scheduleID uuid.UUID) (*model.ClassSchedules, error) {
+	var schedule model.ClassSchedules
+	err := s.db.NewSelect().
+		Model(&schedule).
+		Where("csch.id = ? AND csch.classroom_id = ?", scheduleID, classroomID).
+		Scan(ctx)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("schedule not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve schedule: %w", err)
+	}
+
+	return &schedule, nil
+}
+
+// checkScheduleConflicts looks for active slots that overlap the given one in time and effective dates and
+// share its classroom, teacher, room or any enrolled student
+func checkScheduleConflicts(ctx context.Context, db bun.IDB, classroom *model.Classrooms, schedule *model.ClassSchedules) error {
+	if !schedule.IsActive {
+		return nil
+	}
+
+	var conflicts []ScheduleConflict
+
+	teacherSlots, err := findOverlappingSlots(ctx, db, schedule, func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.Where("classroom.teacher_id = ?", classroom.TeacherID)
+	})
+	if err != nil {
+		return err
+	}
+	for _, slot := range teacherSlots {
+		conflictType := "teacher"
+		if slot.ClassroomID == classroom.ID {
+			conflictType = "classroom"
+		}
+		conflicts = append(conflicts, ScheduleConflict{Type: conflictType, Schedule: slot})
+	}
+
+	// A slot without its own room uses the classroom's room; rooms are only shared within a school
+	room := schedule.RoomNumber
+	if room == nil {
+		room = normalizeRoomNumber(classroom.RoomNumber)
+	}
+	if room != nil {
+		roomSlots, err := findOverlappingSlots(ctx, db, schedule, func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("csch.classroom_id <> ?", classroom.ID).
+				Where("classroom.school_id IS NOT DISTINCT FROM ?", classroom.SchoolID).
+				Where("LOWER(TRIM(COALESCE(NULLIF(csch.room_number, ''), classroom.room_number))) = LOWER(?)", *room)
+		})
+		if err != nil {
+			return err
+		}
+		for _, slot := range roomSlots {
+			conflicts = append(conflicts, ScheduleConflict{Type: "room", Schedule: slot})
+		}
+	}
+
+	sharedClassrooms := db.NewSelect().
+		TableExpr("classroom_students AS mine").
+		Join("JOIN classroom_students AS other ON other.student_id = mine.student_id").
+		ColumnExpr("other.classroom_id").
+		Where("mine.classroom_id = ? AND mine.is_active = true AND other.is_active = true", classroom.ID)
+	studentSlots, err := findOverlappingSlots(ctx, db, schedule, func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.Where("csch.classroom_id <> ?", classroom.ID).
+			Where("csch.classroom_id IN (?)", sharedClassrooms)
+	})
+	if err != nil {
+		return err
+	}
+	for _, slot := range studentSlots {
+		var studentIDs []uuid.UUID
+		err := db.NewSelect().
+			TableExpr("classroom_students AS mine").
+			Join("JOIN classroom_students AS other ON other.student_id = mine.student_id").
+			ColumnExpr("mine.student_id").
+			Where("mine.classroom_id = ? AND mine.is_active = true", classroom.ID).
+			Where("other.classroom_id = ? AND other.is_active = true", slot.ClassroomID).
+			Scan(ctx, &studentIDs)
+		if err != nil {
+			return fmt.Errorf("failed to check student schedules: %w", err)
+		}
+		conflicts = append(conflicts, ScheduleConflict{Type: "student", Schedule: slot, StudentIDs: studentIDs})
+	}
+
+	if len(conflicts) > 0 {
+		return &ScheduleConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// findOverlappingSlots returns active slots in active classrooms that overlap the given slot, narrowed by filter
+func findOverlappingSlots(ctx context.Context, db bun.IDB, schedule *model.ClassSchedules, filter func(*bun.SelectQuery) *bun.SelectQuery) ([]*model.ClassSchedules, error) {
+	var slots []*model.ClassSchedules
+	query := db.NewSelect().
+		Model(&slots).
+		Relation("Classroom").
+		Where("csch.id <> ?", schedule.ID).
+		Where("csch.is_active = true AND csch.day_of_week = ?", schedule.DayOfWeek).
+		Where("csch.start_time < ? AND csch.end_time > ?", schedule.EndTime, schedule.StartTime).
+		Where("classroom.is_active = true AND classroom.deleted_at IS NULL")
+
+	if schedule.EffectiveUntil != nil {
+		query = query.Where("csch.effective_from IS NULL OR csch.effective_from <= ?", *schedule.EffectiveUntil)
+	}
+	if schedule.EffectiveFrom != nil {
+		query = query.Where("csch.effective_until IS NULL OR csch.effective_until >= ?", *schedule.EffectiveFrom)
+	}
+
+	err := filter(query).
+		Order("csch.day_of_week ASC", "csch.start_time ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check schedule conflicts: %w", err)
+	}
+
+	return slots, nil
+}
+
+// validateSchedule checks the time range and effective dates of a slot
+func validateSchedule(schedule *model.ClassSchedules) error {
+	if schedule.DayOfWeek < 0 || schedule.DayOfWeek > 6 {
+		return fmt.Errorf("day of week must be between 0 (Sunday) and 6 (Saturday)")
+	}
+	if !schedule.EndTime.After(schedule.StartTime) {
+		return fmt.Errorf("end time must be after start time")
+	}
+	if schedule.EffectiveFrom != nil && schedule.EffectiveUntil != nil && schedule.EffectiveUntil.Before(*schedule.EffectiveFrom) {
+		return fmt.Errorf("effective until must not be before effective from")
+	}
+	return nil
+}
+
+// parseScheduleClock parses an HH:MM clock time for a time column
+func parseScheduleClock(value string) (time.Time, error) {
+	clock, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time format, expected HH:MM")
+	}
+	return scheduleClock(clock.Hour(), clock.Minute()), nil
+}
+
+// parseScheduleDate parses an optional YYYY-MM-DD date; nil or empty means open-ended.
+// Dates are kept in UTC so bun does not shift them to the previous day when writing a date column.
+func parseScheduleDate(value *string) (*time.Time, error) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(*value))
+	if err != nil {
+		return nil, fmt.Errorf("invalid effective date, expected YYYY-MM-DD")
+	}
+	return &date, nil
+}
+
+// normalizeRoomNumber trims a room number, treating blank as no room
+func normalizeRoomNumber(room *string) *string {
+	if room == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*room)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
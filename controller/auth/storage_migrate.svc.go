@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/utils/storage"
+)
+
+// StorageMigrationReport describes a run that moved uploaded files between storage backends
+type StorageMigrationReport struct {
+	DryRun  bool     `json:"dry_run"`
+	Total   int      `json:"total"`
+	Copied  int      `json:"copied"`
+	Skipped int      `json:"skipped"` // already in the target with a matching checksum
+	Deleted int      `json:"deleted"` // removed from the source after a verified copy
+	Missing []string `json:"missing,omitempty"`
+	Failed  []string `json:"failed,omitempty"`
+}
+
+// MigrateStorageService copies every object referenced by FileUploads.FilePath, including deleted uploads
+// still waiting for the trash purge, from src to dst. Keys stay the same, so no rows change. Each copy is
+// read back and its SHA-256 compared with the source before the source is optionally deleted.
+func (s *UploadService) MigrateStorageService(ctx context.Context, src, dst storage.Storage, deleteSource, dryRun bool) (*StorageMigrationReport, error) {
+	var keys []string
+	err := s.db.NewSelect().
+		Model((*model.FileUploads)(nil)).
+		ColumnExpr("DISTINCT file_path").
+		WhereAllWithDeleted().
+		Order("file_path ASC").
+		Scan(ctx, &keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list uploaded files: %w", err)
+	}
+
+	report := &StorageMigrationReport{DryRun: dryRun, Total: len(keys)}
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		result, err := storage.Migrate(ctx, src, dst, key, deleteSource, dryRun)
+		if result != nil {
+			switch {
+			case result.Missing:
+				report.Missing = append(report.Missing, key)
+			case result.Skipped:
+				report.Skipped++
+			case result.Copied:
+				report.Copied++
+			}
+			if result.Deleted {
+				report.Deleted++
+			}
+		}
+		if err != nil {
+			report.Failed = append(report.Failed, fmt.Sprintf("%s: %s", key, err.Error()))
+		}
+	}
+
+	return report, nil
+}
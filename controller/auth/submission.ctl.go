@@ -0,0 +1,238 @@
+package auth
+
+import (
+	"errors"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/komkem01/easy-attend-service/response"
+	"github.com/uptrace/bun"
+)
+
+// SubmissionController handles assignment submission endpoints
+type SubmissionController struct {
+	submissionService *SubmissionService
+}
+
+// NewSubmissionController creates a new submission controller
+func NewSubmissionController(db *bun.DB) *SubmissionController {
+	return &SubmissionController{
+		submissionService: NewSubmissionService(db),
+	}
+}
+
+// GetMySubmission returns the caller's submission for an assignment
+func (ctrl *SubmissionController) GetMySubmission(c *gin.Context) {
+	assignmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid assignment ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	submission, err := ctrl.submissionService.GetMySubmissionService(c.Request.Context(), assignmentID, userUUID)
+	if err != nil {
+		respondSubmissionError(c, err, "Failed to fetch submission")
+		return
+	}
+
+	response.Success(c, submission)
+}
+
+// SaveSubmissionDraft creates or updates the caller's draft submission
+func (ctrl *SubmissionController) SaveSubmissionDraft(c *gin.Context) {
+	assignmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid assignment ID format")
+		return
+	}
+
+	var req requests.SaveSubmissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	submission, created, err := ctrl.submissionService.SaveDraftService(c.Request.Context(), assignmentID, &req, userUUID)
+	if err != nil {
+		respondSubmissionError(c, err, "Failed to save submission")
+		return
+	}
+
+	if created {
+		response.Created(c, submission)
+		return
+	}
+	response.Success(c, submission)
+}
+
+// SubmitSubmission hands in the caller's draft submission
+func (ctrl *SubmissionController) SubmitSubmission(c *gin.Context) {
+	assignmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid assignment ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	submission, err := ctrl.submissionService.SubmitService(c.Request.Context(), assignmentID, userUUID)
+	if err != nil {
+		respondSubmissionError(c, err, "Failed to submit assignment")
+		return
+	}
+
+	response.Success(c, submission)
+}
+
+// GetSubmissions lists an assignment's handed-in submissions for grading; ?status filters them
+func (ctrl *SubmissionController) GetSubmissions(c *gin.Context) {
+	assignmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid assignment ID format")
+		return
+	}
+
+	status := c.Query("status")
+	if status != "" && status != "submitted" && status != "graded" && status != "returned" {
+		response.BadRequest(c, "Invalid status, expected submitted, graded or returned")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	submissions, err := ctrl.submissionService.GetSubmissionsService(c.Request.Context(), assignmentID, status, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondSubmissionError(c, err, "Failed to fetch submissions")
+		return
+	}
+
+	response.Success(c, submissions)
+}
+
+// GradeSubmission scores one submission
+func (ctrl *SubmissionController) GradeSubmission(c *gin.Context) {
+	submissionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid submission ID format")
+		return
+	}
+
+	var req requests.GradeSubmissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	submission, err := ctrl.submissionService.GradeSubmissionService(c.Request.Context(), submissionID, &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondSubmissionError(c, err, "Failed to grade submission")
+		return
+	}
+
+	response.Success(c, submission)
+}
+
+// BatchGradeSubmissions scores many submissions of an assignment at once
+func (ctrl *SubmissionController) BatchGradeSubmissions(c *gin.Context) {
+	assignmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid assignment ID format")
+		return
+	}
+
+	var req requests.BatchGradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	submissions, err := ctrl.submissionService.BatchGradeService(c.Request.Context(), assignmentID, &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondSubmissionError(c, err, "Failed to grade submissions")
+		return
+	}
+
+	response.Success(c, submissions)
+}
+
+// ReturnSubmissions releases graded submissions so students can see their scores
+func (ctrl *SubmissionController) ReturnSubmissions(c *gin.Context) {
+	assignmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid assignment ID format")
+		return
+	}
+
+	var req requests.ReturnSubmissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	submissions, err := ctrl.submissionService.ReturnSubmissionsService(c.Request.Context(), assignmentID, &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondSubmissionError(c, err, "Failed to return submissions")
+		return
+	}
+
+	response.Success(c, submissions)
+}
+
+// respondSubmissionError maps submission service errors to HTTP responses
+func respondSubmissionError(c *gin.Context, err error, fallback string) {
+	switch err.Error() {
+	case "assignment not found", "submission not found":
+		response.NotFound(c, err.Error())
+	case "student is not enrolled in this classroom", "unauthorized to grade this assignment":
+		response.Forbidden(c, err.Error())
+	case "submission has already been submitted", "submission was saved by another request, try again":
+		response.Conflict(c, err.Error())
+	case "assignment is not published", "this assignment only accepts files", "this assignment requires a text answer",
+		"this assignment requires a file", "submission is empty", "the deadline has passed",
+		"submission has not been submitted", "submission has not been graded", "score exceeds the assignment's max score",
+		"submission is listed more than once":
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback+": "+err.Error())
+	}
+}
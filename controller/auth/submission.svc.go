@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/uptrace/bun"
+)
+
+// SubmissionService handles student assignment submissions
+type SubmissionService struct {
+	db *bun.DB
+}
+
+// NewSubmissionService creates a new submission service
+func NewSubmissionService(db *bun.DB) *SubmissionService {
+	return &SubmissionService{db: db}
+}
+
+// GetMySubmissionService returns the student's own submission for an assignment. The grade stays hidden
+// until the teacher returns it.
+func (s *SubmissionService) GetMySubmissionService(ctx context.Context, assignmentID, studentID uuid.UUID) (*model.AssignmentSubmissions, error) {
+	if _, err := loadSubmittableAssignment(ctx, s.db, assignmentID, studentID); err != nil {
+		return nil, err
+	}
+
+	submission, err := findSubmission(ctx, s.db, assignmentID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if submission == nil {
+		return nil, fmt.Errorf("submission not found")
+	}
+	hideUnreturnedGrade(submission)
+	return submission, nil
+}
+
+// SaveDraftService creates the student's draft submission or updates it. A submission that has been
+// handed in can no longer be edited.
+func (s *SubmissionService) SaveDraftService(ctx context.Context, assignmentID uuid.UUID, req *requests.SaveSubmissionRequest, studentID uuid.UUID) (*model.AssignmentSubmissions, bool, error) {
+	assignment, err := loadSubmittableAssignment(ctx, s.db, assignmentID, studentID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	text := req.SubmissionText
+	if text != nil && strings.TrimSpace(*text) == "" {
+		text = nil
+	}
+	if text != nil && assignment.SubmissionFormat == "file" {
+		return nil, false, fmt.Errorf("this assignment only accepts files")
+	}
+
+	submission, err := findSubmission(ctx, s.db, assignmentID, studentID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	now := time.Now()
+	if submission == nil {
+		submission = &model.AssignmentSubmissions{
+			ID:             uuid.New(),
+			AssignmentID:   assignmentID,
+			StudentID:      studentID,
+			SubmissionText: text,
+			Status:         "draft",
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if _, err := s.db.NewInsert().Model(submission).Exec(ctx); err != nil {
+			if strings.Contains(err.Error(), "duplicate key") {
+				return nil, false, fmt.Errorf("submission was saved by another request, try again")
+			}
+			return nil, false, fmt.Errorf("failed to create submission: %w", err)
+		}
+		return submission, true, nil
+	}
+
+	if submission.Status != "draft" {
+		return nil, false, fmt.Errorf("submission has already been submitted")
+	}
+
+	submission.SubmissionText = text
+	submission.UpdatedAt = now
+	_, err = s.db.NewUpdate().
+		Model(submission).
+		Column("submission_text", "updated_at").
+		Where("id = ?", submission.ID).
+		Where("status = 'draft'").
+		Exec(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to update submission: %w", err)
+	}
+
+	return submission, false, nil
+}
+
+// SubmitService hands in the student's draft. The submission must match the assignment's format, and
+// after the due date it is only accepted, and marked late, when the assignment allows late submissions.
+func (s *SubmissionService) SubmitService(ctx context.Context, assignmentID, studentID uuid.UUID) (*model.AssignmentSubmissions, error) {
+	assignment, err := loadSubmittableAssignment(ctx, s.db, assignmentID, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	submission, err := findSubmission(ctx, s.db, assignmentID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if submission == nil {
+		return nil, fmt.Errorf("submission not found")
+	}
+	if submission.Status != "draft" {
+		return nil, fmt.Errorf("submission has already been submitted")
+	}
+
+	fileCount, err := s.db.NewSelect().
+		Model((*model.FileUploads)(nil)).
+		Where("related_table = 'assignment_submissions'").
+		Where("related_id = ?", submission.ID).
+		Count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check submission files: %w", err)
+	}
+	hasText := submission.SubmissionText != nil && strings.TrimSpace(*submission.SubmissionText) != ""
+
+	switch assignment.SubmissionFormat {
+	case "text":
+		if !hasText {
+			return nil, fmt.Errorf("this assignment requires a text answer")
+		}
+	case "file":
+		if fileCount == 0 {
+			return nil, fmt.Errorf("this assignment requires a file")
+		}
+	default:
+		if !hasText && fileCount == 0 {
+			return nil, fmt.Errorf("submission is empty")
+		}
+	}
+
+	now := time.Now()
+	submission.IsLate, submission.LateMinutes = false, 0
+	if assignment.DueDate != nil && now.After(*assignment.DueDate) {
+		if !assignment.AllowLateSubmission {
+			return nil, fmt.Errorf("the deadline has passed")
+		}
+		submission.IsLate = true
+		submission.LateMinutes = int(now.Sub(*assignment.DueDate).Minutes())
+		if submission.LateMinutes < 1 {
+			submission.LateMinutes = 1
+		}
+	}
+
+	submission.Status = "submitted"
+	submission.SubmittedAt = &now
+	submission.UpdatedAt = now
+	res, err := s.db.NewUpdate().
+		Model(submission).
+		Column("status", "submitted_at", "is_late", "late_minutes", "updated_at").
+		Where("id = ?", submission.ID).
+		Where("status = 'draft'").
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit: %w", err)
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return nil, fmt.Errorf("submission has already been submitted")
+	}
+
+	return submission, nil
+}
+
+// loadSubmittableAssignment returns a published assignment from a classroom the student is actively enrolled in
+func loadSubmittableAssignment(ctx context.Context, db bun.IDB, assignmentID, studentID uuid.UUID) (*model.Assignments, error) {
+	var assignment model.Assignments
+	err := db.NewSelect().
+		Model(&assignment).
+		Where("a.id = ?", assignmentID).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("assignment not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve assignment: %w", err)
+	}
+
+	if !assignment.IsPublished || assignment.Status == "archived" {
+		return nil, fmt.Errorf("assignment is not published")
+	}
+
+	enrolled, err := db.NewSelect().
+		Model((*model.ClassroomStudents)(nil)).
+		Where("classroom_id = ? AND student_id = ? AND is_active = true", assignment.ClassroomID, studentID).
+		Exists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check enrollment: %w", err)
+	}
+	if !enrolled {
+		return nil, fmt.Errorf("student is not enrolled in this classroom")
+	}
+
+	return &assignment, nil
+}
+
+// findSubmission returns the student's submission for an assignment, or nil when there is none yet
+func findSubmission(ctx context.Context, db bun.IDB, assignmentID, studentID uuid.UUID) (*model.AssignmentSubmissions, error) {
+	var submission model.AssignmentSubmissions
+	err := db.NewSelect().
+		Model(&submission).
+		Where("asub.assignment_id = ? AND asub.student_id = ?", assignmentID, studentID).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to retrieve submission: %w", err)
+	}
+	return &submission, nil
+}
@@ -0,0 +1,275 @@
+package auth
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/komkem01/easy-attend-service/response"
+	"github.com/uptrace/bun"
+)
+
+// TermController handles academic year and term endpoints
+type TermController struct {
+	termService *TermService
+}
+
+// NewTermController creates a new term controller
+func NewTermController(db *bun.DB) *TermController {
+	return &TermController{
+		termService: NewTermService(db),
+	}
+}
+
+// GetAcademicYears lists academic years with their terms; ?school_id defaults to the user's school
+func (ctrl *TermController) GetAcademicYears(c *gin.Context) {
+	schoolID, ok := parseSchoolIDQuery(c)
+	if !ok {
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	years, err := ctrl.termService.GetAcademicYearsService(c.Request.Context(), schoolID, userUUID)
+	if err != nil {
+		respondTermError(c, err, "Failed to retrieve academic years")
+		return
+	}
+
+	response.Success(c, years)
+}
+
+// GetCurrentTerm returns the term covering today; ?school_id defaults to the user's school
+func (ctrl *TermController) GetCurrentTerm(c *gin.Context) {
+	schoolID, ok := parseSchoolIDQuery(c)
+	if !ok {
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	term, err := ctrl.termService.GetCurrentTermService(c.Request.Context(), schoolID, userUUID)
+	if err != nil {
+		respondTermError(c, err, "Failed to retrieve current term")
+		return
+	}
+
+	response.Success(c, term)
+}
+
+// CreateAcademicYear adds an academic year, optionally with its terms
+func (ctrl *TermController) CreateAcademicYear(c *gin.Context) {
+	var req requests.CreateAcademicYearRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	year, err := ctrl.termService.CreateAcademicYearService(c.Request.Context(), &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondTermError(c, err, "Failed to create academic year")
+		return
+	}
+
+	response.Created(c, year)
+}
+
+// UpdateAcademicYear edits an academic year
+func (ctrl *TermController) UpdateAcademicYear(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid academic year ID format")
+		return
+	}
+
+	var req requests.UpdateAcademicYearRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	year, err := ctrl.termService.UpdateAcademicYearService(c.Request.Context(), id, &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondTermError(c, err, "Failed to update academic year")
+		return
+	}
+
+	response.Success(c, year)
+}
+
+// DeleteAcademicYear removes an academic year and its terms
+func (ctrl *TermController) DeleteAcademicYear(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid academic year ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	if err := ctrl.termService.DeleteAcademicYearService(c.Request.Context(), id, userUUID, GetUserRoleFromContext(c)); err != nil {
+		respondTermError(c, err, "Failed to delete academic year")
+		return
+	}
+
+	c.JSON(200, gin.H{"success": true, "message": "Academic year deleted successfully"})
+}
+
+// CreateTerm adds a term to an academic year
+func (ctrl *TermController) CreateTerm(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid academic year ID format")
+		return
+	}
+
+	var req requests.CreateTermRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	term, err := ctrl.termService.CreateTermService(c.Request.Context(), id, &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondTermError(c, err, "Failed to create term")
+		return
+	}
+
+	response.Created(c, term)
+}
+
+// UpdateTerm edits a term
+func (ctrl *TermController) UpdateTerm(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid term ID format")
+		return
+	}
+
+	var req requests.UpdateTermRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data: "+err.Error())
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	term, err := ctrl.termService.UpdateTermService(c.Request.Context(), id, &req, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondTermError(c, err, "Failed to update term")
+		return
+	}
+
+	response.Success(c, term)
+}
+
+// DeleteTerm removes a term
+func (ctrl *TermController) DeleteTerm(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid term ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	if err := ctrl.termService.DeleteTermService(c.Request.Context(), id, userUUID, GetUserRoleFromContext(c)); err != nil {
+		respondTermError(c, err, "Failed to delete term")
+		return
+	}
+
+	c.JSON(200, gin.H{"success": true, "message": "Term deleted successfully"})
+}
+
+// ArchiveTerm deactivates a finished term's classrooms and locks their sessions
+func (ctrl *TermController) ArchiveTerm(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid term ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	result, err := ctrl.termService.ArchiveTermService(c.Request.Context(), id, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondTermError(c, err, "Failed to archive term")
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// parseSchoolIDQuery reads an optional ?school_id, writing a 400 response when it is malformed
+func parseSchoolIDQuery(c *gin.Context) (*uuid.UUID, bool) {
+	value := c.Query("school_id")
+	if value == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		response.BadRequest(c, "Invalid school ID format")
+		return nil, false
+	}
+	return &id, true
+}
+
+// respondTermError maps term service errors to HTTP responses
+func respondTermError(c *gin.Context, err error, fallback string) {
+	switch err.Error() {
+	case "academic year not found", "term not found", "no current term":
+		response.NotFound(c, err.Error())
+	case "unauthorized to manage academic terms":
+		response.Forbidden(c, err.Error())
+	case "academic year name already exists", "academic year overlaps another academic year", "term overlaps another term",
+		"term number already exists in this academic year", "academic year has classrooms", "term has classrooms",
+		"term is already archived":
+		response.Conflict(c, err.Error())
+	case "invalid date, expected YYYY-MM-DD", "end date must be after start date", "term must fall within its academic year",
+		"school_id is required", "term has not ended yet":
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback+": "+err.Error())
+	}
+}
@@ -0,0 +1,684 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/requests"
+	"github.com/uptrace/bun"
+)
+
+// TermService manages academic years and terms
+type TermService struct {
+	db *bun.DB
+}
+
+// NewTermService creates a new term service
+func NewTermService(db *bun.DB) *TermService {
+	return &TermService{db: db}
+}
+
+// TermArchiveResult reports what archiving a term changed
+type TermArchiveResult struct {
+	Term                  *model.Terms `json:"term"`
+	ClassroomsDeactivated int          `json:"classrooms_deactivated"`
+	SessionsLocked        int          `json:"sessions_locked"`
+}
+
+// GetAcademicYearsService lists a school's academic years (the user's own school by default) with their terms
+func (s *TermService) GetAcademicYearsService(ctx context.Context, schoolID *uuid.UUID, userID uuid.UUID) ([]*model.AcademicYears, error) {
+	if schoolID == nil {
+		var err error
+		if schoolID, err = userSchoolID(ctx, s.db, userID); err != nil {
+			return nil, err
+		}
+		if schoolID == nil {
+			return []*model.AcademicYears{}, nil
+		}
+	}
+
+	var years []*model.AcademicYears
+	err := s.db.NewSelect().
+		Model(&years).
+		Relation("Terms", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("tm.start_date ASC")
+		}).
+		Where("ay.school_id = ?", *schoolID).
+		Order("ay.start_date DESC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve academic years: %w", err)
+	}
+
+	return years, nil
+}
+
+// GetCurrentTermService returns the term covering today for a school (the user's own by default)
+func (s *TermService) GetCurrentTermService(ctx context.Context, schoolID *uuid.UUID, userID uuid.UUID) (*model.Terms, error) {
+	if schoolID == nil {
+		var err error
+		if schoolID, err = userSchoolID(ctx, s.db, userID); err != nil {
+			return nil, err
+		}
+		if schoolID == nil {
+			return nil, fmt.Errorf("no current term")
+		}
+	}
+
+	term, err := termAt(ctx, s.db, *schoolID, time.Now().In(schoolLocation))
+	if err != nil {
+		return nil, err
+	}
+	if term == nil {
+		return nil, fmt.Errorf("no current term")
+	}
+	return term, nil
+}
+
+// CreateAcademicYearService adds an academic year and any terms given with it
+func (s *TermService) CreateAcademicYearService(ctx context.Context, req *requests.CreateAcademicYearRequest, userID uuid.UUID, role string) (*model.AcademicYears, error) {
+	schoolID, err := termSchoolForEditor(ctx, s.db, req.SchoolID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	startDate, endDate, err := parseTermRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	year := &model.AcademicYears{
+		ID:        uuid.New(),
+		SchoolID:  schoolID,
+		Name:      strings.TrimSpace(req.Name),
+		StartDate: startDate,
+		EndDate:   endDate,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	for _, termReq := range req.Terms {
+		term, err := newTerm(year, &termReq)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateTermPlacement(year, term, year.Terms); err != nil {
+			return nil, err
+		}
+		year.Terms = append(year.Terms, term)
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := checkAcademicYearOverlap(ctx, tx, year); err != nil {
+			return err
+		}
+		if _, err := tx.NewInsert().Model(year).Exec(ctx); err != nil {
+			if strings.Contains(err.Error(), "idx_academic_years_school_name") {
+				return fmt.Errorf("academic year name already exists")
+			}
+			return fmt.Errorf("failed to create academic year: %w", err)
+		}
+		if len(year.Terms) > 0 {
+			if _, err := tx.NewInsert().Model(&year.Terms).Exec(ctx); err != nil {
+				return fmt.Errorf("failed to create terms: %w", err)
+			}
+		}
+		return writeAuditLog(ctx, tx, &userID, "academic_year.create", "academic_years", &year.ID, nil, year, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return year, nil
+}
+
+// UpdateAcademicYearService edits an academic year; its terms must still fit inside it
+func (s *TermService) UpdateAcademicYearService(ctx context.Context, yearID uuid.UUID, req *requests.UpdateAcademicYearRequest, userID uuid.UUID, role string) (*model.AcademicYears, error) {
+	year, err := s.getEditableYear(ctx, yearID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	old := *year
+
+	if req.Name != nil {
+		year.Name = strings.TrimSpace(*req.Name)
+	}
+	start := year.StartDate.Format("2006-01-02")
+	end := year.EndDate.Format("2006-01-02")
+	if req.StartDate != nil {
+		start = *req.StartDate
+	}
+	if req.EndDate != nil {
+		end = *req.EndDate
+	}
+	if year.StartDate, year.EndDate, err = parseTermRange(start, end); err != nil {
+		return nil, err
+	}
+	for _, term := range year.Terms {
+		if term.StartDate.Before(year.StartDate) || term.EndDate.After(year.EndDate) {
+			return nil, fmt.Errorf("term must fall within its academic year")
+		}
+	}
+	year.UpdatedAt = time.Now()
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if err := checkAcademicYearOverlap(ctx, tx, year); err != nil {
+			return err
+		}
+		_, err := tx.NewUpdate().
+			Model(year).
+			Column("name", "start_date", "end_date", "updated_at").
+			WherePK().
+			Exec(ctx)
+		if err != nil {
+			if strings.Contains(err.Error(), "idx_academic_years_school_name") {
+				return fmt.Errorf("academic year name already exists")
+			}
+			return fmt.Errorf("failed to update academic year: %w", err)
+		}
+		old.Terms = nil
+		return writeAuditLog(ctx, tx, &userID, "academic_year.update", "academic_years", &year.ID, old, year, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return year, nil
+}
+
+// DeleteAcademicYearService removes an academic year and its terms (soft delete); years whose terms
+// already have classrooms are kept
+func (s *TermService) DeleteAcademicYearService(ctx context.Context, yearID uuid.UUID, userID uuid.UUID, role string) error {
+	year, err := s.getEditableYear(ctx, yearID, userID, role)
+	if err != nil {
+		return err
+	}
+
+	termIDs := make([]uuid.UUID, 0, len(year.Terms))
+	for _, term := range year.Terms {
+		termIDs = append(termIDs, term.ID)
+	}
+	if len(termIDs) > 0 {
+		used, err := s.db.NewSelect().
+			Model((*model.Classrooms)(nil)).
+			Where("term_id IN (?)", bun.In(termIDs)).
+			Exists(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to check classrooms: %w", err)
+		}
+		if used {
+			return fmt.Errorf("academic year has classrooms")
+		}
+	}
+
+	return s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if len(termIDs) > 0 {
+			if _, err := tx.NewDelete().Model((*model.Terms)(nil)).Where("id IN (?)", bun.In(termIDs)).Exec(ctx); err != nil {
+				return fmt.Errorf("failed to delete terms: %w", err)
+			}
+		}
+		if _, err := tx.NewDelete().Model(year).WherePK().Exec(ctx); err != nil {
+			return fmt.Errorf("failed to delete academic year: %w", err)
+		}
+		return writeAuditLog(ctx, tx, &userID, "academic_year.delete", "academic_years", &year.ID, year, nil, nil)
+	})
+}
+
+// CreateTermService adds a term to an academic year
+func (s *TermService) CreateTermService(ctx context.Context, yearID uuid.UUID, req *requests.CreateTermRequest, userID uuid.UUID, role string) (*model.Terms, error) {
+	year, err := s.getEditableYear(ctx, yearID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	term, err := newTerm(year, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTermPlacement(year, term, year.Terms); err != nil {
+		return nil, err
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(term).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to create term: %w", err)
+		}
+		return writeAuditLog(ctx, tx, &userID, "term.create", "terms", &term.ID, nil, term, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return term, nil
+}
+
+// UpdateTermService edits a term; it must stay inside its academic year and clear of the other terms
+func (s *TermService) UpdateTermService(ctx context.Context, termID uuid.UUID, req *requests.UpdateTermRequest, userID uuid.UUID, role string) (*model.Terms, error) {
+	term, year, err := s.getEditableTerm(ctx, termID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	old := *term
+
+	if req.Name != nil {
+		term.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.TermNumber != nil {
+		term.TermNumber = *req.TermNumber
+	}
+	start := term.StartDate.Format("2006-01-02")
+	end := term.EndDate.Format("2006-01-02")
+	if req.StartDate != nil {
+		start = *req.StartDate
+	}
+	if req.EndDate != nil {
+		end = *req.EndDate
+	}
+	if term.StartDate, term.EndDate, err = parseTermRange(start, end); err != nil {
+		return nil, err
+	}
+
+	others := make([]*model.Terms, 0, len(year.Terms))
+	for _, other := range year.Terms {
+		if other.ID != term.ID {
+			others = append(others, other)
+		}
+	}
+	if err := validateTermPlacement(year, term, others); err != nil {
+		return nil, err
+	}
+	term.UpdatedAt = time.Now()
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().
+			Model(term).
+			Column("name", "term_number", "start_date", "end_date", "updated_at").
+			WherePK().
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to update term: %w", err)
+		}
+		return writeAuditLog(ctx, tx, &userID, "term.update", "terms", &term.ID, old, term, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return term, nil
+}
+
+// DeleteTermService removes a term that no classroom belongs to (soft delete)
+func (s *TermService) DeleteTermService(ctx context.Context, termID uuid.UUID, userID uuid.UUID, role string) error {
+	term, _, err := s.getEditableTerm(ctx, termID, userID, role)
+	if err != nil {
+		return err
+	}
+
+	used, err := s.db.NewSelect().
+		Model((*model.Classrooms)(nil)).
+		Where("term_id = ?", term.ID).
+		Exists(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check classrooms: %w", err)
+	}
+	if used {
+		return fmt.Errorf("term has classrooms")
+	}
+
+	return s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewDelete().Model(term).WherePK().Exec(ctx); err != nil {
+			return fmt.Errorf("failed to delete term: %w", err)
+		}
+		return writeAuditLog(ctx, tx, &userID, "term.delete", "terms", &term.ID, term, nil, nil)
+	})
+}
+
+// ArchiveTermService closes a finished term: its classrooms are deactivated and every session still open
+// for editing is locked, so past attendance stays as reported
+func (s *TermService) ArchiveTermService(ctx context.Context, termID uuid.UUID, userID uuid.UUID, role string) (*TermArchiveResult, error) {
+	term, _, err := s.getEditableTerm(ctx, termID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	return archiveTerm(ctx, s.db, term, &userID)
+}
+
+// ArchiveEndedTermsService archives every term that has ended and is not archived yet
+func (s *TermService) ArchiveEndedTermsService(ctx context.Context) ([]*TermArchiveResult, error) {
+	today := time.Now().In(schoolLocation).Format("2006-01-02")
+
+	var terms []*model.Terms
+	err := s.db.NewSelect().
+		Model(&terms).
+		Where("tm.end_date < ? AND tm.archived_at IS NULL", today).
+		Order("tm.end_date ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve terms: %w", err)
+	}
+
+	results := make([]*TermArchiveResult, 0, len(terms))
+	for _, term := range terms {
+		result, err := archiveTerm(ctx, s.db, term, nil)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// archiveTerm deactivates a term's classrooms and locks their sessions; archivedBy is nil for the scheduled job
+func archiveTerm(ctx context.Context, db *bun.DB, term *model.Terms, archivedBy *uuid.UUID) (*TermArchiveResult, error) {
+	if term.ArchivedAt != nil {
+		return nil, fmt.Errorf("term is already archived")
+	}
+	today := time.Now().In(schoolLocation)
+	if term.EndDate.Format("2006-01-02") >= today.Format("2006-01-02") {
+		return nil, fmt.Errorf("term has not ended yet")
+	}
+
+	now := time.Now()
+	result := &TermArchiveResult{Term: term}
+	err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		classrooms := tx.NewSelect().
+			Model((*model.Classrooms)(nil)).
+			Column("id").
+			Where("term_id = ?", term.ID)
+
+		res, err := tx.NewUpdate().
+			Model((*model.AttendanceSessions)(nil)).
+			Set("is_locked = true").
+			Set("locked_by = ?", archivedBy).
+			Set("locked_at = ?", now).
+			Set("updated_at = ?", now).
+			Where("is_locked = false").
+			Where("term_id = ? OR classroom_id IN (?)", term.ID, classrooms).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to lock sessions: %w", err)
+		}
+		if affected, err := res.RowsAffected(); err == nil {
+			result.SessionsLocked = int(affected)
+		}
+
+		res, err = tx.NewUpdate().
+			Model((*model.Classrooms)(nil)).
+			Set("is_active = false").
+			Set("updated_at = ?", now).
+			Where("term_id = ? AND is_active = true", term.ID).
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to deactivate classrooms: %w", err)
+		}
+		if affected, err := res.RowsAffected(); err == nil {
+			result.ClassroomsDeactivated = int(affected)
+		}
+
+		term.ArchivedAt = &now
+		term.ArchivedBy = archivedBy
+		term.UpdatedAt = now
+		_, err = tx.NewUpdate().
+			Model(term).
+			Column("archived_at", "archived_by", "updated_at").
+			WherePK().
+			Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to archive term: %w", err)
+		}
+
+		return writeAuditLog(ctx, tx, archivedBy, "term.archive", "terms", &term.ID, nil, nil, map[string]interface{}{
+			"classrooms_deactivated": result.ClassroomsDeactivated,
+			"sessions_locked":        result.SessionsLocked,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// getEditableYear loads an academic year with its terms and checks the user may change it
+func (s *TermService) getEditableYear(ctx context.Context, yearID uuid.UUID, userID uuid.UUID, role string) (*model.AcademicYears, error) {
+	var year model.AcademicYears
+	err := s.db.NewSelect().
+		Model(&year).
+		Relation("Terms").
+		Where("ay.id = ?", yearID).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("academic year not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve academic year: %w", err)
+	}
+
+	if _, err := termSchoolForEditor(ctx, s.db, &year.SchoolID, userID, role); err != nil {
+		return nil, err
+	}
+	return &year, nil
+}
+
+// getEditableTerm loads a term with its academic year (and the year's terms) and checks the user may change it
+func (s *TermService) getEditableTerm(ctx context.Context, termID uuid.UUID, userID uuid.UUID, role string) (*model.Terms, *model.AcademicYears, error) {
+	var term model.Terms
+	err := s.db.NewSelect().
+		Model(&term).
+		Where("tm.id = ?", termID).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, fmt.Errorf("term not found")
+		}
+		return nil, nil, fmt.Errorf("failed to retrieve term: %w", err)
+	}
+
+	year, err := s.getEditableYear(ctx, term.AcademicYearID, userID, role)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &term, year, nil
+}
+
+// termSchoolForEditor resolves which school's terms the user is editing. Admins manage their own school;
+// super admins must name the school.
+func termSchoolForEditor(ctx context.Context, db bun.IDB, schoolID *uuid.UUID, userID uuid.UUID, role string) (uuid.UUID, error) {
+	if role == "super_admin" {
+		if schoolID == nil {
+			return uuid.Nil, fmt.Errorf("school_id is required")
+		}
+		return *schoolID, nil
+	}
+	if role != "admin" {
+		return uuid.Nil, fmt.Errorf("unauthorized to manage academic terms")
+	}
+
+	userSchool, err := userSchoolID(ctx, db, userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if userSchool == nil || (schoolID != nil && *schoolID != *userSchool) {
+		return uuid.Nil, fmt.Errorf("unauthorized to manage academic terms")
+	}
+	return *userSchool, nil
+}
+
+// userSchoolID returns the school a user belongs to, or nil
+func userSchoolID(ctx context.Context, db bun.IDB, userID uuid.UUID) (*uuid.UUID, error) {
+	var user model.Users
+	err := db.NewSelect().
+		Model(&user).
+		Column("school_id").
+		Where("id = ?", userID).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+	return user.SchoolID, nil
+}
+
+// termAt returns the school's term covering date, or nil when the date falls between terms
+func termAt(ctx context.Context, db bun.IDB, schoolID uuid.UUID, date time.Time) (*model.Terms, error) {
+	var term model.Terms
+	err := db.NewSelect().
+		Model(&term).
+		Relation("AcademicYear").
+		Where("tm.school_id = ?", schoolID).
+		Where("tm.start_date <= ? AND tm.end_date >= ?", date.Format("2006-01-02"), date.Format("2006-01-02")).
+		Limit(1).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to retrieve term: %w", err)
+	}
+	return &term, nil
+}
+
+// defaultTermIDs is a subquery selecting one term per school for default listings: the most recent term
+// that has started, which is the current one during a term and the last one between terms, or else the
+// school's first upcoming term
+func defaultTermIDs(db bun.IDB) *bun.SelectQuery {
+	today := time.Now().In(schoolLocation).Format("2006-01-02")
+	return db.NewSelect().
+		Model((*model.Terms)(nil)).
+		DistinctOn("school_id").
+		Column("id").
+		OrderExpr("school_id").
+		OrderExpr("start_date <= ? DESC", today).
+		OrderExpr("CASE WHEN start_date <= ? THEN start_date END DESC", today).
+		OrderExpr("start_date ASC")
+}
+
+// classroomTerm picks the term a classroom belongs to: the requested one, which must be from the classroom's
+// school, or else the school's current term. Classrooms without a school have no term unless one is given.
+func classroomTerm(ctx context.Context, db bun.IDB, schoolID *uuid.UUID, termID *uuid.UUID) (*uuid.UUID, error) {
+	if termID == nil {
+		if schoolID == nil {
+			return nil, nil
+		}
+		term, err := termAt(ctx, db, *schoolID, time.Now().In(schoolLocation))
+		if err != nil || term == nil {
+			return nil, err
+		}
+		return &term.ID, nil
+	}
+
+	var term model.Terms
+	err := db.NewSelect().
+		Model(&term).
+		Where("tm.id = ?", *termID).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("term not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve term: %w", err)
+	}
+	if schoolID != nil && term.SchoolID != *schoolID {
+		return nil, fmt.Errorf("term belongs to another school")
+	}
+	if term.ArchivedAt != nil {
+		return nil, fmt.Errorf("term is archived")
+	}
+	return &term.ID, nil
+}
+
+// termPeriod returns the dates a classroom's reports cover by default: its term, or from its creation
+// until today when it has no term
+func termPeriod(ctx context.Context, db bun.IDB, classroom *model.Classrooms) (time.Time, time.Time, error) {
+	if classroom.TermID == nil {
+		return classroom.CreatedAt, time.Now(), nil
+	}
+
+	var term model.Terms
+	err := db.NewSelect().
+		Model(&term).
+		WhereAllWithDeleted().
+		Where("tm.id = ?", *classroom.TermID).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return classroom.CreatedAt, time.Now(), nil
+		}
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to retrieve term: %w", err)
+	}
+	return term.StartDate, term.EndDate, nil
+}
+
+// newTerm builds a term of an academic year from a request
+func newTerm(year *model.AcademicYears, req *requests.CreateTermRequest) (*model.Terms, error) {
+	startDate, endDate, err := parseTermRange(req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &model.Terms{
+		ID:             uuid.New(),
+		AcademicYearID: year.ID,
+		SchoolID:       year.SchoolID,
+		Name:           strings.TrimSpace(req.Name),
+		TermNumber:     req.TermNumber,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}, nil
+}
+
+// validateTermPlacement checks a term lies inside its academic year without overlapping the other terms
+func validateTermPlacement(year *model.AcademicYears, term *model.Terms, others []*model.Terms) error {
+	if term.StartDate.Before(year.StartDate) || term.EndDate.After(year.EndDate) {
+		return fmt.Errorf("term must fall within its academic year")
+	}
+	for _, other := range others {
+		if !term.StartDate.After(other.EndDate) && !other.StartDate.After(term.EndDate) {
+			return fmt.Errorf("term overlaps another term")
+		}
+		if term.TermNumber == other.TermNumber {
+			return fmt.Errorf("term number already exists in this academic year")
+		}
+	}
+	return nil
+}
+
+// checkAcademicYearOverlap rejects an academic year whose dates overlap another year of the same school
+func checkAcademicYearOverlap(ctx context.Context, db bun.IDB, year *model.AcademicYears) error {
+	overlaps, err := db.NewSelect().
+		Model((*model.AcademicYears)(nil)).
+		Where("school_id = ? AND id != ?", year.SchoolID, year.ID).
+		Where("start_date <= ? AND end_date >= ?", year.EndDate.Format("2006-01-02"), year.StartDate.Format("2006-01-02")).
+		Exists(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check academic years: %w", err)
+	}
+	if overlaps {
+		return fmt.Errorf("academic year overlaps another academic year")
+	}
+	return nil
+}
+
+// parseTermRange parses a YYYY-MM-DD date range whose end must come after its start
+func parseTermRange(start, end string) (time.Time, time.Time, error) {
+	startDate, err := parseCalendarDate(start)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	endDate, err := parseCalendarDate(end)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if !endDate.After(startDate) {
+		return time.Time{}, time.Time{}, fmt.Errorf("end date must be after start date")
+	}
+	return startDate, endDate, nil
+}
@@ -0,0 +1,338 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/uptrace/bun"
+)
+
+// Timetable is one user's week laid out by day and period
+type Timetable struct {
+	WeekStart string            `json:"week_start"`
+	WeekEnd   string            `json:"week_end"`
+	Periods   []TimetablePeriod `json:"periods"`
+	Days      []*TimetableDay   `json:"days"`
+}
+
+// TimetablePeriod is a distinct time range used by at least one class during the week
+type TimetablePeriod struct {
+	Number    int    `json:"number"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+}
+
+// TimetableDay holds the classes and calendar events of one date
+type TimetableDay struct {
+	Date      string                `json:"date"`
+	DayOfWeek int                   `json:"day_of_week"`
+	Events    []*CalendarOccurrence `json:"events"`
+	Entries   []*TimetableEntry     `json:"entries"`
+}
+
+// TimetableEntry is a class on a given day, from the weekly schedule, a concrete session or both
+type TimetableEntry struct {
+	Period        int        `json:"period"`
+	StartTime     string     `json:"start_time"`
+	EndTime       string     `json:"end_time"`
+	ClassroomID   uuid.UUID  `json:"classroom_id"`
+	ClassroomName string     `json:"classroom_name"`
+	Subject       string     `json:"subject"`
+	RoomNumber    *string    `json:"room_number"`
+	Role          string     `json:"role"`   // the user's role in the classroom
+	Source        string     `json:"source"` // schedule, session or both
+	ScheduleID    *uuid.UUID `json:"schedule_id,omitempty"`
+	SessionID     *uuid.UUID `json:"session_id,omitempty"`
+	SessionStatus *string    `json:"session_status,omitempty"`
+	Cancelled     bool       `json:"cancelled"`
+	CancelReason  *string    `json:"cancel_reason,omitempty"`
+	NoClass       bool       `json:"no_class"` // a holiday or other calendar event suspends attendance that day
+}
+
+// GetTimetableService builds the Monday-to-Sunday week containing day for every classroom the user
+// teaches, assists in or is enrolled in
+func (s *ScheduleService) GetTimetableService(ctx context.Context, userID uuid.UUID, day time.Time) (*Timetable, error) {
+	weekStart := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	weekStart = weekStart.AddDate(0, 0, -((int(weekStart.Weekday()) + 6) % 7))
+	weekEnd := weekStart.AddDate(0, 0, 6)
+
+	classrooms, roles, err := s.userClassrooms(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	timetable := &Timetable{
+		WeekStart: weekStart.Format("2006-01-02"),
+		WeekEnd:   weekEnd.Format("2006-01-02"),
+		Periods:   []TimetablePeriod{},
+	}
+	for i := 0; i < 7; i++ {
+		date := weekStart.AddDate(0, 0, i)
+		timetable.Days = append(timetable.Days, &TimetableDay{
+			Date:      date.Format("2006-01-02"),
+			DayOfWeek: int(date.Weekday()),
+			Events:    []*CalendarOccurrence{},
+			Entries:   []*TimetableEntry{},
+		})
+	}
+
+	events, err := s.calendarEvents(ctx, userID, classrooms, weekStart, weekEnd)
+	if err != nil {
+		return nil, err
+	}
+	noClass := make(map[string]bool)
+	for _, occurrence := range events {
+		for _, d := range timetable.Days {
+			if occurrence.Date <= d.Date && occurrence.EndDate >= d.Date {
+				d.Events = append(d.Events, occurrence)
+				if cancelsClasses(occurrence.Event) {
+					noClass[d.Date] = true
+				}
+			}
+		}
+	}
+
+	if len(classrooms) == 0 {
+		return timetable, nil
+	}
+
+	classroomIDs := classroomKeys(classrooms)
+
+	var schedules []*model.ClassSchedules
+	err = s.db.NewSelect().
+		Model(&schedules).
+		Where("csch.classroom_id IN (?) AND csch.is_active = true", bun.In(classroomIDs)).
+		Where("csch.effective_from IS NULL OR csch.effective_from <= ?", weekEnd.Format("2006-01-02")).
+		Where("csch.effective_until IS NULL OR csch.effective_until >= ?", weekStart.Format("2006-01-02")).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve schedules: %w", err)
+	}
+
+	var sessions []*model.AttendanceSessions
+	err = s.db.NewSelect().
+		Model(&sessions).
+		Where("?TableAlias.classroom_id IN (?)", bun.In(classroomIDs)).
+		Where("?TableAlias.session_date BETWEEN ? AND ?", weekStart.Format("2006-01-02"), weekEnd.Format("2006-01-02")).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve sessions: %w", err)
+	}
+
+	for _, d := range timetable.Days {
+		for _, schedule := range schedules {
+			if int(schedule.DayOfWeek) != d.DayOfWeek || !scheduleCoversDate(schedule, d.Date) {
+				continue
+			}
+			classroom := classrooms[schedule.ClassroomID]
+			room := schedule.RoomNumber
+			if room == nil {
+				room = classroom.RoomNumber
+			}
+			scheduleID := schedule.ID
+			d.Entries = append(d.Entries, &TimetableEntry{
+				StartTime:     schedule.StartTime.Format("15:04"),
+				EndTime:       schedule.EndTime.Format("15:04"),
+				ClassroomID:   classroom.ID,
+				ClassroomName: classroom.Name,
+				Subject:       classroom.Subject,
+				RoomNumber:    room,
+				Role:          roles[classroom.ID],
+				Source:        "schedule",
+				ScheduleID:    &scheduleID,
+				NoClass:       noClass[d.Date],
+			})
+		}
+	}
+
+	// Attach each session to the scheduled slot it falls on, or add it as an extra class (e.g. a make-up)
+	for _, session := range sessions {
+		index := int(session.SessionDate.Sub(weekStart).Hours() / 24)
+		if index < 0 || index >= len(timetable.Days) {
+			continue
+		}
+		d := timetable.Days[index]
+		start := session.StartTime.Format("15:04")
+		end := session.EndTime.Format("15:04")
+
+		var entry *TimetableEntry
+		for _, e := range d.Entries {
+			if e.ClassroomID == session.ClassroomID && e.SessionID == nil && e.StartTime < end && e.EndTime > start {
+				entry = e
+				entry.Source = "both"
+				break
+			}
+		}
+		if entry == nil {
+			classroom := classrooms[session.ClassroomID]
+			entry = &TimetableEntry{
+				StartTime:     start,
+				EndTime:       end,
+				ClassroomID:   classroom.ID,
+				ClassroomName: classroom.Name,
+				Subject:       classroom.Subject,
+				RoomNumber:    classroom.RoomNumber,
+				Role:          roles[classroom.ID],
+				Source:        "session",
+				NoClass:       noClass[d.Date],
+			}
+			if session.Location != nil {
+				entry.RoomNumber = session.Location
+			}
+			d.Entries = append(d.Entries, entry)
+		}
+
+		sessionID := session.ID
+		status := session.Status
+		entry.SessionID = &sessionID
+		entry.SessionStatus = &status
+		entry.Cancelled = session.Status == "cancelled"
+		entry.CancelReason = session.CancelReason
+	}
+
+	assignTimetablePeriods(timetable)
+	return timetable, nil
+}
+
+// userClassrooms returns the active classrooms a user teaches, is a member of or is enrolled in, with their role
+func (s *ScheduleService) userClassrooms(ctx context.Context, userID uuid.UUID) (map[uuid.UUID]*model.Classrooms, map[uuid.UUID]string, error) {
+	roles := make(map[uuid.UUID]string)
+
+	var taught []uuid.UUID
+	err := s.db.NewSelect().
+		Model((*model.Classrooms)(nil)).
+		Column("id").
+		Where("teacher_id = ?", userID).
+		Scan(ctx, &taught)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve classrooms: %w", err)
+	}
+	for _, id := range taught {
+		roles[id] = "teacher"
+	}
+
+	var members []*model.ClassroomMembers
+	err = s.db.NewSelect().
+		Model(&members).
+		Where("cm.user_id = ? AND cm.status = 'active'", userID).
+		Scan(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve classroom memberships: %w", err)
+	}
+	for _, member := range members {
+		if _, ok := roles[member.ClassroomID]; !ok {
+			roles[member.ClassroomID] = member.Role
+		}
+	}
+
+	var enrolled []uuid.UUID
+	err = s.db.NewSelect().
+		Model((*model.ClassroomStudents)(nil)).
+		Column("classroom_id").
+		Where("student_id = ? AND is_active = true", userID).
+		Scan(ctx, &enrolled)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve enrollments: %w", err)
+	}
+	for _, id := range enrolled {
+		if _, ok := roles[id]; !ok {
+			roles[id] = "student"
+		}
+	}
+
+	classrooms := make(map[uuid.UUID]*model.Classrooms)
+	if len(roles) == 0 {
+		return classrooms, roles, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(roles))
+	for id := range roles {
+		ids = append(ids, id)
+	}
+
+	var rows []*model.Classrooms
+	err = s.db.NewSelect().
+		Model(&rows).
+		Where("c.id IN (?) AND c.is_active = true AND c.deleted_at IS NULL", bun.In(ids)).
+		Scan(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve classrooms: %w", err)
+	}
+	for _, classroom := range rows {
+		classrooms[classroom.ID] = classroom
+	}
+
+	return classrooms, roles, nil
+}
+
+// calendarEvents returns calendar event occurrences in a date range for the user's school, their
+// classrooms' schools and school-independent events
+func (s *ScheduleService) calendarEvents(ctx context.Context, userID uuid.UUID, classrooms map[uuid.UUID]*model.Classrooms, from, to time.Time) ([]*CalendarOccurrence, error) {
+	var schoolIDs []uuid.UUID
+	err := s.db.NewSelect().
+		Model((*model.Users)(nil)).
+		Column("school_id").
+		Where("id = ? AND school_id IS NOT NULL", userID).
+		Scan(ctx, &schoolIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+	for _, classroom := range classrooms {
+		if classroom.SchoolID != nil {
+			schoolIDs = append(schoolIDs, *classroom.SchoolID)
+		}
+	}
+
+	return calendarOccurrences(ctx, s.db, schoolIDs, from, to)
+}
+
+// assignTimetablePeriods numbers the distinct time ranges of the week and sorts each day's entries by them
+func assignTimetablePeriods(timetable *Timetable) {
+	seen := make(map[[2]string]bool)
+	for _, d := range timetable.Days {
+		for _, e := range d.Entries {
+			key := [2]string{e.StartTime, e.EndTime}
+			if !seen[key] {
+				seen[key] = true
+				timetable.Periods = append(timetable.Periods, TimetablePeriod{StartTime: e.StartTime, EndTime: e.EndTime})
+			}
+		}
+	}
+
+	sort.Slice(timetable.Periods, func(i, j int) bool {
+		if timetable.Periods[i].StartTime != timetable.Periods[j].StartTime {
+			return timetable.Periods[i].StartTime < timetable.Periods[j].StartTime
+		}
+		return timetable.Periods[i].EndTime < timetable.Periods[j].EndTime
+	})
+
+	numbers := make(map[[2]string]int, len(timetable.Periods))
+	for i := range timetable.Periods {
+		timetable.Periods[i].Number = i + 1
+		numbers[[2]string{timetable.Periods[i].StartTime, timetable.Periods[i].EndTime}] = i + 1
+	}
+
+	for _, d := range timetable.Days {
+		for _, e := range d.Entries {
+			e.Period = numbers[[2]string{e.StartTime, e.EndTime}]
+		}
+		sort.SliceStable(d.Entries, func(i, j int) bool {
+			return d.Entries[i].Period < d.Entries[j].Period
+		})
+	}
+}
+
+// scheduleCoversDate reports whether a slot's effective range includes a YYYY-MM-DD date
+func scheduleCoversDate(schedule *model.ClassSchedules, date string) bool {
+	if schedule.EffectiveFrom != nil && schedule.EffectiveFrom.Format("2006-01-02") > date {
+		return false
+	}
+	if schedule.EffectiveUntil != nil && schedule.EffectiveUntil.Format("2006-01-02") < date {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/response"
+	"github.com/komkem01/easy-attend-service/utils/storage"
+	"github.com/uptrace/bun"
+)
+
+// TrashController handles listing and restoring soft-deleted records
+type TrashController struct {
+	trashService *TrashService
+}
+
+// NewTrashController creates a new trash controller
+func NewTrashController(db *bun.DB, store storage.Storage) *TrashController {
+	return &TrashController{
+		trashService: NewTrashService(db, store),
+	}
+}
+
+// GetTrash lists deleted classrooms, assignments, schools or users visible to the caller
+func (ctrl *TrashController) GetTrash(c *gin.Context) {
+	resource := c.Param("type")
+	if !IsTrashResource(resource) {
+		response.BadRequest(c, "Invalid trash type, expected classrooms, assignments, schools or users")
+		return
+	}
+
+	page, limit := 1, 20
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		page = p
+	}
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	items, total, err := ctrl.trashService.GetTrashService(c.Request.Context(), resource, page, limit, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondTrashError(c, err, "Failed to fetch trash")
+		return
+	}
+
+	totalPages := (total + limit - 1) / limit
+
+	response.Success(c, map[string]interface{}{
+		"items": items,
+		"pagination": map[string]interface{}{
+			"current_page": page,
+			"per_page":     limit,
+			"total":        total,
+			"total_pages":  totalPages,
+			"has_next":     page < totalPages,
+			"has_prev":     page > 1,
+		},
+	})
+}
+
+// RestoreTrashItem restores a deleted record and the dependents deleted along with it
+func (ctrl *TrashController) RestoreTrashItem(c *gin.Context) {
+	resource := c.Param("type")
+	if !IsTrashResource(resource) {
+		response.BadRequest(c, "Invalid trash type, expected classrooms, assignments, schools or users")
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	item, err := ctrl.trashService.RestoreService(c.Request.Context(), resource, id, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondTrashError(c, err, "Failed to restore item")
+		return
+	}
+
+	response.Success(c, item)
+}
+
+// respondTrashError maps trash service errors to HTTP responses
+func respondTrashError(c *gin.Context, err error, fallback string) {
+	switch err.Error() {
+	case "item not found in trash":
+		response.NotFound(c, err.Error())
+	case "unauthorized to view the trash":
+		response.Forbidden(c, err.Error())
+	case "restore the classroom first":
+		response.Conflict(c, err.Error())
+	case "unknown trash type":
+		response.BadRequest(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback+": "+err.Error())
+	}
+}
@@ -0,0 +1,543 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/utils/storage"
+	"github.com/uptrace/bun"
+)
+
+// TrashService lists, restores and purges soft-deleted records
+type TrashService struct {
+	db    *bun.DB
+	store storage.Storage
+}
+
+// NewTrashService creates a new trash service
+func NewTrashService(db *bun.DB, store storage.Storage) *TrashService {
+	return &TrashService{db: db, store: store}
+}
+
+// TrashPurgeReport counts what a purge removed, and what it kept because other records still need it
+type TrashPurgeReport struct {
+	DryRun bool           `json:"dry_run"`
+	Cutoff time.Time      `json:"cutoff"`
+	Purged map[string]int `json:"purged"`
+	Kept   map[string]int `json:"kept"`
+	Errors []string       `json:"errors,omitempty"` // stored files that could not be removed
+}
+
+// IsTrashResource reports whether resource is a type the trash endpoints handle
+func IsTrashResource(resource string) bool {
+	switch resource {
+	case "classrooms", "assignments", "schools", "users":
+		return true
+	}
+	return false
+}
+
+// GetTrashService lists the caller's deleted records of one type, most recently deleted first.
+// Teachers see their own classrooms and assignments; admins see their school's; super admins see all.
+func (s *TrashService) GetTrashService(ctx context.Context, resource string, page, limit int, userID uuid.UUID, role string) (any, int, error) {
+	var query *bun.SelectQuery
+	var items any
+	switch resource {
+	case "classrooms":
+		var classrooms []*model.Classrooms
+		items, query = &classrooms, s.db.NewSelect().Model(&classrooms).WhereDeleted().Order("c.deleted_at DESC")
+	case "assignments":
+		var assignments []*model.Assignments
+		items, query = &assignments, s.db.NewSelect().Model(&assignments).WhereDeleted().Order("a.deleted_at DESC")
+	case "schools":
+		var schools []*model.Schools
+		items, query = &schools, s.db.NewSelect().Model(&schools).WhereAllWithDeleted().
+			Where("s.deleted_at IS NOT NULL OR s.is_active = false").
+			Order("s.updated_at DESC")
+	case "users":
+		var users []*model.Users
+		items, query = &users, s.db.NewSelect().Model(&users).WhereDeleted().Order("u.deleted_at DESC")
+	default:
+		return nil, 0, fmt.Errorf("unknown trash type")
+	}
+
+	query, err := s.trashScope(ctx, resource, query, userID, role)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := query.Limit(limit).Offset((page - 1) * limit).ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to retrieve deleted %s: %w", resource, err)
+	}
+
+	return items, total, nil
+}
+
+// RestoreService brings a deleted record back, together with the dependents that were deleted with or
+// after it: a classroom's schedules and assignments, an assignment's submissions and a user's profile.
+// An assignment can only be restored once its classroom is.
+func (s *TrashService) RestoreService(ctx context.Context, resource string, id uuid.UUID, userID uuid.UUID, role string) (any, error) {
+	switch resource {
+	case "classrooms":
+		var classroom model.Classrooms
+		if err := s.findDeleted(ctx, resource, s.db.NewSelect().Model(&classroom).WhereDeleted().Where("c.id = ?", id), userID, role); err != nil {
+			return nil, err
+		}
+		err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			if err := undelete(ctx, tx, (*model.Classrooms)(nil), "id = ?", id); err != nil {
+				return err
+			}
+			if err := undelete(ctx, tx, (*model.ClassSchedules)(nil), "classroom_id = ? AND deleted_at >= ?", id, classroom.DeletedAt); err != nil {
+				return err
+			}
+			if err := undelete(ctx, tx, (*model.Assignments)(nil), "classroom_id = ? AND deleted_at >= ?", id, classroom.DeletedAt); err != nil {
+				return err
+			}
+			return writeAuditLog(ctx, tx, &userID, "classroom.restore", "classrooms", &id, nil, nil, nil)
+		})
+		if err != nil {
+			return nil, err
+		}
+		classroom.DeletedAt = nil
+		return &classroom, nil
+
+	case "assignments":
+		var assignment model.Assignments
+		if err := s.findDeleted(ctx, resource, s.db.NewSelect().Model(&assignment).WhereDeleted().Where("a.id = ?", id), userID, role); err != nil {
+			return nil, err
+		}
+		classroomExists, err := s.db.NewSelect().
+			Model((*model.Classrooms)(nil)).
+			Where("id = ?", assignment.ClassroomID).
+			Exists(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check classroom: %w", err)
+		}
+		if !classroomExists {
+			return nil, fmt.Errorf("restore the classroom first")
+		}
+		err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			if err := undelete(ctx, tx, (*model.Assignments)(nil), "id = ?", id); err != nil {
+				return err
+			}
+			if err := undelete(ctx, tx, (*model.AssignmentSubmissions)(nil), "assignment_id = ? AND deleted_at >= ?", id, assignment.DeletedAt); err != nil {
+				return err
+			}
+			return writeAuditLog(ctx, tx, &userID, "assignment.restore", "assignments", &id, nil, nil, nil)
+		})
+		if err != nil {
+			return nil, err
+		}
+		assignment.DeletedAt = nil
+		return &assignment, nil
+
+	case "schools":
+		var school model.Schools
+		query := s.db.NewSelect().
+			Model(&school).
+			WhereAllWithDeleted().
+			Where("s.id = ?", id).
+			Where("s.deleted_at IS NOT NULL OR s.is_active = false")
+		if err := s.findDeleted(ctx, resource, query, userID, role); err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			_, err := tx.NewUpdate().
+				Model((*model.Schools)(nil)).
+				WhereAllWithDeleted().
+				Set("deleted_at = NULL").
+				Set("is_active = true").
+				Set("updated_at = ?", now).
+				Where("id = ?", id).
+				Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to restore school: %w", err)
+			}
+			return writeAuditLog(ctx, tx, &userID, "school.restore", "schools", &id, nil, nil, nil)
+		})
+		if err != nil {
+			return nil, err
+		}
+		school.DeletedAt = nil
+		school.IsActive = true
+		school.UpdatedAt = now
+		return &school, nil
+
+	case "users":
+		var user model.Users
+		if err := s.findDeleted(ctx, resource, s.db.NewSelect().Model(&user).WhereDeleted().Where("u.id = ?", id), userID, role); err != nil {
+			return nil, err
+		}
+		err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			if err := undelete(ctx, tx, (*model.Users)(nil), "id = ?", id); err != nil {
+				return err
+			}
+			if err := undelete(ctx, tx, (*model.UserProfiles)(nil), "user_id = ? AND deleted_at >= ?", id, user.DeletedAt); err != nil {
+				return err
+			}
+			return writeAuditLog(ctx, tx, &userID, "user.restore", "users", &id, nil, nil, nil)
+		})
+		if err != nil {
+			return nil, err
+		}
+		user.DeletedAt = nil
+		return &user, nil
+	}
+
+	return nil, fmt.Errorf("unknown trash type")
+}
+
+// PurgeTrashService permanently removes records deleted more than days ago, along with the stored files of
+// deleted uploads. Classrooms with attendance history, and users or schools that other records still point
+// to, are kept.
+func (s *TrashService) PurgeTrashService(ctx context.Context, days int, dryRun bool) (*TrashPurgeReport, error) {
+	report := &TrashPurgeReport{
+		DryRun: dryRun,
+		Cutoff: time.Now().AddDate(0, 0, -days),
+		Purged: map[string]int{},
+		Kept:   map[string]int{},
+	}
+
+	// Uploaded files go with the assignments and submissions they are attached to
+	uploads := make(map[uuid.UUID]string)
+	collectUploads := func(ctx context.Context, tx bun.Tx, assignmentIDs []uuid.UUID) error {
+		attached, err := attachedUploads(ctx, tx, assignmentIDs)
+		for _, upload := range attached {
+			uploads[upload.ID] = upload.FilePath
+		}
+		return err
+	}
+
+	err := s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		// Assignments first, so a classroom purged below takes only its remaining assignments with it
+		var assignmentIDs []uuid.UUID
+		err := tx.NewSelect().
+			Model((*model.Assignments)(nil)).
+			Column("id").
+			WhereDeleted().
+			Where("deleted_at < ?", report.Cutoff).
+			Scan(ctx, &assignmentIDs)
+		if err != nil {
+			return fmt.Errorf("failed to find deleted assignments: %w", err)
+		}
+		report.Purged["assignments"] = len(assignmentIDs)
+		if err := collectUploads(ctx, tx, assignmentIDs); err != nil {
+			return err
+		}
+		if !dryRun {
+			if err := purgeAssignments(ctx, tx, assignmentIDs); err != nil {
+				return err
+			}
+		}
+
+		var classroomIDs []uuid.UUID
+		err = tx.NewSelect().
+			Model((*model.Classrooms)(nil)).
+			Column("id").
+			WhereDeleted().
+			Where("deleted_at < ?", report.Cutoff).
+			Where("NOT EXISTS (SELECT 1 FROM attendance_sessions WHERE attendance_sessions.classroom_id = c.id)").
+			Scan(ctx, &classroomIDs)
+		if err != nil {
+			return fmt.Errorf("failed to find deleted classrooms: %w", err)
+		}
+		report.Purged["classrooms"] = len(classroomIDs)
+		classroomAssignments, err := classroomAssignmentIDs(ctx, tx, classroomIDs)
+		if err != nil {
+			return err
+		}
+		if err := collectUploads(ctx, tx, classroomAssignments); err != nil {
+			return err
+		}
+		if !dryRun {
+			if err := purgeClassrooms(ctx, tx, classroomIDs); err != nil {
+				return err
+			}
+		}
+
+		var userIDs []uuid.UUID
+		err = tx.NewSelect().
+			Model((*model.Users)(nil)).
+			Column("id").
+			WhereDeleted().
+			Where("deleted_at < ?", report.Cutoff).
+			Where("NOT EXISTS (SELECT 1 FROM attendance_records WHERE attendance_records.student_id = u.id)").
+			Where("NOT EXISTS (SELECT 1 FROM assignment_submissions WHERE assignment_submissions.student_id = u.id)").
+			Where("NOT EXISTS (SELECT 1 FROM classroom_students WHERE classroom_students.student_id = u.id)").
+			Where("NOT EXISTS (SELECT 1 FROM classrooms WHERE classrooms.teacher_id = u.id)").
+			Scan(ctx, &userIDs)
+		if err != nil {
+			return fmt.Errorf("failed to find deleted users: %w", err)
+		}
+		report.Purged["users"] = len(userIDs)
+		if !dryRun && len(userIDs) > 0 {
+			for _, m := range []any{(*model.UserProfiles)(nil), (*model.SessionTokens)(nil), (*model.UserSessions)(nil)} {
+				if _, err := tx.NewDelete().Model(m).Where("user_id IN (?)", bun.In(userIDs)).ForceDelete().Exec(ctx); err != nil {
+					return fmt.Errorf("failed to purge user data: %w", err)
+				}
+			}
+			if _, err := tx.NewDelete().Model((*model.Users)(nil)).Where("id IN (?)", bun.In(userIDs)).ForceDelete().Exec(ctx); err != nil {
+				return fmt.Errorf("failed to purge users: %w", err)
+			}
+		}
+
+		// Schools are deleted by deactivating them, so an inactive school counts from its last update
+		var schoolIDs []uuid.UUID
+		err = tx.NewSelect().
+			Model((*model.Schools)(nil)).
+			Column("id").
+			WhereAllWithDeleted().
+			Where("deleted_at < ? OR (deleted_at IS NULL AND is_active = false AND updated_at < ?)", report.Cutoff, report.Cutoff).
+			Where("NOT EXISTS (SELECT 1 FROM users WHERE users.school_id = s.id)").
+			Where("NOT EXISTS (SELECT 1 FROM classrooms WHERE classrooms.school_id = s.id)").
+			Scan(ctx, &schoolIDs)
+		if err != nil {
+			return fmt.Errorf("failed to find deleted schools: %w", err)
+		}
+		report.Purged["schools"] = len(schoolIDs)
+		if !dryRun && len(schoolIDs) > 0 {
+			if _, err := tx.NewDelete().Model((*model.Schools)(nil)).Where("id IN (?)", bun.In(schoolIDs)).ForceDelete().Exec(ctx); err != nil {
+				return fmt.Errorf("failed to purge schools: %w", err)
+			}
+		}
+
+		var expired []*model.FileUploads
+		err = tx.NewSelect().
+			Model(&expired).
+			Column("id", "file_path").
+			WhereDeleted().
+			Where("deleted_at < ?", report.Cutoff).
+			Scan(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to find deleted files: %w", err)
+		}
+		for _, upload := range expired {
+			uploads[upload.ID] = upload.FilePath
+		}
+		report.Purged["files"] = len(uploads)
+		if !dryRun && len(uploads) > 0 {
+			ids := make([]uuid.UUID, 0, len(uploads))
+			for id := range uploads {
+				ids = append(ids, id)
+			}
+			if _, err := tx.NewDelete().Model((*model.FileUploads)(nil)).Where("id IN (?)", bun.In(ids)).ForceDelete().Exec(ctx); err != nil {
+				return fmt.Errorf("failed to purge files: %w", err)
+			}
+		}
+
+		return s.countKept(ctx, tx, report)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Stored files are removed only once the rows pointing at them are gone for good
+	if !dryRun {
+		for _, key := range uploads {
+			if err := s.store.Delete(ctx, key); err != nil {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %s", key, err.Error()))
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// countKept counts the expired records a purge had to leave in place
+func (s *TrashService) countKept(ctx context.Context, tx bun.Tx, report *TrashPurgeReport) error {
+	expired := map[string]*bun.SelectQuery{
+		"classrooms": tx.NewSelect().Model((*model.Classrooms)(nil)).WhereDeleted().Where("deleted_at < ?", report.Cutoff),
+		"users":      tx.NewSelect().Model((*model.Users)(nil)).WhereDeleted().Where("deleted_at < ?", report.Cutoff),
+		"schools": tx.NewSelect().Model((*model.Schools)(nil)).WhereAllWithDeleted().
+			Where("deleted_at < ? OR (deleted_at IS NULL AND is_active = false AND updated_at < ?)", report.Cutoff, report.Cutoff),
+	}
+	for resource, query := range expired {
+		count, err := query.Count(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to count deleted %s: %w", resource, err)
+		}
+		// A dry run has not removed anything yet, so the purged rows are still counted here
+		if report.DryRun {
+			count -= report.Purged[resource]
+		}
+		if count > 0 {
+			report.Kept[resource] = count
+		}
+	}
+	return nil
+}
+
+// purgeAssignments hard-deletes assignments with their submissions and attached files
+func purgeAssignments(ctx context.Context, tx bun.Tx, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	for _, m := range []any{(*model.AssignmentSubmissions)(nil), (*model.AssignmentFiles)(nil)} {
+		if _, err := tx.NewDelete().Model(m).Where("assignment_id IN (?)", bun.In(ids)).ForceDelete().Exec(ctx); err != nil {
+			return fmt.Errorf("failed to purge assignment data: %w", err)
+		}
+	}
+	if _, err := tx.NewDelete().Model((*model.Assignments)(nil)).Where("id IN (?)", bun.In(ids)).ForceDelete().Exec(ctx); err != nil {
+		return fmt.Errorf("failed to purge assignments: %w", err)
+	}
+	return nil
+}
+
+// purgeClassrooms hard-deletes classrooms with their assignments, roster, schedules and invites
+func purgeClassrooms(ctx context.Context, tx bun.Tx, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	assignmentIDs, err := classroomAssignmentIDs(ctx, tx, ids)
+	if err != nil {
+		return err
+	}
+	if err := purgeAssignments(ctx, tx, assignmentIDs); err != nil {
+		return err
+	}
+
+	invites := tx.NewSelect().Model((*model.ClassroomInvites)(nil)).Column("id").Where("classroom_id IN (?)", bun.In(ids))
+	if _, err := tx.NewDelete().Model((*model.ClassroomInviteUses)(nil)).Where("invite_id IN (?)", invites).Exec(ctx); err != nil {
+		return fmt.Errorf("failed to purge invite uses: %w", err)
+	}
+
+	for _, m := range []any{
+		(*model.ClassroomInvites)(nil),
+		(*model.ClassroomWaitlist)(nil),
+		(*model.ClassroomSeatingPlans)(nil),
+		(*model.ClassroomMembers)(nil),
+		(*model.ClassroomStudents)(nil),
+		(*model.ClassSchedules)(nil),
+	} {
+		if _, err := tx.NewDelete().Model(m).Where("classroom_id IN (?)", bun.In(ids)).ForceDelete().Exec(ctx); err != nil {
+			return fmt.Errorf("failed to purge classroom data: %w", err)
+		}
+	}
+
+	if _, err := tx.NewDelete().Model((*model.Classrooms)(nil)).Where("id IN (?)", bun.In(ids)).ForceDelete().Exec(ctx); err != nil {
+		return fmt.Errorf("failed to purge classrooms: %w", err)
+	}
+	return nil
+}
+
+// classroomAssignmentIDs returns the IDs of every assignment in the classrooms, deleted or not
+func classroomAssignmentIDs(ctx context.Context, tx bun.Tx, classroomIDs []uuid.UUID) ([]uuid.UUID, error) {
+	if len(classroomIDs) == 0 {
+		return nil, nil
+	}
+	var ids []uuid.UUID
+	err := tx.NewSelect().
+		Model((*model.Assignments)(nil)).
+		Column("id").
+		WhereAllWithDeleted().
+		Where("classroom_id IN (?)", bun.In(classroomIDs)).
+		Scan(ctx, &ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find classroom assignments: %w", err)
+	}
+	return ids, nil
+}
+
+// attachedUploads returns the uploads attached to the assignments or to their submissions
+func attachedUploads(ctx context.Context, tx bun.Tx, assignmentIDs []uuid.UUID) ([]*model.FileUploads, error) {
+	if len(assignmentIDs) == 0 {
+		return nil, nil
+	}
+	submissions := tx.NewSelect().
+		Model((*model.AssignmentSubmissions)(nil)).
+		Column("id").
+		WhereAllWithDeleted().
+		Where("assignment_id IN (?)", bun.In(assignmentIDs))
+
+	var uploads []*model.FileUploads
+	err := tx.NewSelect().
+		Model(&uploads).
+		Column("id", "file_path").
+		WhereAllWithDeleted().
+		Where("(related_table = 'assignments' AND related_id IN (?)) OR (related_table = 'assignment_submissions' AND related_id IN (?))",
+			bun.In(assignmentIDs), submissions).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find attached files: %w", err)
+	}
+	return uploads, nil
+}
+
+// findDeleted scans one deleted record the caller may see
+func (s *TrashService) findDeleted(ctx context.Context, resource string, query *bun.SelectQuery, userID uuid.UUID, role string) error {
+	query, err := s.trashScope(ctx, resource, query, userID, role)
+	if err != nil {
+		return err
+	}
+	if err := query.Scan(ctx); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("item not found in trash")
+		}
+		return fmt.Errorf("failed to retrieve deleted item: %w", err)
+	}
+	return nil
+}
+
+// trashScope limits a trash query to the records the caller may see and restore
+func (s *TrashService) trashScope(ctx context.Context, resource string, query *bun.SelectQuery, userID uuid.UUID, role string) (*bun.SelectQuery, error) {
+	if role == "super_admin" {
+		return query, nil
+	}
+
+	var schoolID *uuid.UUID
+	if role == "admin" {
+		var err error
+		if schoolID, err = userSchoolID(ctx, s.db, userID); err != nil {
+			return nil, err
+		}
+		if schoolID == nil {
+			return nil, fmt.Errorf("unauthorized to view the trash")
+		}
+	}
+
+	// Deleted classrooms still own their assignments, so these subqueries include them
+	ownClassrooms := s.db.NewSelect().Model((*model.Classrooms)(nil)).Column("id").WhereAllWithDeleted()
+
+	switch {
+	case resource == "classrooms" && role == "admin":
+		return query.Where("c.school_id = ?", *schoolID), nil
+	case resource == "classrooms" && role == "teacher":
+		return query.Where("c.teacher_id = ?", userID), nil
+	case resource == "assignments" && role == "admin":
+		return query.Where("a.classroom_id IN (?)", ownClassrooms.Where("school_id = ?", *schoolID)), nil
+	case resource == "assignments" && role == "teacher":
+		// Creators keep their deleted assignments only in classrooms they still teach
+		staffOf := s.db.NewSelect().
+			Model((*model.ClassroomMembers)(nil)).
+			Column("classroom_id").
+			Where("user_id = ? AND status = 'active' AND role IN ('teacher', 'assistant')", userID)
+		return query.Where("a.classroom_id IN (?) OR (a.created_by = ? AND a.classroom_id IN (?))",
+			ownClassrooms.Where("teacher_id = ?", userID), userID, staffOf), nil
+	case resource == "schools" && role == "admin":
+		return query.Where("s.id = ?", *schoolID), nil
+	case resource == "users" && role == "admin":
+		return query.Where("u.school_id = ?", *schoolID), nil
+	}
+	return nil, fmt.Errorf("unauthorized to view the trash")
+}
+
+// undelete clears deleted_at on the soft-deleted rows of a table that match where
+func undelete(ctx context.Context, tx bun.Tx, m any, where string, args ...any) error {
+	_, err := tx.NewUpdate().
+		Model(m).
+		WhereDeleted().
+		Set("deleted_at = NULL").
+		Where(where, args...).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to restore: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/response"
+	"github.com/komkem01/easy-attend-service/utils/storage"
+	"github.com/uptrace/bun"
+)
+
+// UploadController handles file upload, download and delete endpoints
+type UploadController struct {
+	uploadService *UploadService
+}
+
+// NewUploadController creates a new upload controller
+func NewUploadController(db *bun.DB, store storage.Storage) *UploadController {
+	return &UploadController{
+		uploadService: NewUploadService(db, store),
+	}
+}
+
+// UploadAssignmentFile attaches a file to an assignment (multipart field "file", optional "description")
+func (ctrl *UploadController) UploadAssignmentFile(c *gin.Context) {
+	assignmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid assignment ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	part, description, err := readUploadPart(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	defer part.Close()
+
+	upload, err := ctrl.uploadService.UploadAssignmentFileService(c.Request.Context(), assignmentID, part, part.FileName(), description, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondUploadError(c, err, "Failed to upload file")
+		return
+	}
+
+	response.Created(c, upload)
+}
+
+// UploadSubmissionFile attaches a file to the caller's draft submission (multipart field "file")
+func (ctrl *UploadController) UploadSubmissionFile(c *gin.Context) {
+	assignmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid assignment ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	part, _, err := readUploadPart(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	defer part.Close()
+
+	upload, err := ctrl.uploadService.UploadSubmissionFileService(c.Request.Context(), assignmentID, part, part.FileName(), userUUID)
+	if err != nil {
+		respondUploadError(c, err, "Failed to upload file")
+		return
+	}
+
+	response.Created(c, upload)
+}
+
+// GetAssignmentFiles lists an assignment's attachments
+func (ctrl *UploadController) GetAssignmentFiles(c *gin.Context) {
+	assignmentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid assignment ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	files, err := ctrl.uploadService.GetAssignmentFilesService(c.Request.Context(), assignmentID, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondUploadError(c, err, "Failed to fetch files")
+		return
+	}
+
+	response.Success(c, files)
+}
+
+// GetSubmissionFiles lists the files attached to a submission
+func (ctrl *UploadController) GetSubmissionFiles(c *gin.Context) {
+	submissionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid submission ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	files, err := ctrl.uploadService.GetSubmissionFilesService(c.Request.Context(), submissionID, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondUploadError(c, err, "Failed to fetch files")
+		return
+	}
+
+	response.Success(c, files)
+}
+
+// DownloadFile sends an uploaded file to a user allowed to see it, redirecting to the storage backend
+// when it can serve the file directly
+func (ctrl *UploadController) DownloadFile(c *gin.Context) {
+	fileID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid file ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	upload, link, content, err := ctrl.uploadService.OpenFileService(c.Request.Context(), fileID, userUUID, GetUserRoleFromContext(c))
+	if err != nil {
+		respondUploadError(c, err, "Failed to download file")
+		return
+	}
+	if link != "" {
+		c.Redirect(http.StatusFound, link)
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, upload.FileSize, upload.MimeType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": upload.OriginalName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// DeleteFile removes an uploaded file
+func (ctrl *UploadController) DeleteFile(c *gin.Context) {
+	fileID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.BadRequest(c, "Invalid file ID format")
+		return
+	}
+
+	userUUID, err := GetUserUUIDFromContext(c)
+	if err != nil {
+		response.Unauthorized(c, err.Error())
+		return
+	}
+
+	if err := ctrl.uploadService.DeleteFileService(c.Request.Context(), fileID, userUUID, GetUserRoleFromContext(c)); err != nil {
+		respondUploadError(c, err, "Failed to delete file")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "File deleted successfully",
+	})
+}
+
+// readUploadPart walks a multipart body up to its "file" part without buffering the file, picking up a
+// "description" field sent before it
+func readUploadPart(c *gin.Context) (*multipart.Part, *string, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, (maxAttachmentMB+1)<<20)
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, nil, fmt.Errorf("expected a multipart/form-data upload")
+	}
+
+	var description *string
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, nil, fmt.Errorf("file is required")
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid multipart data: %s", err.Error())
+		}
+
+		switch part.FormName() {
+		case "file":
+			return part, description, nil
+		case "description":
+			value, err := io.ReadAll(io.LimitReader(part, 1000))
+			part.Close()
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid multipart data: %s", err.Error())
+			}
+			if text := strings.TrimSpace(string(value)); text != "" {
+				description = &text
+			}
+		default:
+			part.Close()
+		}
+	}
+}
+
+// respondUploadError maps upload service errors to HTTP responses
+func respondUploadError(c *gin.Context, err error, fallback string) {
+	switch err.Error() {
+	case "assignment not found", "submission not found", "file not found":
+		response.NotFound(c, err.Error())
+	case "unauthorized to manage this assignment's files", "unauthorized to access this file", "unauthorized to delete this file",
+		"student is not enrolled in this classroom":
+		response.Forbidden(c, err.Error())
+	case "submission has already been submitted", "submission was saved by another request, try again":
+		response.Conflict(c, err.Error())
+	case "file exceeds the size limit":
+		response.RequestEntityTooLarge(c, err.Error())
+	case "assignment is not published", "this assignment only accepts text", "assignment has invalid allowed file types",
+		"file is empty":
+		response.BadRequest(c, err.Error())
+	case "file type is not allowed":
+		response.UnsupportedMediaType(c, err.Error())
+	default:
+		response.InternalServerError(c, fallback+": "+err.Error())
+	}
+}
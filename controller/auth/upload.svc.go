@@ -0,0 +1,462 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/komkem01/easy-attend-service/model"
+	"github.com/komkem01/easy-attend-service/utils/filetype"
+	"github.com/komkem01/easy-attend-service/utils/storage"
+	"github.com/uptrace/bun"
+)
+
+// maxAttachmentMB caps files teachers attach to assignments; submissions use the assignment's MaxFileSizeMB
+const maxAttachmentMB = 100
+
+// downloadLinkExpiry is how long a presigned download link stays valid
+const downloadLinkExpiry = 5 * time.Minute
+
+// uploadExtension keeps a short alphanumeric extension from the original name for the stored file
+var uploadExtension = regexp.MustCompile(`^\.[A-Za-z0-9]{1,10}$`)
+
+// UploadService handles assignment and submission file uploads
+type UploadService struct {
+	db    *bun.DB
+	store storage.Storage
+}
+
+// NewUploadService creates a new upload service
+func NewUploadService(db *bun.DB, store storage.Storage) *UploadService {
+	return &UploadService{db: db, store: store}
+}
+
+// UploadAssignmentFileService streams a teacher's attachment into storage and attaches it to the assignment
+func (s *UploadService) UploadAssignmentFileService(ctx context.Context, assignmentID uuid.UUID, file io.Reader, filename string, description *string, userID uuid.UUID, role string) (*model.FileUploads, error) {
+	assignment, err := loadAssignmentWithClassroom(ctx, s.db, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := canManageAssignment(ctx, s.db, assignment, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("unauthorized to manage this assignment's files")
+	}
+
+	upload, err := s.storeUpload(ctx, file, filename, maxAttachmentMB, nil)
+	if err != nil {
+		return nil, err
+	}
+	relatedTable := "assignments"
+	upload.UploadedBy = userID
+	upload.RelatedTable = &relatedTable
+	upload.RelatedID = &assignment.ID
+	upload.Description = description
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewInsert().Model(upload).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to save file: %w", err)
+		}
+		_, err := tx.NewInsert().Model(&model.AssignmentFiles{
+			ID:           uuid.New(),
+			AssignmentID: assignment.ID,
+			FileName:     upload.OriginalName,
+			FilePath:     upload.FilePath,
+			FileSize:     int(upload.FileSize),
+			FileType:     upload.MimeType,
+			UploadedBy:   userID,
+			CreatedAt:    upload.CreatedAt,
+		}).Exec(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to attach file: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		s.store.Delete(ctx, upload.FilePath)
+		return nil, err
+	}
+
+	return upload, nil
+}
+
+// UploadSubmissionFileService streams a student's file into storage and attaches it to their draft
+// submission, creating the draft when there is none yet. The assignment's MaxFileSizeMB and
+// AllowedFileTypes apply, with the type taken from the file's content rather than its name.
+func (s *UploadService) UploadSubmissionFileService(ctx context.Context, assignmentID uuid.UUID, file io.Reader, filename string, studentID uuid.UUID) (*model.FileUploads, error) {
+	assignment, err := loadSubmittableAssignment(ctx, s.db, assignmentID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if assignment.SubmissionFormat == "text" {
+		return nil, fmt.Errorf("this assignment only accepts text")
+	}
+	allowed, err := filetype.ParseAllowed(assignment.AllowedFileTypes)
+	if err != nil {
+		return nil, fmt.Errorf("assignment has invalid allowed file types")
+	}
+
+	submission, err := findSubmission(ctx, s.db, assignmentID, studentID)
+	if err != nil {
+		return nil, err
+	}
+	if submission != nil && submission.Status != "draft" {
+		return nil, fmt.Errorf("submission has already been submitted")
+	}
+
+	limitMB := assignment.MaxFileSizeMB
+	if limitMB <= 0 {
+		limitMB = 10
+	}
+	upload, err := s.storeUpload(ctx, file, filename, limitMB, allowed)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if submission == nil {
+			submission = &model.AssignmentSubmissions{
+				ID:           uuid.New(),
+				AssignmentID: assignmentID,
+				StudentID:    studentID,
+				Status:       "draft",
+				CreatedAt:    upload.CreatedAt,
+				UpdatedAt:    upload.CreatedAt,
+			}
+			if _, err := tx.NewInsert().Model(submission).Exec(ctx); err != nil {
+				if strings.Contains(err.Error(), "duplicate key") {
+					return fmt.Errorf("submission was saved by another request, try again")
+				}
+				return fmt.Errorf("failed to create submission: %w", err)
+			}
+		}
+
+		relatedTable := "assignment_submissions"
+		upload.UploadedBy = studentID
+		upload.RelatedTable = &relatedTable
+		upload.RelatedID = &submission.ID
+		if _, err := tx.NewInsert().Model(upload).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to save file: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		s.store.Delete(ctx, upload.FilePath)
+		return nil, err
+	}
+
+	return upload, nil
+}
+
+// GetAssignmentFilesService lists the files attached to an assignment
+func (s *UploadService) GetAssignmentFilesService(ctx context.Context, assignmentID uuid.UUID, userID uuid.UUID, role string) ([]*model.FileUploads, error) {
+	assignment, err := loadAssignmentWithClassroom(ctx, s.db, assignmentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkAssignmentAccess(ctx, assignment, userID, role); err != nil {
+		return nil, err
+	}
+
+	return s.relatedFiles(ctx, "assignments", assignmentID)
+}
+
+// GetSubmissionFilesService lists the files attached to a submission, for its student and the assignment's teachers
+func (s *UploadService) GetSubmissionFilesService(ctx context.Context, submissionID uuid.UUID, userID uuid.UUID, role string) ([]*model.FileUploads, error) {
+	if _, err := s.loadAccessibleSubmission(ctx, submissionID, userID, role); err != nil {
+		return nil, err
+	}
+
+	return s.relatedFiles(ctx, "assignment_submissions", submissionID)
+}
+
+// OpenFileService returns an uploaded file for download: a short-lived direct link when the storage backend
+// can presign one, otherwise its content, which the caller closes
+func (s *UploadService) OpenFileService(ctx context.Context, fileID uuid.UUID, userID uuid.UUID, role string) (*model.FileUploads, string, io.ReadCloser, error) {
+	upload, err := s.getUpload(ctx, fileID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if upload.UploadedBy != userID {
+		switch {
+		case upload.RelatedID == nil || upload.RelatedTable == nil:
+			return nil, "", nil, fmt.Errorf("unauthorized to access this file")
+		case *upload.RelatedTable == "assignments":
+			assignment, err := loadAssignmentWithClassroom(ctx, s.db, *upload.RelatedID)
+			if err != nil {
+				return nil, "", nil, err
+			}
+			if err := s.checkAssignmentAccess(ctx, assignment, userID, role); err != nil {
+				return nil, "", nil, err
+			}
+		case *upload.RelatedTable == "assignment_submissions":
+			if _, err := s.loadAccessibleSubmission(ctx, *upload.RelatedID, userID, role); err != nil {
+				return nil, "", nil, err
+			}
+		default:
+			return nil, "", nil, fmt.Errorf("unauthorized to access this file")
+		}
+	}
+
+	link, err := s.store.Presign(ctx, upload.FilePath, downloadLinkExpiry, upload.OriginalName)
+	if err == nil {
+		return upload, link, nil, nil
+	}
+	if !errors.Is(err, storage.ErrPresignNotSupported) {
+		return nil, "", nil, fmt.Errorf("failed to sign download link: %w", err)
+	}
+
+	content, err := s.store.Get(ctx, upload.FilePath)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return upload, "", content, nil
+}
+
+// DeleteFileService removes an uploaded file. Teachers manage assignment attachments; students can remove
+// files from their own submission until it is handed in. The stored object is kept until the trash is purged.
+func (s *UploadService) DeleteFileService(ctx context.Context, fileID uuid.UUID, userID uuid.UUID, role string) error {
+	upload, err := s.getUpload(ctx, fileID)
+	if err != nil {
+		return err
+	}
+	if upload.RelatedID == nil || upload.RelatedTable == nil {
+		return fmt.Errorf("unauthorized to delete this file")
+	}
+
+	switch *upload.RelatedTable {
+	case "assignments":
+		assignment, err := loadAssignmentWithClassroom(ctx, s.db, *upload.RelatedID)
+		if err != nil {
+			return err
+		}
+		allowed, err := canManageAssignment(ctx, s.db, assignment, userID, role)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return fmt.Errorf("unauthorized to delete this file")
+		}
+	case "assignment_submissions":
+		var submission model.AssignmentSubmissions
+		err := s.db.NewSelect().
+			Model(&submission).
+			Where("asub.id = ?", *upload.RelatedID).
+			Scan(ctx)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("submission not found")
+			}
+			return fmt.Errorf("failed to retrieve submission: %w", err)
+		}
+		if submission.StudentID != userID {
+			return fmt.Errorf("unauthorized to delete this file")
+		}
+		if submission.Status != "draft" {
+			return fmt.Errorf("submission has already been submitted")
+		}
+	default:
+		return fmt.Errorf("unauthorized to delete this file")
+	}
+
+	return s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if _, err := tx.NewDelete().Model(upload).Where("id = ?", upload.ID).Exec(ctx); err != nil {
+			return fmt.Errorf("failed to delete file: %w", err)
+		}
+		if *upload.RelatedTable == "assignments" {
+			_, err := tx.NewDelete().
+				Model((*model.AssignmentFiles)(nil)).
+				Where("assignment_id = ? AND file_path = ?", *upload.RelatedID, upload.FilePath).
+				Exec(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to detach file: %w", err)
+			}
+		}
+		return writeAuditLog(ctx, tx, &userID, "file.delete", "file_uploads", &upload.ID, upload, nil, nil)
+	})
+}
+
+// storeUpload sniffs the file type from its first bytes, then streams it into storage, failing once it
+// grows past limitMB
+func (s *UploadService) storeUpload(ctx context.Context, file io.Reader, filename string, limitMB int, allowed []string) (*model.FileUploads, error) {
+	head := make([]byte, filetype.SniffLen)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	head = head[:n]
+	if n == 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+
+	mimeType := filetype.Detect(head)
+	if !filetype.Allowed(mimeType, allowed) {
+		return nil, fmt.Errorf("file type is not allowed")
+	}
+
+	name := strings.TrimSpace(filepath.Base(strings.ReplaceAll(filename, "\\", "/")))
+	if name == "" || name == "." || name == "/" {
+		name = "file"
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	if !uploadExtension.MatchString(ext) {
+		ext = ""
+	}
+
+	now := time.Now()
+	upload := &model.FileUploads{
+		ID:           uuid.New(),
+		OriginalName: name,
+		MimeType:     mimeType,
+		Category:     "assignment",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	upload.StoredName = upload.ID.String() + ext
+	upload.FilePath = path.Join("assignments", now.Format("2006/01"), upload.StoredName)
+
+	limit := int64(limitMB) << 20
+	written, err := s.store.Put(ctx, upload.FilePath, io.LimitReader(io.MultiReader(bytes.NewReader(head), file), limit+1))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, fmt.Errorf("file exceeds the size limit")
+		}
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+	if written > limit {
+		s.store.Delete(ctx, upload.FilePath)
+		return nil, fmt.Errorf("file exceeds the size limit")
+	}
+	upload.FileSize = written
+
+	return upload, nil
+}
+
+// checkAssignmentAccess lets the assignment's teachers and the classroom's members see its files;
+// students only once the assignment is published
+func (s *UploadService) checkAssignmentAccess(ctx context.Context, assignment *model.Assignments, userID uuid.UUID, role string) error {
+	allowed, err := canManageAssignment(ctx, s.db, assignment, userID, role)
+	if err != nil {
+		return err
+	}
+	if allowed {
+		return nil
+	}
+
+	member, err := s.db.NewSelect().
+		Model((*model.ClassroomMembers)(nil)).
+		Where("classroom_id = ? AND user_id = ? AND status = 'active'", assignment.ClassroomID, userID).
+		Where("role <> 'student'").
+		Exists(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check classroom membership: %w", err)
+	}
+	if member {
+		return nil
+	}
+
+	if assignment.IsPublished && assignment.Status != "archived" {
+		enrolled, err := s.db.NewSelect().
+			Model((*model.ClassroomStudents)(nil)).
+			Where("classroom_id = ? AND student_id = ? AND is_active = true", assignment.ClassroomID, userID).
+			Exists(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to check enrollment: %w", err)
+		}
+		if enrolled {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unauthorized to access this file")
+}
+
+// loadAccessibleSubmission returns a submission visible to its student and the assignment's teachers
+func (s *UploadService) loadAccessibleSubmission(ctx context.Context, submissionID uuid.UUID, userID uuid.UUID, role string) (*model.AssignmentSubmissions, error) {
+	var submission model.AssignmentSubmissions
+	err := s.db.NewSelect().
+		Model(&submission).
+		Where("asub.id = ?", submissionID).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("submission not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve submission: %w", err)
+	}
+	if submission.StudentID == userID {
+		return &submission, nil
+	}
+
+	assignment, err := loadAssignmentWithClassroom(ctx, s.db, submission.AssignmentID)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := canManageAssignment(ctx, s.db, assignment, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, fmt.Errorf("unauthorized to access this file")
+	}
+	return &submission, nil
+}
+
+func (s *UploadService) relatedFiles(ctx context.Context, table string, id uuid.UUID) ([]*model.FileUploads, error) {
+	var files []*model.FileUploads
+	err := s.db.NewSelect().
+		Model(&files).
+		Where("fu.related_table = ? AND fu.related_id = ?", table, id).
+		Order("fu.created_at ASC").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve files: %w", err)
+	}
+	return files, nil
+}
+
+func (s *UploadService) getUpload(ctx context.Context, fileID uuid.UUID) (*model.FileUploads, error) {
+	var upload model.FileUploads
+	err := s.db.NewSelect().
+		Model(&upload).
+		Where("fu.id = ?", fileID).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("file not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve file: %w", err)
+	}
+	return &upload, nil
+}
+
+// loadAssignmentWithClassroom returns a live assignment with its Classroom relation loaded
+func loadAssignmentWithClassroom(ctx context.Context, db bun.IDB, assignmentID uuid.UUID) (*model.Assignments, error) {
+	var assignment model.Assignments
+	err := db.NewSelect().
+		Model(&assignment).
+		Relation("Classroom").
+		Where("a.id = ?", assignmentID).
+		Scan(ctx)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("assignment not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve assignment: %w", err)
+	}
+	return &assignment, nil
+}
@@ -12,13 +12,24 @@ func Models() []any {
 		(*model.Prefixes)(nil),
 		(*model.Users)(nil),
 		(*model.UserProfiles)(nil),
+		(*model.AcademicYears)(nil),
+		(*model.Terms)(nil),
 		(*model.Classrooms)(nil),
 		(*model.ClassroomStudents)(nil),
 		(*model.ClassroomMembers)(nil),
+		(*model.ClassroomWaitlist)(nil),
+		(*model.ClassroomInvites)(nil),
+		(*model.ClassroomInviteUses)(nil),
+		(*model.ClassroomSeatingPlans)(nil),
+		(*model.ClassroomTransfers)(nil),
+		(*model.StudentPromotions)(nil),
+		(*model.StudentPromotionItems)(nil),
 
 		// Attendance system
 		(*model.AttendanceSessions)(nil),
 		(*model.AttendanceRecords)(nil),
+		(*model.AttendanceRecordRevisions)(nil),
+		(*model.AttendanceMakeUps)(nil),
 		(*model.AttendanceAnalytics)(nil),
 		(*model.AttendanceRecordsArchive)(nil),
 		(*model.AttendanceSessionsArchive)(nil),
@@ -35,6 +46,7 @@ func Models() []any {
 
 		// Calendar and Events
 		(*model.AcademicCalendar)(nil),
+		(*model.CalendarFeeds)(nil),
 
 		// File management
 		(*model.FileUploads)(nil),
@@ -89,6 +101,10 @@ func RawBeforeQueryMigrate() []string {
 		`CREATE TYPE classroom_status AS ENUM ('active', 'inactive', 'archived');`,
 		`CREATE TYPE classroom_role AS ENUM ('student', 'teacher', 'assistant', 'observer');`,
 		`CREATE TYPE member_status AS ENUM ('active', 'inactive', 'pending', 'removed');`,
+		`CREATE TYPE make_up_status AS ENUM ('pending', 'approved', 'rejected');`,
+		`CREATE TYPE waitlist_status AS ENUM ('waiting', 'enrolled', 'removed');`,
+		`CREATE TYPE transfer_status AS ENUM ('pending', 'approved', 'rejected', 'cancelled');`,
+		`CREATE TYPE promotion_action AS ENUM ('end_enrollment', 'enroll', 'graduate');`,
 	}
 }
 
@@ -101,12 +117,32 @@ func RawAfterQueryMigrate() []string {
 		`CREATE INDEX IF NOT EXISTS idx_users_gender_id ON users(gender_id);`,
 		`CREATE INDEX IF NOT EXISTS idx_classrooms_teacher_id ON classrooms(teacher_id);`,
 		`CREATE INDEX IF NOT EXISTS idx_classrooms_school_id ON classrooms(school_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_classrooms_term_id ON classrooms(term_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_classrooms_rolled_over_from ON classrooms(rolled_over_from);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_classrooms_rollover_term ON classrooms(rolled_over_from, term_id) WHERE rolled_over_from IS NOT NULL AND deleted_at IS NULL;`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_academic_years_school_name ON academic_years(school_id, name) WHERE deleted_at IS NULL;`,
+		`CREATE INDEX IF NOT EXISTS idx_terms_academic_year_id ON terms(academic_year_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_terms_school_dates ON terms(school_id, start_date, end_date);`,
 		`CREATE INDEX IF NOT EXISTS idx_classroom_students_classroom_id ON classroom_students(classroom_id);`,
 		`CREATE INDEX IF NOT EXISTS idx_classroom_students_student_id ON classroom_students(student_id);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_classroom_waitlist_waiting ON classroom_waitlist(classroom_id, student_id) WHERE status = 'waiting';`,
+		`CREATE INDEX IF NOT EXISTS idx_classroom_invites_classroom_id ON classroom_invites(classroom_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_classroom_invite_uses_invite_id ON classroom_invite_uses(invite_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_classroom_transfers_school_status ON classroom_transfers(school_id, status);`,
+		`CREATE INDEX IF NOT EXISTS idx_student_promotions_school_id ON student_promotions(school_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_student_promotion_items_promotion_id ON student_promotion_items(promotion_id);`,
 		`CREATE INDEX IF NOT EXISTS idx_attendance_sessions_classroom_id ON attendance_sessions(classroom_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_attendance_sessions_term_id ON attendance_sessions(term_id);`,
 		`CREATE INDEX IF NOT EXISTS idx_attendance_records_session_id ON attendance_records(session_id);`,
 		`CREATE INDEX IF NOT EXISTS idx_attendance_records_student_id ON attendance_records(student_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_attendance_record_revisions_record_id ON attendance_record_revisions(record_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_attendance_record_revisions_session_id ON attendance_record_revisions(session_id);`,
+		`CREATE INDEX IF NOT EXISTS idx_attendance_make_ups_make_up_session_id ON attendance_make_ups(make_up_session_id);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_attendance_make_ups_original_record_id ON attendance_make_ups(original_record_id) WHERE status <> 'rejected';`,
 		`CREATE INDEX IF NOT EXISTS idx_assignments_classroom_id ON assignments(classroom_id);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_assignment_submissions_assignment_student ON assignment_submissions(assignment_id, student_id) WHERE deleted_at IS NULL;`,
+		`CREATE INDEX IF NOT EXISTS idx_calendar_feeds_user_id ON calendar_feeds(user_id);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_academic_calendar_external_uid ON academic_calendar(COALESCE(school_id, '00000000-0000-0000-0000-000000000000'::uuid), external_uid) WHERE external_uid IS NOT NULL;`,
 		`CREATE INDEX IF NOT EXISTS idx_messages_sender_id ON messages(sender_id);`,
 		`CREATE INDEX IF NOT EXISTS idx_messages_recipient_id ON messages(recipient_id);`,
 		`CREATE INDEX IF NOT EXISTS idx_messages_classroom_id ON messages(classroom_id);`,
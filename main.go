@@ -25,6 +25,21 @@ func main() {
 	// Add serve command for HTTP server
 	rootCmd.AddCommand(cmd.Serve())
 
+	// Add attendance maintenance commands
+	rootCmd.AddCommand(cmd.Attendance())
+
+	// Add academic calendar commands
+	rootCmd.AddCommand(cmd.Calendar())
+
+	// Add academic term commands
+	rootCmd.AddCommand(cmd.Terms())
+
+	// Add trash maintenance commands
+	rootCmd.AddCommand(cmd.Trash())
+
+	// Add file storage commands
+	rootCmd.AddCommand(cmd.Storage())
+
 	// Add healthcheck command
 	rootCmd.AddCommand(cmd.Healthcheck())
 
@@ -21,6 +21,8 @@ type AcademicCalendar struct {
 	IsRecurring       bool       `json:"is_recurring" bun:"is_recurring,notnull,default:false"`
 	RecurrencePattern *string    `json:"recurrence_pattern" bun:"recurrence_pattern,type:jsonb"`
 	AffectsAttendance bool       `json:"affects_attendance" bun:"affects_attendance,notnull,default:true"`
+	ExternalUID       *string    `json:"external_uid,omitempty" bun:"external_uid"` // iCalendar UID of imported events
+	Revision          int        `json:"revision" bun:"revision,notnull,default:0"` // bumped on every update, for calendar feeds
 	CreatedBy         uuid.UUID  `json:"created_by" bun:"created_by,notnull,type:uuid"`
 	CreatedAt         time.Time  `json:"created_at" bun:"created_at,notnull,default:now()"`
 	UpdatedAt         time.Time  `json:"updated_at" bun:"updated_at,notnull,default:now()"`
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// AcademicYears table structure
+type AcademicYears struct {
+	bun.BaseModel `bun:"table:academic_years,alias:ay"`
+
+	ID        uuid.UUID  `json:"id" bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	SchoolID  uuid.UUID  `json:"school_id" bun:"school_id,notnull,type:uuid"`
+	Name      string     `json:"name" bun:"name,notnull"` // e.g. 2568
+	StartDate time.Time  `json:"start_date" bun:"start_date,notnull,type:date"`
+	EndDate   time.Time  `json:"end_date" bun:"end_date,notnull,type:date"`
+	CreatedAt time.Time  `json:"created_at" bun:"created_at,notnull,default:now()"`
+	UpdatedAt time.Time  `json:"updated_at" bun:"updated_at,notnull,default:now()"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bun:"deleted_at,soft_delete"`
+
+	// Relations
+	School *Schools `json:"school,omitempty" bun:"rel:belongs-to,join:school_id=id"`
+	Terms  []*Terms `json:"terms,omitempty" bun:"rel:has-many,join:id=academic_year_id"`
+}
+
+// TableName returns the table name
+func (ay *AcademicYears) TableName() string {
+	return "academic_years"
+}
@@ -19,10 +19,12 @@ type AssignmentSubmissions struct {
 	SubmittedAt    *time.Time `json:"submitted_at" bun:"submitted_at"`
 	IsLate         bool       `json:"is_late" bun:"is_late,notnull,default:false"`
 	LateMinutes    int        `json:"late_minutes" bun:"late_minutes,default:0"`
+	RawScore       *float64   `json:"raw_score" bun:"raw_score,type:numeric"` // score as graded, before the late penalty
 	Score          *float64   `json:"score" bun:"score,type:numeric"`
 	Feedback       *string    `json:"feedback" bun:"feedback"`
 	GradedBy       *uuid.UUID `json:"graded_by" bun:"graded_by,type:uuid"`
 	GradedAt       *time.Time `json:"graded_at" bun:"graded_at"`
+	ReturnedAt     *time.Time `json:"returned_at" bun:"returned_at"`
 	CreatedAt      time.Time  `json:"created_at" bun:"created_at,notnull,default:now()"`
 	UpdatedAt      time.Time  `json:"updated_at" bun:"updated_at,notnull,default:now()"`
 	DeletedAt      *time.Time `json:"deleted_at,omitempty" bun:"deleted_at,soft_delete"`
@@ -27,6 +27,7 @@ type Assignments struct {
 	AllowedFileTypes    *string    `json:"allowed_file_types" bun:"allowed_file_types,type:jsonb"`
 	IsPublished         bool       `json:"is_published" bun:"is_published,notnull,default:false"`
 	Status              string     `json:"status" bun:"status,notnull,default:'draft',type:assignment_status"`
+	Revision            int        `json:"revision" bun:"revision,notnull,default:0"` // bumped on every update, for calendar feeds
 	CreatedBy           uuid.UUID  `json:"created_by" bun:"created_by,notnull,type:uuid"`
 	CreatedAt           time.Time  `json:"created_at" bun:"created_at,notnull,default:now()"`
 	UpdatedAt           time.Time  `json:"updated_at" bun:"updated_at,notnull,default:now()"`
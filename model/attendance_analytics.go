@@ -20,6 +20,7 @@ type AttendanceAnalytics struct {
 	AbsentCount        int       `json:"absent_count" bun:"absent_count,default:0"`
 	LateCount          int       `json:"late_count" bun:"late_count,default:0"`
 	ExcusedCount       int       `json:"excused_count" bun:"excused_count,default:0"`
+	MadeUpCount        int       `json:"made_up_count" bun:"made_up_count,default:0"`
 	AttendanceRate     float64   `json:"attendance_rate" bun:"attendance_rate,default:0"`
 	AverageLateMinutes float64   `json:"average_late_minutes" bun:"average_late_minutes,default:0"`
 	CreatedAt          time.Time `json:"created_at" bun:"created_at,notnull,default:now()"`
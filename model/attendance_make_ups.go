@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// AttendanceMakeUps table structure
+type AttendanceMakeUps struct {
+	bun.BaseModel `bun:"table:attendance_make_ups,alias:amu"`
+
+	ID                uuid.UUID  `json:"id" bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	StudentID         uuid.UUID  `json:"student_id" bun:"student_id,notnull,type:uuid"`
+	OriginalSessionID uuid.UUID  `json:"original_session_id" bun:"original_session_id,notnull,type:uuid"`
+	OriginalRecordID  uuid.UUID  `json:"original_record_id" bun:"original_record_id,notnull,type:uuid"`
+	MakeUpSessionID   uuid.UUID  `json:"make_up_session_id" bun:"make_up_session_id,notnull,type:uuid"`
+	MakeUpRecordID    *uuid.UUID `json:"make_up_record_id" bun:"make_up_record_id,type:uuid"`
+	Status            string     `json:"status" bun:"status,notnull,type:make_up_status,default:'pending'"`
+	Reason            *string    `json:"reason" bun:"reason"`
+	RequestedBy       uuid.UUID  `json:"requested_by" bun:"requested_by,notnull,type:uuid"`
+	ReviewedBy        *uuid.UUID `json:"reviewed_by" bun:"reviewed_by,type:uuid"`
+	ReviewedAt        *time.Time `json:"reviewed_at" bun:"reviewed_at"`
+	ReviewNote        *string    `json:"review_note" bun:"review_note"`
+	CreatedAt         time.Time  `json:"created_at" bun:"created_at,notnull,default:now()"`
+	UpdatedAt         time.Time  `json:"updated_at" bun:"updated_at,notnull,default:now()"`
+
+	// Relations
+	Student         *Users              `json:"student,omitempty" bun:"rel:belongs-to,join:student_id=id"`
+	OriginalSession *AttendanceSessions `json:"original_session,omitempty" bun:"rel:belongs-to,join:original_session_id=id"`
+	OriginalRecord  *AttendanceRecords  `json:"original_record,omitempty" bun:"rel:belongs-to,join:original_record_id=id"`
+	MakeUpSession   *AttendanceSessions `json:"make_up_session,omitempty" bun:"rel:belongs-to,join:make_up_session_id=id"`
+	MakeUpRecord    *AttendanceRecords  `json:"make_up_record,omitempty" bun:"rel:belongs-to,join:make_up_record_id=id"`
+	Reviewer        *Users              `json:"reviewer,omitempty" bun:"rel:belongs-to,join:reviewed_by=id"`
+}
+
+// TableName returns the table name
+func (amu *AttendanceMakeUps) TableName() string {
+	return "attendance_make_ups"
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// AttendanceRecordRevisions table structure
+type AttendanceRecordRevisions struct {
+	bun.BaseModel `bun:"table:attendance_record_revisions,alias:arr"`
+
+	ID        uuid.UUID  `json:"id" bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	RecordID  uuid.UUID  `json:"record_id" bun:"record_id,notnull,type:uuid"`
+	SessionID uuid.UUID  `json:"session_id" bun:"session_id,notnull,type:uuid"`
+	StudentID uuid.UUID  `json:"student_id" bun:"student_id,notnull,type:uuid"`
+	OldStatus *string    `json:"old_status" bun:"old_status,type:attendance_status"`
+	NewStatus string     `json:"new_status" bun:"new_status,notnull,type:attendance_status"`
+	ChangedBy *uuid.UUID `json:"changed_by" bun:"changed_by,type:uuid"`
+	Method    string     `json:"method" bun:"method,notnull"`
+	Reason    *string    `json:"reason" bun:"reason"`
+	CreatedAt time.Time  `json:"created_at" bun:"created_at,notnull,default:now()"`
+
+	// Relations
+	Record  *AttendanceRecords `json:"record,omitempty" bun:"rel:belongs-to,join:record_id=id"`
+	Student *Users             `json:"student,omitempty" bun:"rel:belongs-to,join:student_id=id"`
+	Actor   *Users             `json:"actor,omitempty" bun:"rel:belongs-to,join:changed_by=id"`
+}
+
+// TableName returns the table name
+func (arr *AttendanceRecordRevisions) TableName() string {
+	return "attendance_record_revisions"
+}
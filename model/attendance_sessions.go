@@ -13,6 +13,7 @@ type AttendanceSessions struct {
 
 	ID                   uuid.UUID  `json:"id" bun:"id,pk,type:uuid,default:gen_random_uuid()"`
 	ClassroomID          uuid.UUID  `json:"classroom_id" bun:"classroom_id,notnull,type:uuid"`
+	TermID               *uuid.UUID `json:"term_id" bun:"term_id,type:uuid"`
 	Title                string     `json:"title" bun:"title,notnull"`
 	Description          *string    `json:"description" bun:"description"`
 	SessionDate          time.Time  `json:"session_date" bun:"session_date,notnull,type:date"`
@@ -28,14 +29,25 @@ type AttendanceSessions struct {
 	LateThresholdMinutes int        `json:"late_threshold_minutes" bun:"late_threshold_minutes,default:15"`
 	Location             *string    `json:"location" bun:"location"`
 	Notes                *string    `json:"notes" bun:"notes"`
+	IsLocked             bool       `json:"is_locked" bun:"is_locked,notnull,default:false"`
+	LockedBy             *uuid.UUID `json:"locked_by" bun:"locked_by,type:uuid"`
+	LockedAt             *time.Time `json:"locked_at" bun:"locked_at"`
+	UnlockedAt           *time.Time `json:"unlocked_at" bun:"unlocked_at"`
+	CancelledAt          *time.Time `json:"cancelled_at" bun:"cancelled_at"`
+	CancelledBy          *uuid.UUID `json:"cancelled_by" bun:"cancelled_by,type:uuid"`
+	CancelReason         *string    `json:"cancel_reason" bun:"cancel_reason"`
+	MakeUpForID          *uuid.UUID `json:"make_up_for_id" bun:"make_up_for_id,type:uuid"`
+	Revision             int        `json:"revision" bun:"revision,notnull,default:0"` // bumped when the session changes in calendar feeds
 	CreatedBy            uuid.UUID  `json:"created_by" bun:"created_by,notnull,type:uuid"`
 	CreatedAt            time.Time  `json:"created_at" bun:"created_at,notnull,default:now()"`
 	UpdatedAt            time.Time  `json:"updated_at" bun:"updated_at,notnull,default:now()"`
 	DeletedAt            *time.Time `json:"deleted_at,omitempty" bun:"deleted_at,soft_delete"`
 
 	// Relations
-	Classroom *Classrooms `json:"classroom,omitempty" bun:"rel:belongs-to,join:classroom_id=id"`
-	Creator   *Users      `json:"creator,omitempty" bun:"rel:belongs-to,join:created_by=id"`
+	Classroom *Classrooms         `json:"classroom,omitempty" bun:"rel:belongs-to,join:classroom_id=id"`
+	Creator   *Users              `json:"creator,omitempty" bun:"rel:belongs-to,join:created_by=id"`
+	Locker    *Users              `json:"locker,omitempty" bun:"rel:belongs-to,join:locked_by=id"`
+	MakeUpFor *AttendanceSessions `json:"make_up_for,omitempty" bun:"rel:belongs-to,join:make_up_for_id=id"`
 }
 
 // TableName returns the table name
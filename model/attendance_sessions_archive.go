@@ -13,6 +13,7 @@ type AttendanceSessionsArchive struct {
 
 	ID                   uuid.UUID  `json:"id" bun:"id,pk,type:uuid,default:gen_random_uuid()"`
 	ClassroomID          uuid.UUID  `json:"classroom_id" bun:"classroom_id,notnull,type:uuid"`
+	TermID               *uuid.UUID `json:"term_id" bun:"term_id,type:uuid"`
 	Title                string     `json:"title" bun:"title,notnull"`
 	Description          *string    `json:"description" bun:"description"`
 	SessionDate          time.Time  `json:"session_date" bun:"session_date,notnull,type:date"`
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// CalendarFeeds table structure
+type CalendarFeeds struct {
+	bun.BaseModel `bun:"table:calendar_feeds,alias:cf"`
+
+	ID             uuid.UUID  `json:"id" bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	UserID         uuid.UUID  `json:"user_id" bun:"user_id,notnull,type:uuid"`
+	ClassroomID    *uuid.UUID `json:"classroom_id" bun:"classroom_id,type:uuid"` // nil for the user's personal feed
+	Token          string     `json:"-" bun:"token,notnull,unique"`
+	LastAccessedAt *time.Time `json:"last_accessed_at" bun:"last_accessed_at"`
+	RevokedAt      *time.Time `json:"revoked_at" bun:"revoked_at"`
+	CreatedAt      time.Time  `json:"created_at" bun:"created_at,notnull,default:now()"`
+
+	// URL is the subscription address; it is built from the token and never stored
+	URL string `json:"url,omitempty" bun:"-"`
+
+	// Relations
+	User      *Users      `json:"user,omitempty" bun:"rel:belongs-to,join:user_id=id"`
+	Classroom *Classrooms `json:"classroom,omitempty" bun:"rel:belongs-to,join:classroom_id=id"`
+}
+
+// TableName returns the table name
+func (cf *CalendarFeeds) TableName() string {
+	return "calendar_feeds"
+}
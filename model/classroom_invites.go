@@ -0,0 +1,58 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// ClassroomInvites table structure
+type ClassroomInvites struct {
+	bun.BaseModel `bun:"table:classroom_invites,alias:ci"`
+
+	ID          uuid.UUID  `json:"id" bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	ClassroomID uuid.UUID  `json:"classroom_id" bun:"classroom_id,notnull,type:uuid"`
+	Role        string     `json:"role" bun:"role,notnull,default:'student',type:classroom_role"`
+	ExpiresAt   *time.Time `json:"expires_at" bun:"expires_at"`
+	MaxUses     *int       `json:"max_uses" bun:"max_uses"`
+	UseCount    int        `json:"use_count" bun:"use_count,notnull,default:0"`
+	LastUsedAt  *time.Time `json:"last_used_at" bun:"last_used_at"`
+	RevokedAt   *time.Time `json:"revoked_at" bun:"revoked_at"`
+	RevokedBy   *uuid.UUID `json:"revoked_by" bun:"revoked_by,type:uuid"`
+	CreatedBy   uuid.UUID  `json:"created_by" bun:"created_by,notnull,type:uuid"`
+	CreatedAt   time.Time  `json:"created_at" bun:"created_at,notnull,default:now()"`
+	UpdatedAt   time.Time  `json:"updated_at" bun:"updated_at,notnull,default:now()"`
+
+	// Link is the signed URL to share; it is derived from the ID and never stored
+	Link string `json:"link,omitempty" bun:"-"`
+
+	// Relations
+	Classroom *Classrooms `json:"classroom,omitempty" bun:"rel:belongs-to,join:classroom_id=id"`
+	Creator   *Users      `json:"creator,omitempty" bun:"rel:belongs-to,join:created_by=id"`
+}
+
+// TableName returns the table name
+func (ci *ClassroomInvites) TableName() string {
+	return "classroom_invites"
+}
+
+// ClassroomInviteUses table structure
+type ClassroomInviteUses struct {
+	bun.BaseModel `bun:"table:classroom_invite_uses,alias:ciu"`
+
+	ID       uuid.UUID `json:"id" bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	InviteID uuid.UUID `json:"invite_id" bun:"invite_id,notnull,type:uuid"`
+	UserID   uuid.UUID `json:"user_id" bun:"user_id,notnull,type:uuid"`
+	Result   string    `json:"result" bun:"result,notnull"`
+	UsedAt   time.Time `json:"used_at" bun:"used_at,notnull,default:now()"`
+
+	// Relations
+	Invite *ClassroomInvites `json:"invite,omitempty" bun:"rel:belongs-to,join:invite_id=id"`
+	User   *Users            `json:"user,omitempty" bun:"rel:belongs-to,join:user_id=id"`
+}
+
+// TableName returns the table name
+func (ciu *ClassroomInviteUses) TableName() string {
+	return "classroom_invite_uses"
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// ClassroomSeatingPlans table structure. Seats are labelled "row-column" (1-based); students are placed by
+// storing the label in ClassroomStudents.SeatNumber.
+type ClassroomSeatingPlans struct {
+	bun.BaseModel `bun:"table:classroom_seating_plans,alias:csp"`
+
+	ID               uuid.UUID `json:"id" bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	ClassroomID      uuid.UUID `json:"classroom_id" bun:"classroom_id,notnull,unique,type:uuid"`
+	Rows             int       `json:"rows" bun:"rows,notnull"`
+	Columns          int       `json:"columns" bun:"columns,notnull"`
+	UnavailableSeats *string   `json:"unavailable_seats" bun:"unavailable_seats,type:jsonb"` // JSON array of seat labels
+	UpdatedBy        uuid.UUID `json:"updated_by" bun:"updated_by,notnull,type:uuid"`
+	CreatedAt        time.Time `json:"created_at" bun:"created_at,notnull,default:now()"`
+	UpdatedAt        time.Time `json:"updated_at" bun:"updated_at,notnull,default:now()"`
+
+	// Relations
+	Classroom *Classrooms `json:"classroom,omitempty" bun:"rel:belongs-to,join:classroom_id=id"`
+}
+
+// TableName returns the table name
+func (csp *ClassroomSeatingPlans) TableName() string {
+	return "classroom_seating_plans"
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// ClassroomTransfers table structure. A transfer without ClassroomID covers every classroom the
+// previous teacher owns when it is approved.
+type ClassroomTransfers struct {
+	bun.BaseModel `bun:"table:classroom_transfers,alias:ctr"`
+
+	ID               uuid.UUID  `json:"id" bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	SchoolID         *uuid.UUID `json:"school_id" bun:"school_id,type:uuid"`
+	ClassroomID      *uuid.UUID `json:"classroom_id" bun:"classroom_id,type:uuid"`
+	FromTeacherID    uuid.UUID  `json:"from_teacher_id" bun:"from_teacher_id,notnull,type:uuid"`
+	ToTeacherID      uuid.UUID  `json:"to_teacher_id" bun:"to_teacher_id,notnull,type:uuid"`
+	KeepAsObserver   bool       `json:"keep_as_observer" bun:"keep_as_observer,notnull,default:false"`
+	Status           string     `json:"status" bun:"status,notnull,default:'pending',type:transfer_status"`
+	Reason           *string    `json:"reason" bun:"reason"`
+	RequestedBy      uuid.UUID  `json:"requested_by" bun:"requested_by,notnull,type:uuid"`
+	ReviewedBy       *uuid.UUID `json:"reviewed_by" bun:"reviewed_by,type:uuid"`
+	ReviewedAt       *time.Time `json:"reviewed_at" bun:"reviewed_at"`
+	ReviewNote       *string    `json:"review_note" bun:"review_note"`
+	ClassroomsMoved  int        `json:"classrooms_moved" bun:"classrooms_moved,notnull,default:0"`
+	SessionsMoved    int        `json:"sessions_moved" bun:"sessions_moved,notnull,default:0"`
+	AssignmentsMoved int        `json:"assignments_moved" bun:"assignments_moved,notnull,default:0"`
+	CreatedAt        time.Time  `json:"created_at" bun:"created_at,notnull,default:now()"`
+	UpdatedAt        time.Time  `json:"updated_at" bun:"updated_at,notnull,default:now()"`
+
+	// Relations
+	Classroom   *Classrooms `json:"classroom,omitempty" bun:"rel:belongs-to,join:classroom_id=id"`
+	FromTeacher *Users      `json:"from_teacher,omitempty" bun:"rel:belongs-to,join:from_teacher_id=id"`
+	ToTeacher   *Users      `json:"to_teacher,omitempty" bun:"rel:belongs-to,join:to_teacher_id=id"`
+}
+
+// TableName returns the table name
+func (ctr *ClassroomTransfers) TableName() string {
+	return "classroom_transfers"
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// ClassroomWaitlist table structure
+type ClassroomWaitlist struct {
+	bun.BaseModel `bun:"table:classroom_waitlist,alias:cw"`
+
+	ID          uuid.UUID  `json:"id" bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	ClassroomID uuid.UUID  `json:"classroom_id" bun:"classroom_id,notnull,type:uuid"`
+	StudentID   uuid.UUID  `json:"student_id" bun:"student_id,notnull,type:uuid"`
+	Position    int        `json:"position" bun:"position,notnull"`
+	Status      string     `json:"status" bun:"status,notnull,type:waitlist_status,default:'waiting'"`
+	EnrolledAt  *time.Time `json:"enrolled_at" bun:"enrolled_at"`
+	RemovedAt   *time.Time `json:"removed_at" bun:"removed_at"`
+	CreatedAt   time.Time  `json:"created_at" bun:"created_at,notnull,default:now()"`
+	UpdatedAt   time.Time  `json:"updated_at" bun:"updated_at,notnull,default:now()"`
+
+	// Relations
+	Classroom *Classrooms `json:"classroom,omitempty" bun:"rel:belongs-to,join:classroom_id=id"`
+	Student   *Users      `json:"student,omitempty" bun:"rel:belongs-to,join:student_id=id"`
+}
+
+// TableName returns the table name
+func (cw *ClassroomWaitlist) TableName() string {
+	return "classroom_waitlist"
+}
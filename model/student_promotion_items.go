@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// StudentPromotionItems records each change a promotion made so it can be reverted
+type StudentPromotionItems struct {
+	bun.BaseModel `bun:"table:student_promotion_items,alias:spi"`
+
+	ID                uuid.UUID  `json:"id" bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	PromotionID       uuid.UUID  `json:"promotion_id" bun:"promotion_id,notnull,type:uuid"`
+	StudentID         uuid.UUID  `json:"student_id" bun:"student_id,notnull,type:uuid"`
+	Action            string     `json:"action" bun:"action,notnull,type:promotion_action"`
+	ClassroomID       *uuid.UUID `json:"classroom_id" bun:"classroom_id,type:uuid"`
+	EnrollmentID      *uuid.UUID `json:"enrollment_id" bun:"enrollment_id,type:uuid"`
+	CreatedEnrollment bool       `json:"created_enrollment" bun:"created_enrollment,notnull,default:false"` // enroll inserted a new row rather than reactivating one
+	WasActive         bool       `json:"was_active" bun:"was_active,notnull,default:true"`                  // account state before graduation
+	CreatedAt         time.Time  `json:"created_at" bun:"created_at,notnull,default:now()"`
+
+	// Relations
+	Promotion *StudentPromotions `json:"promotion,omitempty" bun:"rel:belongs-to,join:promotion_id=id"`
+	Student   *Users             `json:"student,omitempty" bun:"rel:belongs-to,join:student_id=id"`
+	Classroom *Classrooms        `json:"classroom,omitempty" bun:"rel:belongs-to,join:classroom_id=id"`
+}
+
+// TableName returns the table name
+func (spi *StudentPromotionItems) TableName() string {
+	return "student_promotion_items"
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// StudentPromotions table structure
+type StudentPromotions struct {
+	bun.BaseModel `bun:"table:student_promotions,alias:spr"`
+
+	ID              uuid.UUID  `json:"id" bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	SchoolID        uuid.UUID  `json:"school_id" bun:"school_id,notnull,type:uuid"`
+	PerformedBy     uuid.UUID  `json:"performed_by" bun:"performed_by,notnull,type:uuid"`
+	Promoted        int        `json:"promoted" bun:"promoted,notnull,default:0"`
+	Graduated       int        `json:"graduated" bun:"graduated,notnull,default:0"`
+	Skipped         int        `json:"skipped" bun:"skipped,notnull,default:0"`
+	RevertibleUntil time.Time  `json:"revertible_until" bun:"revertible_until,notnull"`
+	RevertedAt      *time.Time `json:"reverted_at" bun:"reverted_at"`
+	RevertedBy      *uuid.UUID `json:"reverted_by" bun:"reverted_by,type:uuid"`
+	CreatedAt       time.Time  `json:"created_at" bun:"created_at,notnull,default:now()"`
+
+	// Relations
+	School    *Schools                 `json:"school,omitempty" bun:"rel:belongs-to,join:school_id=id"`
+	Performer *Users                   `json:"performer,omitempty" bun:"rel:belongs-to,join:performed_by=id"`
+	Items     []*StudentPromotionItems `json:"items,omitempty" bun:"rel:has-many,join:id=promotion_id"`
+}
+
+// TableName returns the table name
+func (spr *StudentPromotions) TableName() string {
+	return "student_promotions"
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uptrace/bun"
+)
+
+// Terms table structure
+type Terms struct {
+	bun.BaseModel `bun:"table:terms,alias:tm"`
+
+	ID             uuid.UUID  `json:"id" bun:"id,pk,type:uuid,default:gen_random_uuid()"`
+	AcademicYearID uuid.UUID  `json:"academic_year_id" bun:"academic_year_id,notnull,type:uuid"`
+	SchoolID       uuid.UUID  `json:"school_id" bun:"school_id,notnull,type:uuid"`
+	Name           string     `json:"name" bun:"name,notnull"`
+	TermNumber     int        `json:"term_number" bun:"term_number,notnull"`
+	StartDate      time.Time  `json:"start_date" bun:"start_date,notnull,type:date"`
+	EndDate        time.Time  `json:"end_date" bun:"end_date,notnull,type:date"`
+	ArchivedAt     *time.Time `json:"archived_at" bun:"archived_at"`
+	ArchivedBy     *uuid.UUID `json:"archived_by" bun:"archived_by,type:uuid"`
+	CreatedAt      time.Time  `json:"created_at" bun:"created_at,notnull,default:now()"`
+	UpdatedAt      time.Time  `json:"updated_at" bun:"updated_at,notnull,default:now()"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty" bun:"deleted_at,soft_delete"`
+
+	// Relations
+	AcademicYear *AcademicYears `json:"academic_year,omitempty" bun:"rel:belongs-to,join:academic_year_id=id"`
+	School       *Schools       `json:"school,omitempty" bun:"rel:belongs-to,join:school_id=id"`
+}
+
+// TableName returns the table name
+func (tm *Terms) TableName() string {
+	return "terms"
+}
@@ -28,6 +28,7 @@ type Users struct {
 	IsActive      bool       `json:"is_active" bun:"is_active,notnull,default:true"`
 	EmailVerified bool       `json:"email_verified" bun:"email_verified,notnull,default:false"`
 	LastLoginAt   *time.Time `json:"last_login_at" bun:"last_login_at"`
+	GraduatedAt   *time.Time `json:"graduated_at" bun:"graduated_at"` // set when a student leaves as an alumnus
 	CreatedAt     time.Time  `json:"created_at" bun:"created_at,notnull,default:now()"`
 	UpdatedAt     time.Time  `json:"updated_at" bun:"updated_at,notnull,default:now()"`
 	DeletedAt     *time.Time `json:"deleted_at,omitempty" bun:"deleted_at,soft_delete"`
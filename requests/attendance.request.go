@@ -0,0 +1,84 @@
+package requests
+
+import "github.com/google/uuid"
+
+// CheckInRequest for a student checking in with a session code or a scanned QR payload
+type CheckInRequest struct {
+	SessionID   *uuid.UUID `json:"session_id" binding:"required_with=SessionCode"`
+	SessionCode *string    `json:"session_code" binding:"required_without=QRPayload"`
+	QRPayload   *string    `json:"qr_payload" binding:"required_without=SessionCode"`
+	Location    *string    `json:"location" binding:"omitempty,max=255"`
+}
+
+// MarkAttendanceRequest for a teacher recording attendance for several students at once
+type MarkAttendanceRequest struct {
+	Records []MarkAttendanceItem `json:"records" binding:"required,min=1,dive"`
+	Reason  *string              `json:"reason" binding:"omitempty,max=500"`
+}
+
+// MarkAttendanceItem is a single student's status in MarkAttendanceRequest
+type MarkAttendanceItem struct {
+	StudentID   uuid.UUID `json:"student_id" binding:"required"`
+	Status      string    `json:"status" binding:"required,oneof=present absent late excused"`
+	LateMinutes *int      `json:"late_minutes" binding:"omitempty,min=0"`
+	Notes       *string   `json:"notes" binding:"omitempty,max=500"`
+}
+
+// UpdateAttendanceRecordRequest for a teacher correcting a single record
+type UpdateAttendanceRecordRequest struct {
+	Status      *string `json:"status" binding:"omitempty,oneof=present absent late excused"`
+	LateMinutes *int    `json:"late_minutes" binding:"omitempty,min=0"`
+	Notes       *string `json:"notes" binding:"omitempty,max=500"`
+	Reason      *string `json:"reason" binding:"omitempty,max=500"`
+}
+
+// UnlockSessionRequest for a school admin reopening a signed-off session
+type UnlockSessionRequest struct {
+	Reason string `json:"reason" binding:"required,min=5,max=500"`
+}
+
+// CancelSessionRequest for a teacher cancelling a session, optionally scheduling a make-up
+type CancelSessionRequest struct {
+	Reason *string               `json:"reason" binding:"omitempty,max=500"`
+	MakeUp *MakeUpSessionRequest `json:"make_up" binding:"omitempty"`
+}
+
+// MakeUpSessionRequest describes the session created to replace a cancelled one
+type MakeUpSessionRequest struct {
+	Title       *string `json:"title" binding:"omitempty,max=200"`
+	SessionDate string  `json:"session_date" binding:"required"` // Format: YYYY-MM-DD
+	StartTime   string  `json:"start_time" binding:"required"`   // Format: HH:MM
+	EndTime     string  `json:"end_time" binding:"required"`     // Format: HH:MM
+	Location    *string `json:"location" binding:"omitempty,max=255"`
+}
+
+// RequestMakeUpRequest for crediting an absence with attendance at another section's session.
+// StudentID is only used when a teacher files the request on a student's behalf.
+type RequestMakeUpRequest struct {
+	OriginalSessionID uuid.UUID  `json:"original_session_id" binding:"required"`
+	MakeUpSessionID   uuid.UUID  `json:"make_up_session_id" binding:"required"`
+	StudentID         *uuid.UUID `json:"student_id"`
+	Reason            *string    `json:"reason" binding:"omitempty,max=500"`
+}
+
+// ReviewMakeUpRequest for the original classroom's teacher approving or rejecting a make-up
+type ReviewMakeUpRequest struct {
+	Status string  `json:"status" binding:"required,oneof=approved rejected"`
+	Note   *string `json:"note" binding:"omitempty,max=500"`
+}
+
+// SeatAttendanceRequest for a teacher taking attendance by tapping seats on the seat map. Seated students
+// who were not tapped get UnmarkedStatus when it is set and are left alone otherwise.
+type SeatAttendanceRequest struct {
+	Seats          []SeatAttendanceItem `json:"seats" binding:"omitempty,dive"`
+	UnmarkedStatus *string              `json:"unmarked_status" binding:"omitempty,oneof=present absent late excused"`
+	Reason         *string              `json:"reason" binding:"omitempty,max=500"`
+}
+
+// SeatAttendanceItem is the status for the student in one seat
+type SeatAttendanceItem struct {
+	Seat        string  `json:"seat" binding:"required,max=20"`
+	Status      string  `json:"status" binding:"required,oneof=present absent late excused"`
+	LateMinutes *int    `json:"late_minutes" binding:"omitempty,min=0"`
+	Notes       *string `json:"notes" binding:"omitempty,max=500"`
+}
@@ -0,0 +1,29 @@
+package requests
+
+import "github.com/google/uuid"
+
+// CreateCalendarEventRequest for adding an event to the academic calendar. RRule takes an RFC 5545 recurrence
+// such as "FREQ=WEEKLY;BYDAY=MO" and ExDates lists YYYY-MM-DD dates the recurrence skips.
+type CreateCalendarEventRequest struct {
+	SchoolID          *uuid.UUID `json:"school_id"`
+	Title             string     `json:"title" binding:"required,min=1,max=200"`
+	Description       *string    `json:"description" binding:"omitempty,max=2000"`
+	EventType         string     `json:"event_type" binding:"required,oneof=session assignment_due exam holiday meeting other"`
+	StartDate         string     `json:"start_date" binding:"required"` // Format: YYYY-MM-DD
+	EndDate           *string    `json:"end_date"`                      // Format: YYYY-MM-DD
+	AffectsAttendance *bool      `json:"affects_attendance"`
+	RRule             *string    `json:"rrule" binding:"omitempty,max=500"`
+	ExDates           []string   `json:"exdates"`
+}
+
+// UpdateCalendarEventRequest for editing a calendar event; an empty RRule removes the recurrence
+type UpdateCalendarEventRequest struct {
+	Title             *string  `json:"title" binding:"omitempty,min=1,max=200"`
+	Description       *string  `json:"description" binding:"omitempty,max=2000"`
+	EventType         *string  `json:"event_type" binding:"omitempty,oneof=session assignment_due exam holiday meeting other"`
+	StartDate         *string  `json:"start_date"`
+	EndDate           *string  `json:"end_date"`
+	AffectsAttendance *bool    `json:"affects_attendance"`
+	RRule             *string  `json:"rrule" binding:"omitempty,max=500"`
+	ExDates           []string `json:"exdates"`
+}
@@ -4,29 +4,33 @@ import "github.com/google/uuid"
 
 // CreateClassroomRequest for creating new classroom
 type CreateClassroomRequest struct {
-	SchoolID    *uuid.UUID `json:"school_id" validate:"omitempty,uuid"`
-	Name        string     `json:"name" validate:"required,min=2,max=100"`
-	Subject     string     `json:"subject" validate:"required,min=2,max=50"`
-	Description *string    `json:"description" validate:"omitempty,max=500"`
-	GradeLevel  *string    `json:"grade_level" validate:"omitempty,max=20"`
-	Section     *string    `json:"section" validate:"omitempty,max=10"`
-	RoomNumber  *string    `json:"room_number" validate:"omitempty,max=20"`
-	MaxStudents *int       `json:"max_students" validate:"omitempty,min=1,max=200"`
-	Schedule    *string    `json:"schedule" validate:"omitempty"`
+	SchoolID        *uuid.UUID `json:"school_id" validate:"omitempty,uuid"`
+	TermID          *uuid.UUID `json:"term_id" validate:"omitempty,uuid"` // defaults to the school's current term
+	Name            string     `json:"name" validate:"required,min=2,max=100"`
+	Subject         string     `json:"subject" validate:"required,min=2,max=50"`
+	Description     *string    `json:"description" validate:"omitempty,max=500"`
+	GradeLevel      *string    `json:"grade_level" validate:"omitempty,max=20"`
+	Section         *string    `json:"section" validate:"omitempty,max=10"`
+	RoomNumber      *string    `json:"room_number" validate:"omitempty,max=20"`
+	MaxStudents     *int       `json:"max_students" validate:"omitempty,min=1,max=200"`
+	Schedule        *string    `json:"schedule" validate:"omitempty"`
+	RequireApproval *bool      `json:"require_approval" validate:"omitempty"`
 }
 
 // UpdateClassroomRequest for updating classroom
 type UpdateClassroomRequest struct {
-	SchoolID    *uuid.UUID `json:"school_id" validate:"omitempty,uuid"`
-	Name        *string    `json:"name" validate:"omitempty,min=2,max=100"`
-	Subject     *string    `json:"subject" validate:"omitempty,min=2,max=50"`
-	Description *string    `json:"description" validate:"omitempty,max=500"`
-	GradeLevel  *string    `json:"grade_level" validate:"omitempty,max=20"`
-	Section     *string    `json:"section" validate:"omitempty,max=10"`
-	RoomNumber  *string    `json:"room_number" validate:"omitempty,max=20"`
-	MaxStudents *int       `json:"max_students" validate:"omitempty,min=1,max=200"`
-	Schedule    *string    `json:"schedule" validate:"omitempty"`
-	IsActive    *bool      `json:"is_active" validate:"omitempty"`
+	SchoolID        *uuid.UUID `json:"school_id" validate:"omitempty,uuid"`
+	TermID          *uuid.UUID `json:"term_id" validate:"omitempty,uuid"`
+	Name            *string    `json:"name" validate:"omitempty,min=2,max=100"`
+	Subject         *string    `json:"subject" validate:"omitempty,min=2,max=50"`
+	Description     *string    `json:"description" validate:"omitempty,max=500"`
+	GradeLevel      *string    `json:"grade_level" validate:"omitempty,max=20"`
+	Section         *string    `json:"section" validate:"omitempty,max=10"`
+	RoomNumber      *string    `json:"room_number" validate:"omitempty,max=20"`
+	MaxStudents     *int       `json:"max_students" validate:"omitempty,min=1,max=200"`
+	Schedule        *string    `json:"schedule" validate:"omitempty"`
+	IsActive        *bool      `json:"is_active" validate:"omitempty"`
+	RequireApproval *bool      `json:"require_approval" validate:"omitempty"`
 }
 
 // ClassroomQueryRequest for filtering classrooms
@@ -39,4 +43,44 @@ type ClassroomQueryRequest struct {
 	Subject    *string    `json:"subject" query:"subject" validate:"omitempty,max=50"`
 	GradeLevel *string    `json:"grade_level" query:"grade_level" validate:"omitempty,max=20"`
 	IsActive   *bool      `json:"is_active" query:"is_active" validate:"omitempty"`
+	TermID     *uuid.UUID `json:"term_id" query:"term_id" validate:"omitempty,uuid"`
+	AllTerms   bool       `json:"all_terms"` // set by the controller for term_id=all, which lists every term
+}
+
+// JoinClassroomRequest for a student joining a classroom with its code
+type JoinClassroomRequest struct {
+	ClassroomCode string `json:"classroom_code" binding:"required,min=4,max=20"`
+}
+
+// ReviewJoinRequest for a teacher accepting or rejecting a pending join
+type ReviewJoinRequest struct {
+	Status string `json:"status" binding:"required,oneof=approved rejected"`
+}
+
+// CreateInviteRequest for a teacher creating a shareable invite link
+type CreateInviteRequest struct {
+	Role           *string `json:"role" binding:"omitempty,oneof=student assistant observer"`
+	ExpiresInHours *int    `json:"expires_in_hours" binding:"omitempty,min=1,max=8760"`
+	MaxUses        *int    `json:"max_uses" binding:"omitempty,min=1,max=10000"`
+}
+
+// AcceptInviteRequest for a user opening an invite link
+type AcceptInviteRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// TransferClassroomsRequest for handing one classroom, or all of a teacher's classrooms, to another
+// teacher. FromTeacherID defaults to the requesting teacher.
+type TransferClassroomsRequest struct {
+	FromTeacherID  *uuid.UUID `json:"from_teacher_id"`
+	ToTeacherID    uuid.UUID  `json:"to_teacher_id" binding:"required"`
+	ClassroomID    *uuid.UUID `json:"classroom_id"` // empty transfers every classroom
+	KeepAsObserver bool       `json:"keep_as_observer"`
+	Reason         *string    `json:"reason" binding:"omitempty,max=500"`
+}
+
+// ReviewTransferRequest for a school admin approving or rejecting a transfer
+type ReviewTransferRequest struct {
+	Status string  `json:"status" binding:"required,oneof=approved rejected"`
+	Note   *string `json:"note" binding:"omitempty,max=500"`
 }
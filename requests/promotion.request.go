@@ -0,0 +1,19 @@
+package requests
+
+import "github.com/google/uuid"
+
+// PromoteStudentsRequest for moving whole grade levels up at the end of an academic year
+type PromoteStudentsRequest struct {
+	SchoolID *uuid.UUID         `json:"school_id"`
+	Mappings []PromotionMapping `json:"mappings" binding:"required,min=1,max=100,dive"`
+	DryRun   bool               `json:"dry_run"`
+}
+
+// PromotionMapping sends the students of one grade level (and optionally one section) to their next-year
+// classrooms, or graduates them
+type PromotionMapping struct {
+	FromGradeLevel string      `json:"from_grade_level" binding:"required,max=50"` // e.g. ม.1
+	FromSection    *string     `json:"from_section" binding:"omitempty,max=50"`    // empty matches every section
+	ToClassroomIDs []uuid.UUID `json:"to_classroom_ids" binding:"max=50"`
+	Graduate       bool        `json:"graduate"`
+}
@@ -0,0 +1,57 @@
+package requests
+
+import "github.com/google/uuid"
+
+// RosterQueryRequest for listing a classroom's students
+type RosterQueryRequest struct {
+	Page   int     `json:"page" query:"page" validate:"omitempty,min=1"`
+	Limit  int     `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+	Search *string `json:"search" query:"search" validate:"omitempty,min=1,max=100"`
+	Sort   string  `json:"sort" query:"sort" validate:"omitempty,oneof=student_number name enrolled_at"`
+	Order  string  `json:"order" query:"order" validate:"omitempty,oneof=asc desc"`
+	Status string  `json:"status" query:"status" validate:"omitempty,oneof=active inactive all"`
+}
+
+// AddRosterStudentRequest for a teacher adding a student to the roster
+type AddRosterStudentRequest struct {
+	StudentID     uuid.UUID `json:"student_id" binding:"required"`
+	StudentNumber *string   `json:"student_number" binding:"omitempty,max=20"`
+	SeatNumber    *string   `json:"seat_number" binding:"omitempty,max=20"`
+	Notes         *string   `json:"notes" binding:"omitempty,max=500"`
+}
+
+// UpdateRosterStudentRequest for a teacher editing a roster entry
+type UpdateRosterStudentRequest struct {
+	StudentNumber *string `json:"student_number" binding:"omitempty,max=20"`
+	SeatNumber    *string `json:"seat_number" binding:"omitempty,max=20"`
+	Notes         *string `json:"notes" binding:"omitempty,max=500"`
+	IsActive      *bool   `json:"is_active"`
+}
+
+// ReorderWaitlistRequest lists every waiting student in the new order
+type ReorderWaitlistRequest struct {
+	StudentIDs []uuid.UUID `json:"student_ids" binding:"required,min=1"`
+}
+
+// SeatingLayoutRequest for setting a classroom's grid of seats
+type SeatingLayoutRequest struct {
+	Rows             int      `json:"rows" binding:"required,min=1,max=20"`
+	Columns          int      `json:"columns" binding:"required,min=1,max=20"`
+	UnavailableSeats []string `json:"unavailable_seats" binding:"max=400"` // seat labels such as "2-3"
+}
+
+// AssignSeatsRequest for placing students in seats
+type AssignSeatsRequest struct {
+	Assignments []SeatAssignment `json:"assignments" binding:"required,min=1,dive"`
+}
+
+// SeatAssignment places one student; an empty seat unseats them
+type SeatAssignment struct {
+	StudentID uuid.UUID `json:"student_id" binding:"required"`
+	Seat      string    `json:"seat" binding:"max=20"`
+}
+
+// ArrangeSeatsRequest for seating every student automatically
+type ArrangeSeatsRequest struct {
+	Mode string `json:"mode" binding:"required,oneof=student_number random"`
+}
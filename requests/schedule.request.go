@@ -0,0 +1,30 @@
+package requests
+
+import "github.com/google/uuid"
+
+// CreateScheduleRequest for adding a weekly slot to a classroom's timetable
+type CreateScheduleRequest struct {
+	DayOfWeek      *int    `json:"day_of_week" binding:"required,min=0,max=6"` // 0 = Sunday
+	StartTime      string  `json:"start_time" binding:"required"`              // Format: HH:MM
+	EndTime        string  `json:"end_time" binding:"required"`                // Format: HH:MM
+	RoomNumber     *string `json:"room_number" binding:"omitempty,max=20"`
+	EffectiveFrom  *string `json:"effective_from"`  // Format: YYYY-MM-DD
+	EffectiveUntil *string `json:"effective_until"` // Format: YYYY-MM-DD
+	IsActive       *bool   `json:"is_active"`
+}
+
+// UpdateScheduleRequest for editing a weekly slot; an empty string clears an effective date
+type UpdateScheduleRequest struct {
+	DayOfWeek      *int    `json:"day_of_week" binding:"omitempty,min=0,max=6"`
+	StartTime      *string `json:"start_time"`
+	EndTime        *string `json:"end_time"`
+	RoomNumber     *string `json:"room_number" binding:"omitempty,max=20"`
+	EffectiveFrom  *string `json:"effective_from"`
+	EffectiveUntil *string `json:"effective_until"`
+	IsActive       *bool   `json:"is_active"`
+}
+
+// CreateCalendarFeedRequest for issuing an iCalendar subscription URL; leave ClassroomID empty for the personal feed
+type CreateCalendarFeedRequest struct {
+	ClassroomID *uuid.UUID `json:"classroom_id"`
+}
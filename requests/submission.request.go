@@ -0,0 +1,31 @@
+package requests
+
+import "github.com/google/uuid"
+
+// SaveSubmissionRequest for creating or updating a student's draft submission
+type SaveSubmissionRequest struct {
+	SubmissionText *string `json:"submission_text" binding:"omitempty,max=20000"`
+}
+
+// GradeSubmissionRequest for grading one submission; the late penalty is applied to Score automatically
+type GradeSubmissionRequest struct {
+	Score    *float64 `json:"score" binding:"required,min=0"`
+	Feedback *string  `json:"feedback" binding:"omitempty,max=5000"`
+}
+
+// BatchGradeRequest for grading many submissions of one assignment at once
+type BatchGradeRequest struct {
+	Grades []BatchGradeItem `json:"grades" binding:"required,min=1,max=500,dive"`
+}
+
+// BatchGradeItem grades one submission in a batch
+type BatchGradeItem struct {
+	SubmissionID uuid.UUID `json:"submission_id" binding:"required"`
+	Score        *float64  `json:"score" binding:"required,min=0"`
+	Feedback     *string   `json:"feedback" binding:"omitempty,max=5000"`
+}
+
+// ReturnSubmissionsRequest for releasing grades to students; no IDs returns every graded submission
+type ReturnSubmissionsRequest struct {
+	SubmissionIDs []uuid.UUID `json:"submission_ids" binding:"max=500"`
+}
@@ -0,0 +1,43 @@
+package requests
+
+import "github.com/google/uuid"
+
+// CreateAcademicYearRequest for adding an academic year, optionally together with its terms
+type CreateAcademicYearRequest struct {
+	SchoolID  *uuid.UUID          `json:"school_id"`
+	Name      string              `json:"name" binding:"required,min=1,max=50"` // e.g. 2568
+	StartDate string              `json:"start_date" binding:"required"`        // Format: YYYY-MM-DD
+	EndDate   string              `json:"end_date" binding:"required"`          // Format: YYYY-MM-DD
+	Terms     []CreateTermRequest `json:"terms" binding:"omitempty,dive"`
+}
+
+// UpdateAcademicYearRequest for editing an academic year
+type UpdateAcademicYearRequest struct {
+	Name      *string `json:"name" binding:"omitempty,min=1,max=50"`
+	StartDate *string `json:"start_date"`
+	EndDate   *string `json:"end_date"`
+}
+
+// CreateTermRequest for adding a term to an academic year
+type CreateTermRequest struct {
+	Name       string `json:"name" binding:"required,min=1,max=50"`
+	TermNumber int    `json:"term_number" binding:"required,min=1,max=4"`
+	StartDate  string `json:"start_date" binding:"required"` // Format: YYYY-MM-DD
+	EndDate    string `json:"end_date" binding:"required"`   // Format: YYYY-MM-DD
+}
+
+// UpdateTermRequest for editing a term
+type UpdateTermRequest struct {
+	Name       *string `json:"name" binding:"omitempty,min=1,max=50"`
+	TermNumber *int    `json:"term_number" binding:"omitempty,min=1,max=4"`
+	StartDate  *string `json:"start_date"`
+	EndDate    *string `json:"end_date"`
+}
+
+// RolloverClassroomsRequest for cloning classrooms into another term; DryRun previews the result
+type RolloverClassroomsRequest struct {
+	TargetTermID    uuid.UUID   `json:"target_term_id" binding:"required"`
+	ClassroomIDs    []uuid.UUID `json:"classroom_ids" binding:"required,min=1,max=100"`
+	CopyAssignments bool        `json:"copy_assignments"` // copies become unpublished drafts with due dates shifted into the new term
+	DryRun          bool        `json:"dry_run"`
+}
@@ -67,6 +67,22 @@ func Created(ctx *gin.Context, data any) {
 	}, data})
 }
 
+// Accepted sends a 202 Accepted response for requests that still need someone to act on them
+func Accepted(ctx *gin.Context, data any) {
+	ctx.JSON(http.StatusAccepted, Response{StatusResponse{
+		Code:    202,
+		Message: "Accepted",
+	}, data})
+}
+
+// UnprocessableEntity sends a 422 response carrying details of what could not be processed
+func UnprocessableEntity(ctx *gin.Context, message string, data any) {
+	ctx.JSON(http.StatusUnprocessableEntity, Response{StatusResponse{
+		Code:    422,
+		Message: message,
+	}, data})
+}
+
 // InternalError ส่งผลลัพธ์เมื่อมีข้อผิดพลาดภายใน
 func InternalError(ctx *gin.Context, message any, payloadCode ...string) {
 	ctx.JSON(http.StatusInternalServerError, StatusResponse{
@@ -113,6 +129,30 @@ func Conflict(ctx *gin.Context, message any, payloadCode ...string) {
 	})
 }
 
+// ConflictWithData sends a 409 Conflict response carrying details of what clashed
+func ConflictWithData(ctx *gin.Context, message string, data any) {
+	ctx.JSON(http.StatusConflict, Response{StatusResponse{
+		Code:    409,
+		Message: message,
+	}, data})
+}
+
+// RequestEntityTooLarge sends a 413 Request Entity Too Large response
+func RequestEntityTooLarge(ctx *gin.Context, message any, payloadCode ...string) {
+	ctx.JSON(http.StatusRequestEntityTooLarge, StatusResponse{
+		Code:    413,
+		Message: message.(string),
+	})
+}
+
+// UnsupportedMediaType sends a 415 Unsupported Media Type response
+func UnsupportedMediaType(ctx *gin.Context, message any, payloadCode ...string) {
+	ctx.JSON(http.StatusUnsupportedMediaType, StatusResponse{
+		Code:    415,
+		Message: message.(string),
+	})
+}
+
 // InternalServerError sends a 500 Internal Server Error response
 func InternalServerError(ctx *gin.Context, message any, payloadCode ...string) {
 	ctx.JSON(http.StatusInternalServerError, StatusResponse{
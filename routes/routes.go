@@ -6,11 +6,12 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/komkem01/easy-attend-service/controller/auth"
 	"github.com/komkem01/easy-attend-service/middlewares"
+	"github.com/komkem01/easy-attend-service/utils/storage"
 	"github.com/uptrace/bun"
 )
 
-// SetupRoutes configures all the routes for the application
-func SetupRoutes(db *bun.DB) *gin.Engine {
+// SetupRoutes configures all the routes for the application; store holds uploaded files
+func SetupRoutes(db *bun.DB, store storage.Storage) *gin.Engine {
 	// Set gin mode based on environment
 	gin.SetMode(gin.ReleaseMode) // Change to gin.DebugMode for development
 
@@ -35,6 +36,15 @@ func SetupRoutes(db *bun.DB) *gin.Engine {
 	// Initialize controllers
 	classroomController := auth.NewClassroomController(classroomService)
 	assignmentController := auth.NewAssignmentController(db)
+	submissionController := auth.NewSubmissionController(db)
+	uploadController := auth.NewUploadController(db, store)
+	qrCodeController := auth.NewQRCodeController(db)
+	attendanceController := auth.NewAttendanceController(db)
+	scheduleController := auth.NewScheduleController(db)
+	calendarController := auth.NewCalendarController(db)
+	termController := auth.NewTermController(db)
+	promotionController := auth.NewPromotionController(db)
+	trashController := auth.NewTrashController(db, store)
 
 	// API version 1 routes
 	v1 := router.Group("/api/v1")
@@ -73,6 +83,9 @@ func SetupRoutes(db *bun.DB) *gin.Engine {
 			// Assignments (public read access)
 			public.GET("/assignments", assignmentController.GetAssignments)
 			public.GET("/assignments/:id", assignmentController.GetAssignment)
+
+			// iCalendar subscriptions (authenticated by the token in the URL)
+			public.GET("/ical/:file", scheduleController.GetCalendarFeed)
 		}
 
 		// Protected routes
@@ -94,6 +107,73 @@ func SetupRoutes(db *bun.DB) *gin.Engine {
 			protected.POST("/classrooms", classroomController.CreateClassroom)
 			protected.PATCH("/classrooms/:id", classroomController.UpdateClassroom)
 			protected.DELETE("/classrooms/:id", classroomController.DeleteClassroom)
+			protected.POST("/classrooms/join", classroomController.JoinClassroom)
+			protected.POST("/classrooms/rollover", classroomController.RolloverClassrooms)
+			protected.GET("/classrooms/:id/join-requests", classroomController.GetJoinRequests)
+			protected.POST("/classrooms/:id/join-requests/:member_id/review", classroomController.ReviewJoinRequest)
+			protected.GET("/classrooms/:id/students", classroomController.GetRoster)
+			protected.POST("/classrooms/:id/students", classroomController.AddRosterStudent)
+			protected.POST("/classrooms/:id/students/import", classroomController.ImportRoster)
+			protected.PATCH("/classrooms/:id/students/:student_id", classroomController.UpdateRosterStudent)
+			protected.DELETE("/classrooms/:id/students/:student_id", classroomController.RemoveRosterStudent)
+			protected.GET("/classrooms/:id/waitlist", classroomController.GetWaitlist)
+			protected.PUT("/classrooms/:id/waitlist", classroomController.ReorderWaitlist)
+			protected.DELETE("/classrooms/:id/waitlist", classroomController.ClearWaitlist)
+			protected.DELETE("/classrooms/:id/waitlist/:student_id", classroomController.RemoveFromWaitlist)
+			protected.POST("/classrooms/:id/code/rotate", classroomController.RotateClassroomCode)
+			protected.GET("/classrooms/:id/seating", classroomController.GetSeatingPlan)
+			protected.PUT("/classrooms/:id/seating", classroomController.SetSeatingLayout)
+			protected.PUT("/classrooms/:id/seating/assignments", classroomController.AssignSeats)
+			protected.POST("/classrooms/:id/seating/arrange", classroomController.ArrangeSeats)
+			protected.GET("/classrooms/:id/invites", classroomController.GetInvites)
+			protected.POST("/classrooms/:id/invites", classroomController.CreateInvite)
+			protected.GET("/classrooms/:id/invites/:invite_id/uses", classroomController.GetInviteUses)
+			protected.DELETE("/classrooms/:id/invites/:invite_id", classroomController.RevokeInvite)
+			protected.POST("/classroom-invites/accept", classroomController.AcceptInvite)
+			protected.GET("/classroom-transfers", classroomController.GetTransfers)
+			protected.POST("/classroom-transfers", classroomController.RequestTransfer)
+			protected.POST("/classroom-transfers/:id/review", classroomController.ReviewTransfer)
+			protected.POST("/classroom-transfers/:id/cancel", classroomController.CancelTransfer)
+
+			// Class schedules (weekly timetable slots)
+			protected.GET("/classrooms/:id/schedules", scheduleController.GetSchedules)
+			protected.POST("/classrooms/:id/schedules", scheduleController.CreateSchedule)
+			protected.PATCH("/classrooms/:id/schedules/:schedule_id", scheduleController.UpdateSchedule)
+			protected.DELETE("/classrooms/:id/schedules/:schedule_id", scheduleController.DeleteSchedule)
+			protected.GET("/timetable", scheduleController.GetTimetable)
+			protected.GET("/calendar-feeds", scheduleController.GetCalendarFeeds)
+			protected.POST("/calendar-feeds", scheduleController.CreateCalendarFeed)
+			protected.DELETE("/calendar-feeds/:id", scheduleController.RevokeCalendarFeed)
+
+			// Academic calendar (school events, expanded into occurrences)
+			protected.GET("/academic-calendar", calendarController.GetCalendarOccurrences)
+			protected.POST("/academic-calendar", calendarController.CreateCalendarEvent)
+			protected.POST("/academic-calendar/import", calendarController.ImportCalendar)
+			protected.GET("/academic-calendar/sources", calendarController.GetCalendarSources)
+			protected.GET("/academic-calendar/:id", calendarController.GetCalendarEvent)
+			protected.PATCH("/academic-calendar/:id", calendarController.UpdateCalendarEvent)
+			protected.DELETE("/academic-calendar/:id", calendarController.DeleteCalendarEvent)
+
+			// Academic years and terms
+			protected.GET("/academic-years", termController.GetAcademicYears)
+			protected.POST("/academic-years", termController.CreateAcademicYear)
+			protected.PATCH("/academic-years/:id", termController.UpdateAcademicYear)
+			protected.DELETE("/academic-years/:id", termController.DeleteAcademicYear)
+			protected.POST("/academic-years/:id/terms", termController.CreateTerm)
+			protected.GET("/terms/current", termController.GetCurrentTerm)
+			protected.PATCH("/terms/:id", termController.UpdateTerm)
+			protected.DELETE("/terms/:id", termController.DeleteTerm)
+			protected.POST("/terms/:id/archive", termController.ArchiveTerm)
+
+			// End-of-year promotions
+			protected.GET("/promotions", promotionController.GetPromotions)
+			protected.POST("/promotions", promotionController.PromoteStudents)
+			protected.GET("/promotions/:id", promotionController.GetPromotion)
+			protected.POST("/promotions/:id/revert", promotionController.RevertPromotion)
+
+			// Trash bin for soft-deleted records
+			protected.GET("/trash/:type", trashController.GetTrash)
+			protected.POST("/trash/:type/:id/restore", trashController.RestoreTrashItem)
 
 			// Assignments management (protected - requires authentication)
 			protected.POST("/assignments", assignmentController.CreateAssignment)
@@ -101,6 +181,47 @@ func SetupRoutes(db *bun.DB) *gin.Engine {
 			protected.DELETE("/assignments/:id", assignmentController.DeleteAssignment)
 			protected.POST("/assignments/:id/publish", assignmentController.PublishAssignment)
 
+			// Student submissions
+			protected.GET("/assignments/:id/submission", submissionController.GetMySubmission)
+			protected.PUT("/assignments/:id/submission", submissionController.SaveSubmissionDraft)
+			protected.POST("/assignments/:id/submission/submit", submissionController.SubmitSubmission)
+
+			// Grading
+			protected.GET("/assignments/:id/submissions", submissionController.GetSubmissions)
+			protected.POST("/assignments/:id/submissions/grades", submissionController.BatchGradeSubmissions)
+			protected.POST("/assignments/:id/submissions/return", submissionController.ReturnSubmissions)
+			protected.PUT("/submissions/:id/grade", submissionController.GradeSubmission)
+
+			// Assignment and submission files
+			protected.GET("/assignments/:id/files", uploadController.GetAssignmentFiles)
+			protected.POST("/assignments/:id/files", uploadController.UploadAssignmentFile)
+			protected.POST("/assignments/:id/submission/files", uploadController.UploadSubmissionFile)
+			protected.GET("/submissions/:id/files", uploadController.GetSubmissionFiles)
+			protected.GET("/files/:id", uploadController.DownloadFile)
+			protected.DELETE("/files/:id", uploadController.DeleteFile)
+
+			// QR code images (kiosk and projector views)
+			protected.GET("/attendance-sessions/:id/qrcode.png", qrCodeController.GetSessionQRCodePNG)
+			protected.GET("/attendance-sessions/:id/qrcode.svg", qrCodeController.GetSessionQRCodeSVG)
+			protected.GET("/profile/qrcode.png", qrCodeController.GetMyQRCodePNG)
+			protected.GET("/profile/qrcode.svg", qrCodeController.GetMyQRCodeSVG)
+
+			// Attendance check-in, marking and sign-off
+			protected.POST("/attendance/check-in", attendanceController.CheckIn)
+			protected.GET("/attendance-sessions/:id/records", attendanceController.GetSessionRecords)
+			protected.POST("/attendance-sessions/:id/records", attendanceController.MarkAttendance)
+			protected.POST("/attendance-sessions/:id/seats", attendanceController.MarkAttendanceBySeat)
+			protected.PATCH("/attendance-records/:id", attendanceController.UpdateAttendanceRecord)
+			protected.GET("/attendance-records/:id/revisions", attendanceController.GetRecordTimeline)
+			protected.GET("/attendance-sessions/:id/revisions", attendanceController.GetSessionRevisions)
+			protected.POST("/attendance-sessions/:id/sign-off", attendanceController.SignOffSession)
+			protected.POST("/attendance-sessions/:id/unlock", attendanceController.UnlockSession)
+			protected.POST("/attendance-sessions/:id/cancel", attendanceController.CancelSession)
+			protected.GET("/classrooms/:id/attendance-summary", attendanceController.GetClassroomAttendanceSummary)
+			protected.GET("/attendance-make-ups", attendanceController.GetMakeUps)
+			protected.POST("/attendance-make-ups", attendanceController.RequestMakeUp)
+			protected.POST("/attendance-make-ups/:id/review", attendanceController.ReviewMakeUp)
+
 			// Add more protected routes here as you develop features
 		}
 	}
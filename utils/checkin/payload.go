@@ -0,0 +1,32 @@
+package checkin
+
+import (
+	"errors"
+	"strings"
+)
+
+const payloadPrefix = "EASYATTEND"
+
+// Payload is the decoded content of a check-in or identity QR code
+type Payload struct {
+	Purpose string
+	Subject string
+	Token   string
+}
+
+// EncodePayload builds the string stored in a QR code, e.g. EASYATTEND:session:<id>:<token>
+func EncodePayload(purpose, subject, token string) string {
+	return strings.Join([]string{payloadPrefix, purpose, subject, token}, ":")
+}
+
+// DecodePayload parses a string produced by EncodePayload
+func DecodePayload(raw string) (*Payload, error) {
+	parts := strings.Split(strings.TrimSpace(raw), ":")
+	if len(parts) != 4 || parts[0] != payloadPrefix {
+		return nil, errors.New("invalid qr payload")
+	}
+	if parts[1] != PurposeSession && parts[1] != PurposeStudent {
+		return nil, errors.New("invalid qr payload")
+	}
+	return &Payload{Purpose: parts[1], Subject: parts[2], Token: parts[3]}, nil
+}
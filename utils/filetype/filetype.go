@@ -0,0 +1,81 @@
+package filetype
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// SniffLen is how many leading bytes Detect looks at
+const SniffLen = 512
+
+// byExtension maps the extensions teachers list in AllowedFileTypes to the type content sniffing reports
+// for them. Office Open XML documents are zip archives, so they sniff as application/zip.
+var byExtension = map[string]string{
+	"pdf":  "application/pdf",
+	"png":  "image/png",
+	"jpg":  "image/jpeg",
+	"jpeg": "image/jpeg",
+	"gif":  "image/gif",
+	"webp": "image/webp",
+	"bmp":  "image/bmp",
+	"txt":  "text/plain",
+	"csv":  "text/plain",
+	"md":   "text/plain",
+	"zip":  "application/zip",
+	"docx": "application/zip",
+	"xlsx": "application/zip",
+	"pptx": "application/zip",
+	"rar":  "application/x-rar-compressed",
+	"gz":   "application/x-gzip",
+	"mp3":  "audio/mpeg",
+	"wav":  "audio/wave",
+	"ogg":  "application/ogg",
+	"mp4":  "video/mp4",
+	"webm": "video/webm",
+	"avi":  "video/avi",
+}
+
+// Detect returns the MIME type of a file from its first bytes, without parameters such as charset
+func Detect(head []byte) string {
+	detected, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	return strings.TrimSpace(detected)
+}
+
+// ParseAllowed reads an assignment's AllowedFileTypes, a JSON array of extensions ("pdf") or MIME types
+// ("image/png", "image/*"). Empty means every type is allowed.
+func ParseAllowed(raw *string) ([]string, error) {
+	if raw == nil || strings.TrimSpace(*raw) == "" {
+		return nil, nil
+	}
+	var allowed []string
+	if err := json.Unmarshal([]byte(*raw), &allowed); err != nil {
+		return nil, err
+	}
+	return allowed, nil
+}
+
+// Allowed reports whether a sniffed MIME type matches one of the allowed entries
+func Allowed(mimeType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, entry := range allowed {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case strings.HasSuffix(entry, "/*"):
+			if strings.HasPrefix(mimeType, strings.TrimSuffix(entry, "*")) {
+				return true
+			}
+		case strings.Contains(entry, "/"):
+			if mimeType == entry {
+				return true
+			}
+		default:
+			if byExtension[strings.TrimPrefix(entry, ".")] == mimeType {
+				return true
+			}
+		}
+	}
+	return false
+}
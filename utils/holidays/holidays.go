@@ -0,0 +1,50 @@
+// Package holidays bundles public holiday calendars that can be imported like an uploaded .ics file.
+package holidays
+
+import (
+	_ "embed"
+	"sort"
+)
+
+// thailandICS lists Thai public holidays. Fixed-date holidays recur yearly; the Buddhist holidays follow
+// the lunar calendar, so each year's dates are listed separately and must be added as the cabinet
+// announces them. Substitution days are left to each school.
+//
+//go:embed thailand.ics
+var thailandICS []byte
+
+// Source is a bundled iCalendar dataset
+type Source struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	data        []byte
+}
+
+var sources = map[string]*Source{
+	"th-public-holidays": {
+		Name:        "th-public-holidays",
+		Description: "Thai public holidays",
+		data:        thailandICS,
+	},
+}
+
+// Lookup returns the iCalendar data of a bundled source
+func Lookup(name string) ([]byte, bool) {
+	source, ok := sources[name]
+	if !ok {
+		return nil, false
+	}
+	return source.data, true
+}
+
+// Sources lists the bundled sources by name
+func Sources() []*Source {
+	list := make([]*Source, 0, len(sources))
+	for _, source := range sources {
+		list = append(list, source)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
@@ -0,0 +1,142 @@
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	prodID         = "-//Easy Attend//Easy Attend Service//EN"
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405Z"
+	maxLineOctets  = 75
+)
+
+// Event is a single VEVENT. UID must stay the same across feed refreshes so calendar
+// clients replace the event instead of adding a copy; Sequence is a small revision counter
+// that must grow when it changes. Updated is written as LAST-MODIFIED.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Status      string // TENTATIVE, CONFIRMED or CANCELLED; empty omits the property
+	Start       time.Time
+	End         time.Time
+	AllDay      bool // Start and End are dates; End is exclusive
+	Updated     time.Time
+	Sequence    int64
+	Categories  []string
+	RRule       string      // RRULE value without the "RRULE:" name, e.g. FREQ=YEARLY
+	ExDates     []time.Time // dates or times the recurrence skips, matching AllDay
+}
+
+// Calendar is a VCALENDAR published as a subscription feed
+type Calendar struct {
+	Name      string
+	Refresh   time.Duration // suggested polling interval; zero omits it
+	Generated time.Time     // written as every event's DTSTAMP; zero means the time of encoding
+	Events    []Event
+}
+
+// Encode renders the calendar as RFC 5545 text with CRLF line endings and folded long lines
+func (c *Calendar) Encode() []byte {
+	var buf bytes.Buffer
+
+	writeLine(&buf, "BEGIN:VCALENDAR")
+	writeLine(&buf, "VERSION:2.0")
+	writeLine(&buf, "PRODID:"+prodID)
+	writeLine(&buf, "CALSCALE:GREGORIAN")
+	writeLine(&buf, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(&buf, "X-WR-CALNAME:"+escapeText(c.Name))
+	}
+	if c.Refresh > 0 {
+		interval := fmt.Sprintf("PT%dM", int(c.Refresh.Minutes()))
+		writeLine(&buf, "REFRESH-INTERVAL;VALUE=DURATION:"+interval)
+		writeLine(&buf, "X-PUBLISHED-TTL:"+interval)
+	}
+
+	generated := c.Generated
+	if generated.IsZero() {
+		generated = time.Now()
+	}
+
+	for _, event := range c.Events {
+		writeLine(&buf, "BEGIN:VEVENT")
+		writeLine(&buf, "UID:"+event.UID)
+		writeLine(&buf, "DTSTAMP:"+generated.UTC().Format(dateTimeFormat))
+		if !event.Updated.IsZero() {
+			writeLine(&buf, "LAST-MODIFIED:"+event.Updated.UTC().Format(dateTimeFormat))
+		}
+		if event.AllDay {
+			writeLine(&buf, "DTSTART;VALUE=DATE:"+event.Start.Format(dateFormat))
+			writeLine(&buf, "DTEND;VALUE=DATE:"+event.End.Format(dateFormat))
+		} else {
+			writeLine(&buf, "DTSTART:"+event.Start.UTC().Format(dateTimeFormat))
+			writeLine(&buf, "DTEND:"+event.End.UTC().Format(dateTimeFormat))
+		}
+		writeLine(&buf, "SUMMARY:"+escapeText(event.Summary))
+		if event.Description != "" {
+			writeLine(&buf, "DESCRIPTION:"+escapeText(event.Description))
+		}
+		if event.Location != "" {
+			writeLine(&buf, "LOCATION:"+escapeText(event.Location))
+		}
+		if event.Status != "" {
+			writeLine(&buf, "STATUS:"+event.Status)
+		}
+		if len(event.Categories) > 0 {
+			categories := make([]string, len(event.Categories))
+			for i, category := range event.Categories {
+				categories[i] = escapeText(category)
+			}
+			writeLine(&buf, "CATEGORIES:"+strings.Join(categories, ","))
+		}
+		if event.RRule != "" {
+			writeLine(&buf, "RRULE:"+event.RRule)
+		}
+		for _, exDate := range event.ExDates {
+			if event.AllDay {
+				writeLine(&buf, "EXDATE;VALUE=DATE:"+exDate.Format(dateFormat))
+			} else {
+				writeLine(&buf, "EXDATE:"+exDate.UTC().Format(dateTimeFormat))
+			}
+		}
+		writeLine(&buf, fmt.Sprintf("SEQUENCE:%d", event.Sequence))
+		writeLine(&buf, "END:VEVENT")
+	}
+
+	writeLine(&buf, "END:VCALENDAR")
+	return buf.Bytes()
+}
+
+// escapeText escapes a TEXT value (RFC 5545 section 3.3.11)
+func escapeText(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, ";", "\\;")
+	s = strings.ReplaceAll(s, ",", "\\,")
+	s = strings.ReplaceAll(s, "\r\n", "\\n")
+	s = strings.ReplaceAll(s, "\n", "\\n")
+	return s
+}
+
+// writeLine writes a content line, folding it at 75 octets without splitting a UTF-8 character
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit
+		limit = maxLineOctets - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
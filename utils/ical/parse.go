@@ -0,0 +1,326 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCalendar is wrapped by every error Parse returns for malformed input
+var ErrInvalidCalendar = errors.New("invalid iCalendar file")
+
+const maxContentLine = 1 << 20
+
+// Parse reads the VEVENT components of an iCalendar document. Times without a zone are read in loc,
+// as are times whose TZID is not a known IANA zone. Events missing DTEND get one from DURATION, or
+// last one day (all-day) or no time at all. Other components such as VTIMEZONE and VALARM are skipped.
+func Parse(r io.Reader, loc *time.Location) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events     []Event
+		event      *Event
+		duration   time.Duration
+		hasDur     bool
+		seenCal    bool
+		components []string
+	)
+	for _, l := range lines {
+		name, params, value, ok := splitContentLine(l.text)
+		if !ok {
+			return nil, fmt.Errorf("%w: line %d is malformed", ErrInvalidCalendar, l.number)
+		}
+
+		switch name {
+		case "BEGIN":
+			component := strings.ToUpper(value)
+			if component == "VCALENDAR" {
+				seenCal = true
+			}
+			if component == "VEVENT" && len(components) == 1 && components[0] == "VCALENDAR" {
+				event = &Event{}
+				duration, hasDur = 0, false
+			}
+			components = append(components, component)
+			continue
+		case "END":
+			component := strings.ToUpper(value)
+			if len(components) == 0 || components[len(components)-1] != component {
+				return nil, fmt.Errorf("%w: line %d closes %s that was not opened", ErrInvalidCalendar, l.number, value)
+			}
+			components = components[:len(components)-1]
+			if component == "VEVENT" && event != nil && len(components) == 1 {
+				if event.Start.IsZero() {
+					return nil, fmt.Errorf("%w: event ending on line %d has no DTSTART", ErrInvalidCalendar, l.number)
+				}
+				if event.End.IsZero() {
+					switch {
+					case hasDur:
+						event.End = event.Start.Add(duration)
+					case event.AllDay:
+						event.End = event.Start.AddDate(0, 0, 1)
+					default:
+						event.End = event.Start
+					}
+				}
+				events = append(events, *event)
+				event = nil
+			}
+			continue
+		}
+
+		// Only properties of the VEVENT itself matter; nested VALARMs and the like are skipped
+		if event == nil || len(components) != 2 {
+			continue
+		}
+
+		switch name {
+		case "UID":
+			event.UID = strings.TrimSpace(value)
+		case "SUMMARY":
+			event.Summary = unescapeText(value)
+		case "DESCRIPTION":
+			event.Description = unescapeText(value)
+		case "LOCATION":
+			event.Location = unescapeText(value)
+		case "STATUS":
+			event.Status = strings.ToUpper(strings.TrimSpace(value))
+		case "DTSTART":
+			event.Start, event.AllDay, err = parseDateValue(params, value, loc)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d has an invalid DTSTART", ErrInvalidCalendar, l.number)
+			}
+		case "DTEND":
+			event.End, _, err = parseDateValue(params, value, loc)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d has an invalid DTEND", ErrInvalidCalendar, l.number)
+			}
+		case "DURATION":
+			duration, err = parseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d has an invalid DURATION", ErrInvalidCalendar, l.number)
+			}
+			hasDur = true
+		case "CATEGORIES":
+			for _, category := range splitEscaped(value, ',') {
+				if category = strings.TrimSpace(unescapeText(category)); category != "" {
+					event.Categories = append(event.Categories, category)
+				}
+			}
+		case "RRULE":
+			event.RRule = strings.TrimSpace(value)
+		case "EXDATE":
+			for _, item := range strings.Split(value, ",") {
+				exDate, _, err := parseDateValue(params, item, loc)
+				if err != nil {
+					return nil, fmt.Errorf("%w: line %d has an invalid EXDATE", ErrInvalidCalendar, l.number)
+				}
+				event.ExDates = append(event.ExDates, exDate)
+			}
+		case "LAST-MODIFIED":
+			if updated, _, err := parseDateValue(params, value, loc); err == nil {
+				event.Updated = updated
+			}
+		case "DTSTAMP":
+			if updated, _, err := parseDateValue(params, value, loc); err == nil && event.Updated.IsZero() {
+				event.Updated = updated
+			}
+		case "SEQUENCE":
+			if sequence, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+				event.Sequence = sequence
+			}
+		}
+	}
+
+	if !seenCal {
+		return nil, fmt.Errorf("%w: missing BEGIN:VCALENDAR", ErrInvalidCalendar)
+	}
+	if len(components) != 0 {
+		return nil, fmt.Errorf("%w: %s is not closed", ErrInvalidCalendar, components[len(components)-1])
+	}
+	return events, nil
+}
+
+type contentLine struct {
+	number int
+	text   string
+}
+
+// unfold joins folded lines (RFC 5545 section 3.1) and drops blank ones
+func unfold(r io.Reader) ([]contentLine, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxContentLine)
+
+	var lines []contentLine
+	number := 0
+	for scanner.Scan() {
+		number++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if number == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t") {
+			if len(lines) == 0 {
+				return nil, fmt.Errorf("%w: line %d continues nothing", ErrInvalidCalendar, number)
+			}
+			lines[len(lines)-1].text += text[1:]
+			continue
+		}
+		if text != "" {
+			lines = append(lines, contentLine{number: number, text: text})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+	}
+	return lines, nil
+}
+
+// splitContentLine splits "NAME;PARAM=x;PARAM="y:z":value" into its parts; parameter values may be quoted
+func splitContentLine(line string) (name string, params map[string]string, value string, ok bool) {
+	params = make(map[string]string)
+	quoted := false
+	start := 0
+	var fields []string
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"':
+			quoted = !quoted
+		case ';', ':':
+			if quoted {
+				continue
+			}
+			fields = append(fields, line[start:i])
+			start = i + 1
+			if line[i] == ':' {
+				value = line[i+1:]
+				name = strings.ToUpper(strings.TrimSpace(fields[0]))
+				for _, field := range fields[1:] {
+					key, val, found := strings.Cut(field, "=")
+					if !found {
+						return "", nil, "", false
+					}
+					params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+				}
+				return name, params, value, name != ""
+			}
+		}
+	}
+	return "", nil, "", false
+}
+
+// parseDateValue parses a DATE or DATE-TIME value. Dates come back as UTC midnight.
+func parseDateValue(params map[string]string, value string, loc *time.Location) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+	if strings.EqualFold(params["VALUE"], "DATE") || len(value) == len(dateFormat) {
+		t, err := time.Parse(dateFormat, value)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(dateTimeFormat, value)
+		return t, false, err
+	}
+
+	zone := loc
+	if tzid := params["TZID"]; tzid != "" {
+		if named, err := time.LoadLocation(strings.TrimPrefix(tzid, "/")); err == nil {
+			zone = named
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, zone)
+	return t, false, err
+}
+
+// parseDuration parses a non-negative RFC 5545 duration such as P1D, PT1H30M or P2W
+func parseDuration(value string) (time.Duration, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "+")
+	if !strings.HasPrefix(value, "P") || len(value) < 3 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	var total time.Duration
+	inTime := false
+	number := ""
+	for _, r := range value[1:] {
+		switch {
+		case r >= '0' && r <= '9':
+			number += string(r)
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		}
+
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		number = ""
+		switch {
+		case r == 'W' && !inTime:
+			total += time.Duration(n) * 7 * 24 * time.Hour
+		case r == 'D' && !inTime:
+			total += time.Duration(n) * 24 * time.Hour
+		case r == 'H' && inTime:
+			total += time.Duration(n) * time.Hour
+		case r == 'M' && inTime:
+			total += time.Duration(n) * time.Minute
+		case r == 'S' && inTime:
+			total += time.Duration(n) * time.Second
+		default:
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+	}
+	if number != "" {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return total, nil
+}
+
+// splitEscaped splits on sep, ignoring separators escaped with a backslash
+func splitEscaped(value string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' {
+			i++
+			continue
+		}
+		if value[i] == sep {
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, value[start:])
+}
+
+// unescapeText reverses escapeText
+func unescapeText(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package invite
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+)
+
+// ErrInvalidToken is returned for tokens that are malformed or carry a bad signature
+var ErrInvalidToken = errors.New("invalid invite token")
+
+// Sign returns the token embedded in an invite link: the invite ID followed by its HMAC signature
+func Sign(inviteID uuid.UUID) string {
+	id := base64.RawURLEncoding.EncodeToString(inviteID[:])
+	return id + "." + signature(id)
+}
+
+// Parse verifies a token and returns the invite ID it was issued for
+func Parse(token string) (uuid.UUID, error) {
+	id, sig, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok || !hmac.Equal([]byte(signature(id)), []byte(sig)) {
+		return uuid.Nil, ErrInvalidToken
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil {
+		return uuid.Nil, ErrInvalidToken
+	}
+	inviteID, err := uuid.FromBytes(raw)
+	if err != nil {
+		return uuid.Nil, ErrInvalidToken
+	}
+	return inviteID, nil
+}
+
+// Link builds the shareable URL for a token (INVITE_BASE_URL, default http://localhost:3000/join)
+func Link(token string) string {
+	godotenv.Load()
+
+	base := os.Getenv("INVITE_BASE_URL")
+	if base == "" {
+		base = "http://localhost:3000/join"
+	}
+
+	separator := "?"
+	if strings.Contains(base, "?") {
+		separator = "&"
+	}
+	return base + separator + "invite=" + url.QueryEscape(token)
+}
+
+func signature(id string) string {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("JWT_SECRET")))
+	mac.Write([]byte("classroom-invite|" + id))
+	return hex.EncodeToString(mac.Sum(nil)[:12])
+}
@@ -0,0 +1,139 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/qr"
+)
+
+const (
+	// DefaultSize is the default image width/height in pixels
+	DefaultSize = 256
+	// MinSize is the smallest image size accepted
+	MinSize = 64
+	// MaxSize is the largest image size accepted
+	MaxSize = 2048
+
+	// quietZone is the number of blank modules around the symbol (required by the QR spec)
+	quietZone = 4
+)
+
+// ParseLevel converts L, M, Q or H into an error correction level (defaults to M)
+func ParseLevel(level string) (qr.ErrorCorrectionLevel, error) {
+	switch strings.ToUpper(strings.TrimSpace(level)) {
+	case "", "M":
+		return qr.M, nil
+	case "L":
+		return qr.L, nil
+	case "Q":
+		return qr.Q, nil
+	case "H":
+		return qr.H, nil
+	}
+	return qr.M, errors.New("invalid error correction level")
+}
+
+// ValidateSize checks that size is within the supported range
+func ValidateSize(size int) error {
+	if size < MinSize || size > MaxSize {
+		return fmt.Errorf("size must be between %d and %d", MinSize, MaxSize)
+	}
+	return nil
+}
+
+// PNG renders payload as a size x size PNG image
+func PNG(payload string, size int, level qr.ErrorCorrectionLevel) ([]byte, error) {
+	code, modulePx, offset, err := encode(payload, size, level)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewGray(image.Rect(0, 0, size, size))
+	for i := range img.Pix {
+		img.Pix[i] = 0xFF
+	}
+
+	dim := code.Bounds().Dx()
+	for y := 0; y < dim; y++ {
+		for x := 0; x < dim; x++ {
+			if !isDark(code, x, y) {
+				continue
+			}
+			px := offset + (x+quietZone)*modulePx
+			py := offset + (y+quietZone)*modulePx
+			for dy := 0; dy < modulePx; dy++ {
+				for dx := 0; dx < modulePx; dx++ {
+					img.SetGray(px+dx, py+dy, color.Gray{Y: 0})
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG renders payload as a size x size SVG document
+func SVG(payload string, size int, level qr.ErrorCorrectionLevel) ([]byte, error) {
+	code, _, _, err := encode(payload, size, level)
+	if err != nil {
+		return nil, err
+	}
+
+	dim := code.Bounds().Dx()
+	total := dim + 2*quietZone
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, total, total)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#ffffff"/>`, total, total)
+	buf.WriteString(`<path fill="#000000" d="`)
+	for y := 0; y < dim; y++ {
+		for x := 0; x < dim; x++ {
+			if isDark(code, x, y) {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x+quietZone, y+quietZone)
+			}
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+
+	return buf.Bytes(), nil
+}
+
+// encode builds the QR symbol and works out how many pixels each module gets
+func encode(payload string, size int, level qr.ErrorCorrectionLevel) (barcode.Barcode, int, int, error) {
+	if payload == "" {
+		return nil, 0, 0, errors.New("payload is empty")
+	}
+	if err := ValidateSize(size); err != nil {
+		return nil, 0, 0, err
+	}
+
+	code, err := qr.Encode(payload, level, qr.Auto)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("failed to encode qr code: %w", err)
+	}
+
+	total := code.Bounds().Dx() + 2*quietZone
+	modulePx := size / total
+	if modulePx < 1 {
+		return nil, 0, 0, fmt.Errorf("size %d is too small for this payload", size)
+	}
+	offset := (size - modulePx*total) / 2
+
+	return code, modulePx, offset, nil
+}
+
+func isDark(code barcode.Barcode, x, y int) bool {
+	return color.GrayModel.Convert(code.At(x, y)).(color.Gray).Y < 128
+}
@@ -0,0 +1,486 @@
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dateFormat = "2006-01-02"
+
+	// maxEmptyPeriods bounds expansion of rules that never match (e.g. Feb 30 every year)
+	maxEmptyPeriods = 5000
+)
+
+var (
+	ErrInvalidFrequency = errors.New("invalid recurrence frequency")
+	ErrInvalidInterval  = errors.New("invalid recurrence interval")
+	ErrInvalidDay       = errors.New("invalid recurrence day")
+	ErrInvalidMonthDay  = errors.New("invalid recurrence month day")
+	ErrInvalidMonth     = errors.New("invalid recurrence month")
+	ErrInvalidCount     = errors.New("invalid recurrence count")
+	ErrInvalidUntil     = errors.New("invalid recurrence until date")
+	ErrInvalidExDate    = errors.New("invalid recurrence exception date")
+	ErrInvalidRule      = errors.New("invalid recurrence rule")
+)
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Rule is a date-based subset of an RFC 5545 RRULE plus exception dates. It is stored as JSON in
+// academic_calendar.recurrence_pattern. As in RFC 5545, BY parts expand the set of dates when they name
+// something smaller than the frequency (BYDAY in a WEEKLY rule) and limit it otherwise (BYMONTH in a
+// DAILY rule); BYDAY only limits a MONTHLY or YEARLY rule that also has BYMONTHDAY.
+type Rule struct {
+	Freq       string   `json:"freq"`                   // DAILY, WEEKLY, MONTHLY or YEARLY
+	Interval   int      `json:"interval,omitempty"`     // every n periods; 0 means 1
+	ByDay      []string `json:"by_day,omitempty"`       // MO..SU; MONTHLY/YEARLY accept an ordinal such as 1MO or -1FR
+	ByMonthDay []int    `json:"by_month_day,omitempty"` // 1..31 or -31..-1 counted from the end of the month; not WEEKLY
+	ByMonth    []int    `json:"by_month,omitempty"`     // 1..12
+	Count      int      `json:"count,omitempty"`        // total occurrences including excluded ones; 0 means unlimited
+	Until      string   `json:"until,omitempty"`        // YYYY-MM-DD, inclusive
+	ExDates    []string `json:"exdates,omitempty"`      // YYYY-MM-DD dates to skip
+}
+
+// ParseRRule parses an RRULE value such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;UNTIL=20270331".
+// A leading "RRULE:" is ignored. Time-of-day parts of UNTIL are dropped.
+func ParseRRule(value string) (*Rule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return nil, ErrInvalidRule
+	}
+
+	rule := &Rule{}
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, ErrInvalidRule
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(val)
+		case "INTERVAL":
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, ErrInvalidInterval
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(val)
+			if err != nil {
+				return nil, ErrInvalidCount
+			}
+			rule.Count = n
+		case "UNTIL":
+			if len(val) < 8 {
+				return nil, ErrInvalidUntil
+			}
+			until, err := time.Parse("20060102", val[:8])
+			if err != nil {
+				return nil, ErrInvalidUntil
+			}
+			rule.Until = until.Format(dateFormat)
+		case "BYDAY":
+			rule.ByDay = strings.Split(strings.ToUpper(val), ",")
+		case "BYMONTHDAY":
+			days, err := parseInts(val)
+			if err != nil {
+				return nil, ErrInvalidMonthDay
+			}
+			rule.ByMonthDay = days
+		case "BYMONTH":
+			months, err := parseInts(val)
+			if err != nil {
+				return nil, ErrInvalidMonth
+			}
+			rule.ByMonth = months
+		case "WKST":
+			// Weeks always start on Monday, the RFC 5545 default
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %s", ErrInvalidRule, key)
+		}
+	}
+
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// String renders the rule as RRULE text (without exception dates), the inverse of ParseRRule
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		parts = append(parts, "BYDAY="+strings.Join(r.ByDay, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(r.ByMonth))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != "" {
+		parts = append(parts, "UNTIL="+strings.ReplaceAll(r.Until, "-", ""))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Validate checks every part of the rule
+func (r *Rule) Validate() error {
+	switch r.Freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return ErrInvalidFrequency
+	}
+	if r.Interval < 0 {
+		return ErrInvalidInterval
+	}
+	if r.Count < 0 {
+		return ErrInvalidCount
+	}
+	for _, day := range r.ByDay {
+		ordinal, _, err := parseByDay(day)
+		if err != nil {
+			return err
+		}
+		if ordinal != 0 && r.Freq != "MONTHLY" && r.Freq != "YEARLY" {
+			return ErrInvalidDay
+		}
+		// An ordinal in a YEARLY rule without BYMONTH counts weeks of the year, which is not supported
+		if ordinal != 0 && r.Freq == "YEARLY" && len(r.ByMonth) == 0 {
+			return fmt.Errorf("%w: an ordinal BYDAY in a YEARLY rule requires BYMONTH", ErrInvalidDay)
+		}
+	}
+	for _, day := range r.ByMonthDay {
+		if day == 0 || day < -31 || day > 31 {
+			return ErrInvalidMonthDay
+		}
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq == "WEEKLY" {
+		return fmt.Errorf("%w: BYMONTHDAY cannot be used in a WEEKLY rule", ErrInvalidMonthDay)
+	}
+	for _, month := range r.ByMonth {
+		if month < 1 || month > 12 {
+			return ErrInvalidMonth
+		}
+	}
+	if r.Until != "" {
+		if _, err := time.Parse(dateFormat, r.Until); err != nil {
+			return ErrInvalidUntil
+		}
+	}
+	for _, date := range r.ExDates {
+		if _, err := time.Parse(dateFormat, date); err != nil {
+			return ErrInvalidExDate
+		}
+	}
+	return nil
+}
+
+// Occurrences returns the dates (UTC midnight) on which an event starting on start recurs, limited to
+// [from, to]. Only dates the rule generates are returned, so a start date that does not match BYDAY
+// or BYMONTHDAY is not itself an occurrence.
+func (r *Rule) Occurrences(start, from, to time.Time) []time.Time {
+	start = dateOnly(start)
+	from = dateOnly(from)
+	to = dateOnly(to)
+
+	last := to
+	if r.Until != "" {
+		if until, err := time.Parse(dateFormat, r.Until); err == nil && until.Before(last) {
+			last = until
+		}
+	}
+
+	excluded := make(map[string]bool, len(r.ExDates))
+	for _, date := range r.ExDates {
+		excluded[date] = true
+	}
+
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	// Without COUNT the periods before from need not be walked, so skip to the one containing from
+	first := 0
+	if r.Count == 0 && from.After(start) {
+		first = r.periodsBetween(start, from) / interval
+	}
+
+	var dates []time.Time
+	emitted := 0
+	empty := 0
+	for period := first; empty < maxEmptyPeriods; period++ {
+		candidates := r.candidates(start, period*interval)
+		if len(candidates) == 0 {
+			empty++
+			continue
+		}
+		empty = 0
+		if candidates[0].After(last) {
+			break
+		}
+
+		for _, date := range candidates {
+			if date.Before(start) {
+				continue
+			}
+			if date.After(last) || (r.Count > 0 && emitted >= r.Count) {
+				return dates
+			}
+			emitted++
+			if !date.Before(from) && !excluded[date.Format(dateFormat)] {
+				dates = append(dates, date)
+			}
+		}
+	}
+
+	return dates
+}
+
+// periodsBetween counts the whole rule periods (days, weeks, months or years) from the one containing
+// start to the one containing date
+func (r *Rule) periodsBetween(start, date time.Time) int {
+	switch r.Freq {
+	case "DAILY":
+		return int(date.Sub(start).Hours() / 24)
+	case "WEEKLY":
+		startMonday := start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		dateMonday := date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+		return int(dateMonday.Sub(startMonday).Hours() / 24 / 7)
+	case "MONTHLY":
+		return (date.Year()-start.Year())*12 + int(date.Month()) - int(start.Month())
+	case "YEARLY":
+		return date.Year() - start.Year()
+	}
+	return 0
+}
+
+// candidates lists the sorted dates of the period offset periods after the one containing start
+func (r *Rule) candidates(start time.Time, offset int) []time.Time {
+	var dates []time.Time
+
+	switch r.Freq {
+	case "DAILY":
+		date := start.AddDate(0, 0, offset)
+		if r.matchesWeekday(date) && r.matchesMonthDay(date) && r.matchesMonth(date) {
+			dates = append(dates, date)
+		}
+
+	case "WEEKLY":
+		monday := start.AddDate(0, 0, -((int(start.Weekday())+6)%7)+offset*7)
+		if len(r.ByDay) == 0 {
+			dates = append(dates, monday.AddDate(0, 0, (int(start.Weekday())+6)%7))
+		}
+		for _, day := range r.ByDay {
+			_, weekday, _ := parseByDay(day)
+			dates = append(dates, monday.AddDate(0, 0, (int(weekday)+6)%7))
+		}
+		// A week can straddle two months, so BYMONTH is checked per date
+		kept := dates[:0]
+		for _, date := range dates {
+			if r.matchesMonth(date) {
+				kept = append(kept, date)
+			}
+		}
+		dates = kept
+
+	case "MONTHLY":
+		month := time.Date(start.Year(), start.Month()+time.Month(offset), 1, 0, 0, 0, 0, time.UTC)
+		if r.matchesMonth(month) {
+			dates = r.monthDates(month, start.Day())
+		}
+
+	case "YEARLY":
+		year := start.Year() + offset
+		months := r.ByMonth
+		if len(months) == 0 && len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+			months = []int{int(start.Month())}
+		} else if len(months) == 0 {
+			// BYMONTHDAY or BYDAY alone expand over every month of the year
+			months = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+		}
+		for _, m := range months {
+			month := time.Date(year, time.Month(m), 1, 0, 0, 0, 0, time.UTC)
+			dates = append(dates, r.monthDates(month, start.Day())...)
+		}
+	}
+
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	return dedupe(dates)
+}
+
+// matchesMonth reports whether BYMONTH (if any) allows a date; used to limit DAILY, WEEKLY and MONTHLY rules
+func (r *Rule) matchesMonth(date time.Time) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, month := range r.ByMonth {
+		if time.Month(month) == date.Month() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesMonthDay reports whether BYMONTHDAY (if any) allows a date; used to limit DAILY rules
+func (r *Rule) matchesMonthDay(date time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	daysInMonth := time.Date(date.Year(), date.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, day := range r.ByMonthDay {
+		if day == date.Day() || day < 0 && daysInMonth+day+1 == date.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekday reports whether BYDAY (if any) allows a date; used to limit DAILY rules
+func (r *Rule) matchesWeekday(date time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, value := range r.ByDay {
+		if _, weekday, _ := parseByDay(value); weekday == date.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// monthDates expands BYMONTHDAY and BYDAY within one month, defaulting to the start's day of month. When
+// both are given only the days matching both are kept, as RFC 5545 requires. Days that do not exist in the
+// month (e.g. the 31st of April) are skipped.
+func (r *Rule) monthDates(month time.Time, defaultDay int) []time.Time {
+	daysInMonth := month.AddDate(0, 1, -1).Day()
+
+	var monthDays []time.Time
+	for _, day := range r.ByMonthDay {
+		if day < 0 {
+			day = daysInMonth + day + 1
+		}
+		if day >= 1 && day <= daysInMonth {
+			monthDays = append(monthDays, month.AddDate(0, 0, day-1))
+		}
+	}
+
+	var weekDays []time.Time
+	for _, value := range r.ByDay {
+		ordinal, weekday, _ := parseByDay(value)
+		first := month.AddDate(0, 0, (int(weekday)-int(month.Weekday())+7)%7)
+		var matches []time.Time
+		for d := first; d.Month() == month.Month(); d = d.AddDate(0, 0, 7) {
+			matches = append(matches, d)
+		}
+		switch {
+		case ordinal == 0:
+			weekDays = append(weekDays, matches...)
+		case ordinal > 0 && ordinal <= len(matches):
+			weekDays = append(weekDays, matches[ordinal-1])
+		case ordinal < 0 && -ordinal <= len(matches):
+			weekDays = append(weekDays, matches[len(matches)+ordinal])
+		}
+	}
+
+	switch {
+	case len(r.ByMonthDay) > 0 && len(r.ByDay) > 0:
+		var dates []time.Time
+		for _, date := range monthDays {
+			for _, match := range weekDays {
+				if date.Equal(match) {
+					dates = append(dates, date)
+					break
+				}
+			}
+		}
+		return dates
+	case len(r.ByMonthDay) > 0:
+		return monthDays
+	case len(r.ByDay) > 0:
+		return weekDays
+	case defaultDay <= daysInMonth:
+		return []time.Time{month.AddDate(0, 0, defaultDay-1)}
+	}
+	return nil
+}
+
+// parseByDay splits a BYDAY value such as "MO", "2TU" or "-1FR" into ordinal and weekday
+func parseByDay(value string) (int, time.Weekday, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if len(value) < 2 {
+		return 0, 0, ErrInvalidDay
+	}
+
+	weekday, ok := weekdays[value[len(value)-2:]]
+	if !ok {
+		return 0, 0, ErrInvalidDay
+	}
+
+	ordinal := 0
+	if prefix := value[:len(value)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return 0, 0, ErrInvalidDay
+		}
+		ordinal = n
+	}
+	return ordinal, weekday, nil
+}
+
+func parseInts(value string) ([]int, error) {
+	var numbers []int
+	for _, part := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
+
+func joinInts(numbers []int) string {
+	parts := make([]string, len(numbers))
+	for i, n := range numbers {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ",")
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func dedupe(dates []time.Time) []time.Time {
+	out := dates[:0]
+	for i, date := range dates {
+		if i == 0 || !date.Equal(dates[i-1]) {
+			out = append(out, date)
+		}
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// MigrateResult is what Migrate did with one object
+type MigrateResult struct {
+	Missing bool // the source has no object under the key
+	Skipped bool // the target already held an identical copy
+	Copied  bool // copied and verified, or in a dry run, would be copied
+	Deleted bool // removed from the source after the target copy was verified
+}
+
+// Migrate copies the object under key from src to dst unless dst already holds an identical copy. The copy
+// is read back and its SHA-256 compared with the source; on a mismatch the target copy is removed again.
+// With deleteSource a verified object is then removed from src. With dryRun nothing is written or deleted.
+func Migrate(ctx context.Context, src, dst Storage, key string, deleteSource, dryRun bool) (*MigrateResult, error) {
+	result := &MigrateResult{}
+
+	srcInfo, err := src.Stat(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		result.Missing = true
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	srcSum, err := Checksum(ctx, src, key)
+	if err != nil {
+		return nil, err
+	}
+
+	dstInfo, err := dst.Stat(ctx, key)
+	switch {
+	case err == nil && dstInfo.Size == srcInfo.Size:
+		dstSum, err := Checksum(ctx, dst, key)
+		if err != nil {
+			return nil, err
+		}
+		result.Skipped = dstSum == srcSum
+	case err != nil && !errors.Is(err, ErrNotFound):
+		return nil, err
+	}
+
+	if dryRun {
+		result.Copied = !result.Skipped
+		return result, nil
+	}
+
+	if !result.Skipped {
+		copiedSum, err := Copy(ctx, src, dst, key)
+		if err != nil {
+			return nil, err
+		}
+		dstSum, err := Checksum(ctx, dst, key)
+		if err != nil {
+			return nil, err
+		}
+		if copiedSum != srcSum || dstSum != srcSum {
+			dst.Delete(ctx, key)
+			return nil, fmt.Errorf("checksum mismatch after copy")
+		}
+		result.Copied = true
+	}
+
+	if deleteSource {
+		if err := src.Delete(ctx, key); err != nil {
+			return result, fmt.Errorf("copied, but failed to delete the source: %w", err)
+		}
+		result.Deleted = true
+	}
+	return result, nil
+}
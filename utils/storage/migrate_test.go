@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// corruptingStore writes something other than what it was given, as a faulty backend would
+type corruptingStore struct {
+	*Local
+}
+
+func (c corruptingStore) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	return c.Local.Put(ctx, key, strings.NewReader(strings.ToUpper(string(content))))
+}
+
+func putString(t *testing.T, store Storage, key, content string) {
+	t.Helper()
+	if _, err := store.Put(context.Background(), key, strings.NewReader(content)); err != nil {
+		t.Fatalf("Put %s: %v", key, err)
+	}
+}
+
+func readString(t *testing.T, store Storage, key string) string {
+	t.Helper()
+	body, err := store.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get %s: %v", key, err)
+	}
+	defer body.Close()
+	content, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("Get %s: %v", key, err)
+	}
+	return string(content)
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	const key = "submissions/2026/essay.pdf"
+
+	t.Run("copies and deletes the source", func(t *testing.T) {
+		src, dst := &Local{Root: t.TempDir()}, &Local{Root: t.TempDir()}
+		putString(t, src, key, "essay")
+
+		result, err := Migrate(ctx, src, dst, key, true, false)
+		if err != nil {
+			t.Fatalf("Migrate: %v", err)
+		}
+		if !result.Copied || !result.Deleted || result.Skipped || result.Missing {
+			t.Errorf("Migrate returned %+v, want copied and deleted", result)
+		}
+		if got := readString(t, dst, key); got != "essay" {
+			t.Errorf("target holds %q, want %q", got, "essay")
+		}
+		if _, err := src.Stat(ctx, key); !errors.Is(err, ErrNotFound) {
+			t.Errorf("source Stat returned %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("skips an identical copy", func(t *testing.T) {
+		src, dst := &Local{Root: t.TempDir()}, &Local{Root: t.TempDir()}
+		putString(t, src, key, "essay")
+		putString(t, dst, key, "essay")
+
+		result, err := Migrate(ctx, src, dst, key, false, false)
+		if err != nil {
+			t.Fatalf("Migrate: %v", err)
+		}
+		if !result.Skipped || result.Copied || result.Deleted {
+			t.Errorf("Migrate returned %+v, want skipped", result)
+		}
+		if got := readString(t, src, key); got != "essay" {
+			t.Errorf("source holds %q, want it kept", got)
+		}
+	})
+
+	t.Run("skipped copy still deletes the source", func(t *testing.T) {
+		src, dst := &Local{Root: t.TempDir()}, &Local{Root: t.TempDir()}
+		putString(t, src, key, "essay")
+		putString(t, dst, key, "essay")
+
+		result, err := Migrate(ctx, src, dst, key, true, false)
+		if err != nil {
+			t.Fatalf("Migrate: %v", err)
+		}
+		if !result.Skipped || !result.Deleted {
+			t.Errorf("Migrate returned %+v, want skipped and deleted", result)
+		}
+		if _, err := src.Stat(ctx, key); !errors.Is(err, ErrNotFound) {
+			t.Errorf("source Stat returned %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("overwrites a different copy of the same size", func(t *testing.T) {
+		src, dst := &Local{Root: t.TempDir()}, &Local{Root: t.TempDir()}
+		putString(t, src, key, "essay")
+		putString(t, dst, key, "draft")
+
+		result, err := Migrate(ctx, src, dst, key, false, false)
+		if err != nil {
+			t.Fatalf("Migrate: %v", err)
+		}
+		if !result.Copied || result.Skipped {
+			t.Errorf("Migrate returned %+v, want copied", result)
+		}
+		if got := readString(t, dst, key); got != "essay" {
+			t.Errorf("target holds %q, want %q", got, "essay")
+		}
+	})
+
+	t.Run("checksum mismatch keeps the source", func(t *testing.T) {
+		src, dst := &Local{Root: t.TempDir()}, corruptingStore{&Local{Root: t.TempDir()}}
+		putString(t, src, key, "essay")
+
+		result, err := Migrate(ctx, src, dst, key, true, false)
+		if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+			t.Fatalf("Migrate returned %+v, %v, want a checksum mismatch", result, err)
+		}
+		if _, err := dst.Stat(ctx, key); !errors.Is(err, ErrNotFound) {
+			t.Errorf("target Stat returned %v, want the bad copy removed", err)
+		}
+		if got := readString(t, src, key); got != "essay" {
+			t.Errorf("source holds %q, want it kept", got)
+		}
+	})
+
+	t.Run("reports a missing source", func(t *testing.T) {
+		src, dst := &Local{Root: t.TempDir()}, &Local{Root: t.TempDir()}
+
+		result, err := Migrate(ctx, src, dst, key, true, false)
+		if err != nil {
+			t.Fatalf("Migrate: %v", err)
+		}
+		if !result.Missing || result.Deleted {
+			t.Errorf("Migrate returned %+v, want missing", result)
+		}
+	})
+
+	t.Run("dry run writes nothing", func(t *testing.T) {
+		src, dst := &Local{Root: t.TempDir()}, &Local{Root: t.TempDir()}
+		putString(t, src, key, "essay")
+
+		result, err := Migrate(ctx, src, dst, key, true, true)
+		if err != nil {
+			t.Fatalf("Migrate: %v", err)
+		}
+		if !result.Copied || result.Deleted {
+			t.Errorf("Migrate returned %+v, want a planned copy", result)
+		}
+		if _, err := dst.Stat(ctx, key); !errors.Is(err, ErrNotFound) {
+			t.Errorf("target Stat returned %v, want nothing written", err)
+		}
+		if got := readString(t, src, key); got != "essay" {
+			t.Errorf("source holds %q, want it kept", got)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+var (
+	// ErrNotFound is returned when no object is stored under a key
+	ErrNotFound = errors.New("object not found")
+	// ErrInvalidKey is returned for object keys that would escape the storage root
+	ErrInvalidKey = errors.New("invalid storage key")
+	// ErrPresignNotSupported is returned by backends that cannot hand out direct download links
+	ErrPresignNotSupported = errors.New("presigned links are not supported by this storage backend")
+)
+
+// Storage keeps uploaded files under slash-separated keys
+type Storage interface {
+	// Put stores r under key and returns the number of bytes written
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Get opens the object stored under key; the caller closes it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object stored under key; a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// Stat describes the object stored under key
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// Presign returns a time-limited link that downloads the object as filename
+	Presign(ctx context.Context, key string, expires time.Duration, filename string) (string, error)
+}
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key     string    `json:"key"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// Drivers lists the backend names New accepts
+var Drivers = []string{"local", "s3"}
+
+// Default returns the backend chosen by STORAGE_DRIVER (default local)
+func Default() (Storage, error) {
+	godotenv.Load()
+
+	driver := os.Getenv("STORAGE_DRIVER")
+	if driver == "" {
+		driver = "local"
+	}
+	return New(driver)
+}
+
+// New builds the named backend from its environment settings: UPLOAD_DIR for local, S3_* for s3
+func New(driver string) (Storage, error) {
+	godotenv.Load()
+
+	switch strings.ToLower(strings.TrimSpace(driver)) {
+	case "local":
+		root := os.Getenv("UPLOAD_DIR")
+		if root == "" {
+			root = "uploads"
+		}
+		return &Local{Root: root}, nil
+	case "s3":
+		return NewS3FromEnv()
+	}
+	return nil, fmt.Errorf("unknown storage driver %q, expected one of %s", driver, strings.Join(Drivers, ", "))
+}
+
+// Checksum returns the hex SHA-256 of the object stored under key
+func Checksum(ctx context.Context, store Storage, key string) (string, error) {
+	content, err := store.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer content.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Copy streams the object under key from src to dst and returns the hex SHA-256 of what was read
+func Copy(ctx context.Context, src, dst Storage, key string) (string, error) {
+	content, err := src.Get(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	defer content.Close()
+
+	hash := sha256.New()
+	if _, err := dst.Put(ctx, key, io.TeeReader(content, hash)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}